@@ -0,0 +1,138 @@
+package analyser
+
+import (
+	"cryptoBot/pkg/api"
+	"cryptoBot/pkg/api/bybit/mock"
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/optimizer"
+	"cryptoBot/pkg/service/report"
+	"cryptoBot/pkg/service/trading"
+	"fmt"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// NewInMemoryBacktester runs every backtest with own in-memory repositories, clock and exchange mock,
+// so backtests of the storage can run in parallel. Klines are shared and loaded only once
+func NewInMemoryBacktester(storage *repository.MemoryStorage, initialCapital float64) optimizer.Backtester {
+	fillConfig := mock.NewFillConfig()
+	return func(config *trading.StrategyConfig, from time.Time, to time.Time) (*report.BacktestReport, error) {
+		_, backtestReport, err := backtest(storage.NewRepositories(), config, from, to, initialCapital, fillConfig)
+		return backtestReport, err
+	}
+}
+
+// NewRecordedBacktester records every backtest in backtest_run, repositories of the run save transactions with its id
+func NewRecordedBacktester(runService *report.BacktestRunService, repositories func(runId int64) *repository.Repository, initialCapital float64) *RecordedBacktester {
+	return &RecordedBacktester{
+		runService:     runService,
+		repositories:   repositories,
+		initialCapital: initialCapital,
+		FillConfig:     mock.NewFillConfig(),
+		Benchmark:      report.NewBenchmarkConfig(),
+	}
+}
+
+type RecordedBacktester struct {
+	runService     *report.BacktestRunService
+	repositories   func(runId int64) *repository.Repository
+	initialCapital float64
+
+	/* Fills of the exchange mock, analyser.fill of config.yml by default */
+	FillConfig *mock.FillConfig
+
+	/* Buy-and-hold benchmarks added to the report, analyser.benchmark of config.yml by default, nil disables them */
+	Benchmark *report.BenchmarkConfig
+
+	/* Optional, called after the run with the strategy instance, e.g. to print strategy specific statistic */
+	OnFinished func(run *domains.BacktestRun, tradingService trading.TradingService)
+}
+
+// Backtest saves the run as failed when the strategy can't be built or its report can't be built
+func (b *RecordedBacktester) Backtest(config *trading.StrategyConfig, from time.Time, to time.Time) (*domains.BacktestRun, *report.BacktestReport, error) {
+	run, err := b.runService.Start(config.Key(), config.Strategy, trading.ResolveTradingStrategy(config), config.Coins, config.GetInterval(60),
+		config.Params, from, to, b.initialCapital)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error during saving run of %s: %s", config.Key(), err.Error())
+	}
+
+	start := time.Now()
+	repos := b.repositories(run.Id)
+	tradingService, backtestReport, err := backtest(repos, config, from, to, b.initialCapital, b.FillConfig)
+	if err != nil {
+		if failErr := b.runService.Fail(run, err); failErr != nil {
+			zap.S().Errorf("Error during saving failed run %d: %s", run.Id, failErr.Error())
+		}
+		return run, nil, err
+	}
+	if b.Benchmark != nil {
+		report.NewBenchmarkService(repos.Kline, repos.Coin, b.Benchmark).AddBenchmarks(backtestReport, config.Coins, strconv.Itoa(config.GetInterval(60)))
+	}
+	backtestReport.Name = fmt.Sprintf("%s #%d", backtestReport.Name, run.Id)
+	if err := b.runService.Finish(run, backtestReport); err != nil {
+		return run, nil, err
+	}
+	zap.S().Infof("EXECUTED run %d of %s in %d ms", run.Id, config.Key(), time.Since(start).Milliseconds())
+	if b.OnFinished != nil {
+		b.OnFinished(run, tradingService)
+	}
+	return run, backtestReport, nil
+}
+
+// backtest runs the strategy on the repositories with own clock and exchange mock and builds its report
+func backtest(repos *repository.Repository, config *trading.StrategyConfig, from time.Time, to time.Time, initialCapital float64,
+	fillConfig *mock.FillConfig) (trading.TradingService, *report.BacktestReport, error) {
+	if err := fillConfig.Validate(); err != nil {
+		return nil, nil, err
+	}
+	clock := date.NewClockMock(from)
+	exchangeApi := mock.NewBybitApiMockWithFillModel(mock.NewFillModel(fillConfig, repos.Kline, clock))
+
+	registry := trading.NewStrategyRegistry(&trading.StrategyDependencies{
+		Repos: repos,
+		Clock: clock,
+		ExchangeApiProvider: func(account trading.StrategyAccount) api.ExchangeApi {
+			return exchangeApi
+		},
+	})
+
+	tradingService, err := registry.Build(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the clock is already at the start of the period, so klines are not fetched from the exchange
+	if err := tradingService.Initialize(); err != nil {
+		return nil, nil, err
+	}
+	coins := make([]*domains.Coin, 0, len(config.Coins))
+	for _, symbol := range config.Coins {
+		coin, err := repos.Coin.FindBySymbol(symbol)
+		if err != nil || coin == nil {
+			return nil, nil, fmt.Errorf("Coin [%s] not found", symbol)
+		}
+		coins = append(coins, coin)
+	}
+	if err := NewAnalyserRunner(tradingService, clock, repos.Kline).AnalysePeriod(coins, strconv.Itoa(config.GetInterval(60)), from, to); err != nil {
+		return nil, nil, err
+	}
+
+	backtestReport, err := report.NewBacktestReportService(repos.Transaction, repos.Coin).BuildReport(config.Key(), trading.ResolveTradingStrategy(config), from, to, initialCapital)
+	return tradingService, backtestReport, err
+}
+
+// ParsePeriod parses dates of command arguments, invalid date stops the command
+func ParsePeriod(from string, to string) (time.Time, time.Time) {
+	fromTime, err := time.Parse(constants.DATE_FORMAT, from)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid from [%s]: %s", from, err.Error()))
+	}
+	toTime, err := time.Parse(constants.DATE_FORMAT, to)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid to [%s]: %s", to, err.Error()))
+	}
+	return fromTime, toTime
+}
@@ -0,0 +1,81 @@
+package analyser
+
+import (
+	"cryptoBot/cmd/bootstrap"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/log"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/trading"
+	"go.uber.org/zap"
+	"os"
+	"time"
+)
+
+// ConfigAnalyser backtests strategies of config.yml: <analyser> [from] [to] [name], analyser of a strategy sets
+// only the configs loader and optional hooks
+type ConfigAnalyser struct {
+	/* Reads configs of the strategy from config.yml, e.g. trading.NewGridStrategyConfigs */
+	Configs func() []*trading.StrategyConfig
+
+	/* Optional preparation of data of the config before its backtest, error skips the config */
+	Prepare func(repos *repository.Repository, config *trading.StrategyConfig, from time.Time, to time.Time) error
+
+	/* Optional hook called after every run with the trading service of the run */
+	OnFinished func(run *domains.BacktestRun, tradingService trading.TradingService)
+}
+
+// Run backtests configs selected by name of the command arguments, all configs without name
+func (a *ConfigAnalyser) Run() {
+	bootstrap.Run()
+	log.InitLoggerAnalyser()
+
+	var closableClosure []func()
+
+	defer func() {
+		for i := range closableClosure {
+			closableClosure[i]()
+		}
+	}()
+	postgresDb := bootstrap.Database(closableClosure)
+	repos := repository.NewRepositories(postgresDb)
+	backtester := NewAnalyserBacktester(postgresDb)
+	backtester.OnFinished = a.OnFinished
+
+	from := "2023-01-01"
+	to := "2023-07-01"
+	name := ""
+	if len(os.Args) > 2 {
+		from = os.Args[1]
+		to = os.Args[2]
+	}
+	if len(os.Args) > 3 {
+		name = os.Args[3]
+	}
+	fromTime, toTime := ParsePeriod(from, to)
+
+	for _, config := range a.Configs() {
+		if name != "" && config.Name != name {
+			continue
+		}
+
+		if a.Prepare != nil {
+			if err := a.Prepare(repos, config, fromTime, toTime); err != nil {
+				zap.S().Errorf("Error during preparing data of %s: %s", config.Key(), err.Error())
+				continue
+			}
+		}
+
+		run, backtestReport, err := backtester.Backtest(config, fromTime, toTime)
+		if err != nil {
+			zap.S().Errorf("Error during backtest of %s: %s", config.Key(), err.Error())
+			continue
+		}
+		PrintReport(run, backtestReport)
+	}
+
+	if err := postgresDb.Close(); err != nil {
+		zap.S().Errorf("error occured on db connection close: %s", err.Error())
+	}
+
+	os.Exit(0)
+}
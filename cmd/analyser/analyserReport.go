@@ -0,0 +1,57 @@
+package analyser
+
+import (
+	"cryptoBot/cmd/bootstrap"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/report"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// NewAnalyserBacktester records runs in backtest_run of postgres, transactions of a run are kept in memory
+// when analyser.inMemory is set and saved into postgres with the run id otherwise
+func NewAnalyserBacktester(postgresDb *sqlx.DB) *RecordedBacktester {
+	return NewAnalyserBacktesterWithCapital(postgresDb, InitialCapital())
+}
+
+// NewAnalyserBacktesterWithCapital is NewAnalyserBacktester starting every run with the initial capital in USD
+func NewAnalyserBacktesterWithCapital(postgresDb *sqlx.DB, initialCapital float64) *RecordedBacktester {
+	repositories := func(runId int64) *repository.Repository {
+		return repository.NewRunRepositories(postgresDb, runId)
+	}
+	if viper.GetBool("analyser.inMemory") {
+		repositories = bootstrap.AnalyserMemoryStorage(postgresDb).NewRunRepositories
+	}
+
+	runService := report.NewBacktestRunService(repository.NewRepositories(postgresDb).BacktestRun, date.GetClock())
+	return NewRecordedBacktester(runService, repositories, initialCapital)
+}
+
+// PrintReport prints report of the run and saves json and html copies into analyser.reportDir
+func PrintReport(run *domains.BacktestRun, backtestReport *report.BacktestReport) {
+	fmt.Printf("Run %d, revision %s\n", run.Id, run.GitRevision)
+	fmt.Println(backtestReport.String())
+
+	reportDir := viper.GetString("analyser.reportDir")
+	if reportDir == "" {
+		return
+	}
+	jsonPath, htmlPath, err := backtestReport.Save(reportDir)
+	if err != nil {
+		zap.S().Errorf("Error during saving report of %s: %s", backtestReport.Name, err.Error())
+		return
+	}
+	zap.S().Infof("Report of %s is saved to %s and %s", backtestReport.Name, jsonPath, htmlPath)
+}
+
+// InitialCapital reads analyser.initialCapital, 1000 USD by default
+func InitialCapital() float64 {
+	if viper.IsSet("analyser.initialCapital") {
+		return viper.GetFloat64("analyser.initialCapital")
+	}
+	return 1000
+}
@@ -1,33 +1,75 @@
 package analyser
 
 import (
-	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
 	"cryptoBot/pkg/service/date"
 	"cryptoBot/pkg/service/trading"
+	"cryptoBot/pkg/util"
+	"fmt"
+	"sort"
 	"time"
 )
 
-var analyserRunnerImpl *Runner
+// EXECUTION_DELAY is time after the kline close when the strategy is executed, cron of the trader starts a bit later as well
+const EXECUTION_DELAY = time.Second * 2
 
-func NewAnalyserRunner(tradingService trading.TradingService) *Runner {
-	analyserRunnerImpl = &Runner{
+// NewAnalyserRunner moves the clock of the run, the same clock must be passed to all services of the strategy
+func NewAnalyserRunner(tradingService trading.TradingService, clock date.Clock, klineRepo repository.Kline) *Runner {
+	return &Runner{
 		tradingService: tradingService,
+		clock:          clock,
+		klineRepo:      klineRepo,
 	}
-	return analyserRunnerImpl
 }
 
+// Runner is event loop of one backtest run, the clock is moved by kline close events of the traded coins
 type Runner struct {
 	tradingService trading.TradingService
+	clock          date.Clock
+	klineRepo      repository.Kline
 }
 
-func (runner *Runner) AnalyseCoin(from string, to string, interval int) {
-	timeMax, _ := time.Parse(constants.DATE_FORMAT, to)
-	timeIterator, _ := time.Parse(constants.DATE_FORMAT, from)
-	timeIterator = timeIterator.Add(time.Second * 2)
-
-	for ; timeIterator.Before(timeMax); timeIterator = timeIterator.Add(time.Minute * time.Duration(interval)) {
-		date.SetMockTime(timeIterator)
+// AnalysePeriod executes the strategy after every close of a kline of the coins in the period,
+// gaps in klines are skipped, so the strategy never runs at a moment without a new kline
+func (runner *Runner) AnalysePeriod(coins []*domains.Coin, interval string, from time.Time, to time.Time) error {
+	events, err := runner.findCloseEvents(coins, interval, from, to)
+	if err != nil {
+		return err
+	}
 
+	for _, event := range events {
+		runner.clock.SetTime(event)
 		runner.tradingService.Execute()
 	}
+	return nil
+}
+
+// findCloseEvents returns sorted moments of execution after kline closes, closes of several coins at the same time are one event
+func (runner *Runner) findCloseEvents(coins []*domains.Coin, interval string, from time.Time, to time.Time) ([]time.Time, error) {
+	moments := make(map[int64]time.Time)
+	for _, coin := range coins {
+		// kline closed just before the start is executed at the start
+		klines, err := runner.klineRepo.FindAllByCoinIdAndIntervalAndCloseTimeInRange(coin.Id, interval, from.Add(-time.Minute), to)
+		if err != nil {
+			return nil, fmt.Errorf("Error during loading klines of %s: %s", coin.Symbol, err.Error())
+		}
+		for _, kline := range klines {
+			// close time is the last second or millisecond of the kline, the event is at open of the next one
+			moment := util.RoundToMinutes(kline.CloseTime.Add(time.Second)).Add(EXECUTION_DELAY)
+			if moment.Before(from) || !moment.Before(to) {
+				continue
+			}
+			moments[moment.Unix()] = moment
+		}
+	}
+
+	events := make([]time.Time, 0, len(moments))
+	for _, moment := range moments {
+		events = append(events, moment)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Before(events[j])
+	})
+	return events, nil
 }
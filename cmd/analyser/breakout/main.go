@@ -0,0 +1,11 @@
+package main
+
+import (
+	"cryptoBot/cmd/analyser"
+	"cryptoBot/pkg/service/trading"
+)
+
+// Backtests donchian breakout strategies from config.yml: breakout [from] [to] [name]
+func main() {
+	(&analyser.ConfigAnalyser{Configs: trading.NewBreakoutStrategyConfigs}).Run()
+}
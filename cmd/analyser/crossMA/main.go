@@ -4,7 +4,6 @@ import (
 	"cryptoBot/cmd/bootstrap"
 	"cryptoBot/pkg/api/bybit/mock"
 	"cryptoBot/pkg/log"
-	"cryptoBot/pkg/repository"
 	"cryptoBot/pkg/service/analyser"
 	"cryptoBot/pkg/service/date"
 	"cryptoBot/pkg/service/exchange"
@@ -14,6 +13,7 @@ import (
 	"cryptoBot/pkg/service/trading"
 	"go.uber.org/zap"
 	"os"
+	"time"
 )
 
 func main() {
@@ -30,7 +30,7 @@ func main() {
 
 	postgresDb := bootstrap.Database(closableClosure)
 
-	repos := repository.NewRepositories(postgresDb)
+	repos := bootstrap.AnalyserRepositories(postgresDb)
 
 	//exchangeApi := binance.NewBinanceApi()
 	//mockExchangeApi := mock.NewBinanceApiMock()
@@ -41,17 +41,19 @@ func main() {
 	//tradingService := trading.NewHolderStrategyTradingService(repos.Transaction, repos.PriceChange, mockExchangeApi)
 	//analyserService := analyser.NewAnalyserService(repos.Transaction, repos.PriceChange, exchangeApi, tradingService)
 
-	maService := indicator.NewMovingAverageService(date.GetClock(), repos.Kline)
-	seriesConvertorService := techanLib.NewTechanConvertorService(date.GetClock(), repos.Kline)
-	stdDevService := indicator.NewStandardDeviationService(date.GetClock(), repos.Kline, seriesConvertorService)
-	exchangeDataService := exchange.NewExchangeDataService(repos.Transaction, repos.Coin, mockExchangeApi, date.GetClock(), repos.Kline)
+	// own clock of the run, the analyser service moves it
+	clockMock := date.NewClockMock(time.Time{})
+	maService := indicator.NewMovingAverageService(clockMock, repos.Kline)
+	seriesConvertorService := techanLib.NewTechanConvertorService(clockMock, repos.Kline)
+	stdDevService := indicator.NewStandardDeviationService(clockMock, repos.Kline, seriesConvertorService)
+	exchangeDataService := exchange.NewExchangeDataService(repos.Transaction, repos.Coin, mockExchangeApi, clockMock, repos.Kline)
 	priceChangeTrackingService := orders.NewPriceChangeTrackingService(repos.PriceChange)
-	fetcherService := exchange.NewKlinesFetcherService(mockExchangeApi, repos.Kline, date.GetClock())
+	fetcherService := exchange.NewKlinesFetcherService(mockExchangeApi, repos.Kline, clockMock)
 
-	maTradingService := trading.NewMAStrategyTradingService(repos.Transaction, repos.PriceChange, mockExchangeApi, date.GetClock(), exchangeDataService, repos.Kline, priceChangeTrackingService, maService, stdDevService, fetcherService)
+	maTradingService := trading.NewMAStrategyTradingService(repos.Transaction, repos.PriceChange, mockExchangeApi, clockMock, exchangeDataService, repos.Kline, priceChangeTrackingService, maService, stdDevService, fetcherService)
 	analyserService := analyser.NewMovingAverageStrategyAnalyserService(repos.Transaction, repos.PriceChange, mockExchangeApi, maTradingService, repos.Kline)
 
-	//maResistanceTradingService := trading.NewMovingAverageResistanceStrategyTradingService(repos.Transaction, repos.PriceChange, mockExchangeApi, date.GetClock(), exchangeDataService, repos.Kline, priceChangeTrackingService, maService)
+	//maResistanceTradingService := trading.NewMovingAverageResistanceStrategyTradingService(repos.Transaction, repos.PriceChange, mockExchangeApi, clockMock, exchangeDataService, repos.Kline, priceChangeTrackingService, maService)
 	//analyserService := analyser.NewMovingAverageResistanceStratagyAnalyserService(repos.Transaction, repos.PriceChange, mockExchangeApi, maResistanceTradingService, repos.Kline)
 
 	coin, _ := repos.Coin.FindBySymbol("SOLUSDT")
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"cryptoBot/cmd/analyser"
+	"cryptoBot/pkg/api/bybit/mock"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/exchange"
+	"cryptoBot/pkg/service/trading"
+	"fmt"
+	"go.uber.org/zap"
+	"os"
+	"time"
+)
+
+// Backtests funding basis strategies from config.yml against funding_rate table: fundingBasis [from] [to] [name] [fetch],
+// fetch saves funding history of the period from exchange before the run
+func main() {
+	fundingBasisAnalyser := &analyser.ConfigAnalyser{
+		Configs: trading.NewFundingBasisStrategyConfigs,
+		OnFinished: func(run *domains.BacktestRun, tradingService trading.TradingService) {
+			report, err := tradingService.(*trading.FundingBasisStrategyTradingService).BuildReport()
+			if err != nil {
+				zap.S().Errorf("Error during building report of %s: %s", run.Name, err.Error())
+				return
+			}
+			zap.S().Infof("REPORT %s: %s", run.Name, report.String())
+		},
+	}
+
+	// rates are fetched into postgres before in-memory repositories copy them, missing rates don't stop the backtest
+	if len(os.Args) > 4 && os.Args[4] == "fetch" {
+		fundingBasisAnalyser.Prepare = func(repos *repository.Repository, config *trading.StrategyConfig, from time.Time, to time.Time) error {
+			fundingRateFetcherService := exchange.NewFundingRateFetcherService(mock.NewBybitApiMock(), repos.FundingRate, date.GetClock())
+			if err := fetchFundingRates(fundingRateFetcherService, repos, config.Coins[0], from, to); err != nil {
+				zap.S().Errorf("Error during fetching funding rates of %s: %s", config.Key(), err.Error())
+			}
+			return nil
+		}
+	}
+
+	fundingBasisAnalyser.Run()
+}
+
+func fetchFundingRates(fetcherService *exchange.FundingRateFetcherService, repos *repository.Repository, symbol string, from time.Time, to time.Time) error {
+	coin, err := repos.Coin.FindBySymbol(symbol)
+	if err != nil {
+		return err
+	}
+	if coin == nil {
+		return fmt.Errorf("Coin [%s] not found", symbol)
+	}
+
+	return fetcherService.FetchFundingRatesForPeriod(coin, from, to)
+}
@@ -0,0 +1,219 @@
+package analyser
+
+import (
+	"bufio"
+	"cryptoBot/pkg/api/bybit/mock"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/report"
+	"cryptoBot/pkg/service/trading"
+	"flag"
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// go test ./cmd/analyser -run TestGoldenRuns -update rewrites golden files by the current trades,
+// review their diff before commit, every changed trade is a changed trading decision
+var updateGolden = flag.Bool("update", false, "regenerate golden files of strategy runs")
+
+const (
+	GOLDEN_DIR            = "testdata/golden"
+	GOLDEN_KLINES         = "klines.csv"
+	GOLDEN_CAPITAL        = 1000
+	GOLDEN_FUNDING_PERIOD = 8 * time.Hour
+)
+
+var (
+	goldenFrom = time.Date(2023, 1, 11, 0, 0, 0, 0, time.UTC)
+	goldenTo   = time.Date(2023, 2, 26, 0, 0, 0, 0, time.UTC)
+)
+
+// goldenRuns are strategies with params of the fixture, 10 days of klines before goldenFrom are history of indicators
+var goldenRuns = []*trading.StrategyConfig{
+	{
+		Strategy: trading.BREAKOUT_STRATEGY,
+		Coins:    []string{"BTCUSDT"},
+		Interval: 60,
+		Params: map[string]interface{}{
+			"entryLength": 20, "exitLength": 10, "atrLength": 20, "stopAtrMultiplier": 2, "pyramidAtrStep": 0.5,
+			"maxUnits": 4, "direction": "both", "riskPerUnitInCents": 2000, "costOfOrderInCents": 10000, "leverage": 1,
+		},
+	},
+	{
+		Strategy: trading.MEAN_REVERSION_STRATEGY,
+		Coins:    []string{"ETHUSDT"},
+		Interval: 60,
+		Params: map[string]interface{}{
+			"bands": "bollinger", "length": 20, "deviation": 2, "rsiLength": 14, "rsiOversold": 30, "rsiOverbought": 70,
+			"direction": "both", "stopLossPercent": 3, "costOfOrderInCents": 10000, "leverage": 1,
+		},
+	},
+	{
+		Strategy: trading.RULE_BASED_STRATEGY,
+		Coins:    []string{"ETHUSDT"},
+		Interval: 60,
+		Params: map[string]interface{}{
+			"entryLong": "ema(20) > ema(50) && rsi(13) crosses_above 50", "exitLong": "rsi(13) crosses_below 45",
+			"entryShort": "ema(20) < ema(50) && rsi(13) crosses_below 50", "exitShort": "rsi(13) crosses_above 55",
+			"stopLossPercent": 3, "takeProfitPercent": 6, "costOfOrderInCents": 10000, "leverage": 1,
+		},
+	},
+	{
+		Strategy: trading.GRID_STRATEGY,
+		Coins:    []string{"BTCUSDT"},
+		Interval: 60,
+		Params: map[string]interface{}{
+			"tradingType": "futures", "direction": "long", "lowerPrice": 15500, "upperPrice": 19500, "levels": 11,
+			"spacing": "geometric", "quantity": 0.005, "stopOutPercent": 3, "leverage": 1,
+		},
+	},
+	{
+		Strategy: trading.FUNDING_BASIS_STRATEGY,
+		Coins:    []string{"ETHUSDT"},
+		Interval: 60,
+		Params: map[string]interface{}{
+			"entryFundingRate": 0.0003, "exitFundingRate": 0.0002, "costOfOrderInCents": 10000, "leverage": 1,
+		},
+	},
+	{
+		Strategy: trading.PAIR_ARBITRAGE_STRATEGY,
+		Coins:    []string{"BTCUSDT", "ETHUSDT"},
+		Interval: 60,
+		Params: map[string]interface{}{
+			"strategyLength": 20, "entryZScore": 2, "zScoreCloseToZero": 0.2, "leverage": 1, "startCapitalInCents": 10000,
+			"zScoreStopLoss": 4, "halfLifeMultiplier": 3, "maxHoldingKlines": 336, "regimeLength": 500,
+		},
+	},
+	{
+		Strategy: trading.SMA_VOLUME_SCALPER_STRATEGY,
+		Coins:    []string{"BTCUSDT"},
+		Interval: 60,
+		Params:   map[string]interface{}{"costOfOrderInCents": 10000, "leverage": 1},
+	},
+	{
+		Strategy: trading.SESSIONS_SCALPER_STRATEGY,
+		Coins:    []string{"BTCUSDT"},
+		Interval: 60,
+		Params:   map[string]interface{}{"costOfOrderInCents": 10000, "leverage": 1},
+	},
+	{
+		Strategy: trading.TREND_METER_STRATEGY,
+		Coins:    []string{"BTCUSDT"},
+		Interval: 60,
+		Params:   map[string]interface{}{"futures": true},
+	},
+}
+
+// TestGoldenRuns replays every strategy on the kline fixture with in-memory repositories and exact fills of the exchange mock
+// and compares closed trades with the golden file of the strategy
+func TestGoldenRuns(t *testing.T) {
+	viper.AddConfigPath("../../configs")
+	viper.SetConfigName("config")
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatalf("Error during reading configs: %s", err.Error())
+	}
+	// orders are placed by the strategies whatever signals.mode of config.yml is, blackouts are out of the fixture
+	viper.Set("signals.mode", "execute")
+	viper.Set("sessions.blackoutFile", "")
+
+	for _, config := range goldenRuns {
+		config := config
+		t.Run(config.Strategy, func(t *testing.T) {
+			storage, err := repository.NewMemoryStorage(nil, time.Time{}, time.Time{}, filepath.Join(GOLDEN_DIR, GOLDEN_KLINES))
+			if err != nil {
+				t.Fatalf("Error during loading klines: %s", err.Error())
+			}
+			storage.FundingRates = buildGoldenFundingRates(storage, config.Coins)
+
+			_, backtestReport, err := backtest(storage.NewRepositories(), config, goldenFrom, goldenTo, GOLDEN_CAPITAL, &mock.FillConfig{})
+			if err != nil {
+				t.Fatalf("Backtest of %s failed: %s", config.Key(), err.Error())
+			}
+
+			actual := formatGoldenTrades(backtestReport)
+			path := filepath.Join(GOLDEN_DIR, config.Strategy+".golden")
+			if *updateGolden {
+				if err := os.WriteFile(path, []byte(actual), 0644); err != nil {
+					t.Fatalf("Error during writing %s: %s", path, err.Error())
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Error during reading %s, run with -update to create it: %s", path, err.Error())
+			}
+			compareGolden(t, path, string(expected), actual)
+		})
+	}
+}
+
+// buildGoldenFundingRates settles funding every 8 hours, the rate is high in the second and the fifth week of the fixture
+func buildGoldenFundingRates(storage *repository.MemoryStorage, symbols []string) []*domains.FundingRate {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	rates := make([]*domains.FundingRate, 0)
+	for _, symbol := range symbols {
+		coin, _ := storage.Coin.FindBySymbol(symbol)
+		if coin == nil {
+			continue
+		}
+		for fundingTime := start; fundingTime.Before(goldenTo); fundingTime = fundingTime.Add(GOLDEN_FUNDING_PERIOD) {
+			rate := 0.0001
+			if week := int(fundingTime.Sub(start).Hours()) / 24 / 7; week == 1 || week == 4 {
+				rate = 0.0005
+			}
+			rates = append(rates, &domains.FundingRate{CoinId: coin.Id, FundingTime: fundingTime, Rate: rate})
+		}
+	}
+	return rates
+}
+
+// formatGoldenTrades writes one line per closed trade, prices and amounts are rounded, so the file is readable in diffs
+func formatGoldenTrades(backtestReport *report.BacktestReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# trades %d, open positions %d, net pnl %.4f, fees %.4f\n",
+		backtestReport.Trades, backtestReport.OpenPositions, backtestReport.NetPnl, backtestReport.Fees)
+	b.WriteString("# symbol,tradingKey,futuresType,openedAt,closedAt,openPrice,closePrice,cost,fees,netPnl,closeReason\n")
+	for _, trade := range backtestReport.TradeList {
+		fmt.Fprintf(&b, "%s,%s,%s,%s,%s,%.4f,%.4f,%.4f,%.4f,%.4f,%s\n", trade.Symbol, trade.TradingKey, trade.FuturesType,
+			trade.OpenedAt.UTC().Format(time.RFC3339), trade.ClosedAt.UTC().Format(time.RFC3339),
+			trade.OpenPrice, trade.ClosePrice, trade.Cost, trade.Fees, trade.NetPnl, trade.CloseReason)
+	}
+	return b.String()
+}
+
+// compareGolden reports the first different line, the rest of the diff is usually caused by it
+func compareGolden(t *testing.T, path string, expected string, actual string) {
+	if expected == actual {
+		return
+	}
+	expectedLines := readLines(expected)
+	actualLines := readLines(actual)
+	for i := 0; i < len(expectedLines) || i < len(actualLines); i++ {
+		expectedLine, actualLine := "<none>", "<none>"
+		if i < len(expectedLines) {
+			expectedLine = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			actualLine = actualLines[i]
+		}
+		if expectedLine != actualLine {
+			t.Fatalf("Trades differ from %s at line %d:\nexpected: %s\nactual:   %s\nrun with -update if the change is intended",
+				path, i+1, expectedLine, actualLine)
+		}
+	}
+}
+
+func readLines(text string) []string {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
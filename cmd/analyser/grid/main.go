@@ -0,0 +1,19 @@
+package main
+
+import (
+	"cryptoBot/cmd/analyser"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/trading"
+	"time"
+)
+
+// Backtests grid strategies from config.yml: grid [from] [to] [name], levels of previous backtest are removed
+func main() {
+	(&analyser.ConfigAnalyser{
+		Configs: trading.NewGridStrategyConfigs,
+		// levels are saved by grid name, transactions of runs are apart by run id
+		Prepare: func(repos *repository.Repository, config *trading.StrategyConfig, from time.Time, to time.Time) error {
+			return repos.GridLevel.DeleteAllByGridName(config.Key())
+		},
+	}).Run()
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"cryptoBot/cmd/analyser"
+	"cryptoBot/pkg/service/trading"
+)
+
+// Backtests bollinger and keltner mean reversion strategies from config.yml: meanReversion [from] [to] [name]
+func main() {
+	(&analyser.ConfigAnalyser{Configs: trading.NewMeanReversionStrategyConfigs}).Run()
+}
@@ -3,18 +3,17 @@ package main
 import (
 	"cryptoBot/cmd/analyser"
 	"cryptoBot/cmd/bootstrap"
-	"cryptoBot/pkg/api/bybit/mock"
 	"cryptoBot/pkg/constants"
 	"cryptoBot/pkg/log"
 	"cryptoBot/pkg/repository"
-	"cryptoBot/pkg/service/date"
-	"cryptoBot/pkg/service/exchange"
-	"cryptoBot/pkg/service/indicator/techanLib"
-	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/indicator"
+	"cryptoBot/pkg/service/scanner"
 	"cryptoBot/pkg/service/trading"
+	"fmt"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"os"
-	"time"
+	"strconv"
 )
 
 func main() {
@@ -30,20 +29,37 @@ func main() {
 	}()
 	postgresDb := bootstrap.Database(closableClosure)
 	repos := repository.NewRepositories(postgresDb)
-	mockExchangeApi := mock.NewBybitApiMock()
-	clockMock := date.GetClockMock()
-
-	seriesConvertorService := techanLib.NewTechanConvertorService(clockMock, repos.Kline)
-	exchangeDataService := exchange.NewExchangeDataService(repos.Transaction, repos.Coin, mockExchangeApi, clockMock, repos.Kline)
-	priceChangeTrackingService := orders.NewPriceChangeTrackingService(repos.PriceChange)
-
-	orderManagerService := orders.NewOrderManagerService(repos.Transaction, mockExchangeApi, clockMock, exchangeDataService, repos.Kline, constants.PAIR_ARBITRAGE, priceChangeTrackingService,
-		orders.NewProfitLossFinderService(clockMock, repos.Kline),
-		0,
-		0, 0, 0, 0)
+	backtester := analyser.NewAnalyserBacktester(postgresDb)
 
 	klineInterval := 60
 
+	// pairArbitrage [ratio|ols|kalman] [tradingStrategy] [scan|report.csv] [top] - every pair is saved as a backtest run, runs are compared by cmd/runs,
+	// pairs are taken from the last run of cmd/scanner (table or csv report) when the third argument is set
+	hedgeRatioMethod := string(indicator.HEDGE_RATIO_PRICE_RATIO)
+	tradingStrategy := constants.PAIR_ARBITRAGE
+	if len(os.Args) > 1 {
+		hedgeRatioMethod = os.Args[1]
+	}
+	if len(os.Args) > 2 {
+		parsedTradingStrategy, err := strconv.Atoi(os.Args[2])
+		if err != nil {
+			panic(fmt.Sprintf("Invalid trading strategy [%s]: %s", os.Args[2], err.Error()))
+		}
+		tradingStrategy = constants.TradingStrategy(parsedTradingStrategy)
+	}
+	candidatesSource := ""
+	if len(os.Args) > 3 {
+		candidatesSource = os.Args[3]
+	}
+	top := 10
+	if len(os.Args) > 4 {
+		parsedTop, err := strconv.Atoi(os.Args[4])
+		if err != nil {
+			panic(fmt.Sprintf("Invalid top [%s]: %s", os.Args[4], err.Error()))
+		}
+		top = parsedTop
+	}
+
 	var arguments = make([][]string, 0, 40)
 
 	arguments = append(arguments, []string{"XRPUSDT", "LTCUSDT", "2023-06-09", "2023-07-14"})
@@ -80,39 +96,40 @@ func main() {
 	//arguments = append(arguments, []string{"XMRUSDT", "SOLUSDT", "2022-02-10", "2023-05-31"})
 	//arguments = append(arguments, []string{"XMRUSDT", "ETHUSDT", "2022-02-10", "2023-05-31"})
 
+	if candidatesSource != "" {
+		candidates, err := findScannedCandidates(repos, candidatesSource, top)
+		if err != nil {
+			panic(fmt.Sprintf("Error during reading scanned pairs from [%s]: %s", candidatesSource, err.Error()))
+		}
+		arguments = arguments[:0]
+		for _, candidate := range candidates {
+			arguments = append(arguments, []string{candidate.Symbol1, candidate.Symbol2, candidate.From, candidate.To})
+		}
+	}
+
 	for _, argument := range arguments {
 		symbol1 := argument[0]
 		symbol2 := argument[1]
 		from := argument[2]
 		to := argument[3]
 
-		println(symbol1, symbol2, from, to)
-
-		coin1, _ := repos.Coin.FindBySymbol(symbol1)
-		coin2, _ := repos.Coin.FindBySymbol(symbol2)
-
-		tradingService := trading.NewPairArbitrageStrategyTradingService(
-			repos.Coin,
-			repos.Transaction,
-			clockMock,
-			exchangeDataService,
-			repos.SyntheticKline,
-			exchange.NewKlinesFetcherService(mockExchangeApi, repos.Kline, clockMock),
-			orderManagerService,
-			seriesConvertorService,
-			coin1,
-			coin2,
-		)
-		analyserService := analyser.NewAnalyserRunner(tradingService)
-
-		start := time.Now()
-		analyserService.AnalyseCoin(from, to, klineInterval)
-
-		end := time.Now()
-		zap.S().Infof("EXECUTED %s-%s  in %s", symbol1, symbol2, end.Sub(start).Milliseconds())
-
-		//tradingStrategy := 780 + i //770-780 fail
-		//postgresDb.Exec("update transaction_table set trading_strategy = $1 where trading_strategy = 6;", tradingStrategy)
+		zap.S().Infof("Backtest %s-%s from %s to %s with hedge ratio %s", symbol1, symbol2, from, to, hedgeRatioMethod)
+
+		fromTime, toTime := analyser.ParsePeriod(from, to)
+		run, backtestReport, err := backtester.Backtest(&trading.StrategyConfig{
+			Strategy:        trading.PAIR_ARBITRAGE_STRATEGY,
+			Coins:           []string{symbol1, symbol2},
+			Interval:        klineInterval,
+			TradingStrategy: tradingStrategy,
+			Params: map[string]interface{}{
+				"hedgeRatio": hedgeRatioMethod,
+			},
+		}, fromTime, toTime)
+		if err != nil {
+			zap.S().Errorf("Error during backtest of %s-%s: %s", symbol1, symbol2, err.Error())
+			continue
+		}
+		analyser.PrintReport(run, backtestReport)
 	}
 
 	if err := postgresDb.Close(); err != nil {
@@ -121,3 +138,11 @@ func main() {
 
 	os.Exit(0)
 }
+
+func findScannedCandidates(repos *repository.Repository, source string, top int) ([]scanner.PairCandidate, error) {
+	if source == "scan" {
+		scannerService := scanner.NewPairScannerService(repos.Coin, repos.Kline, repos.PairScanResult, indicator.NewCointegrationService(viper.GetInt("scanner.adfLags")))
+		return scannerService.FindLastScanCandidates(top)
+	}
+	return scanner.ReadReport(source, top)
+}
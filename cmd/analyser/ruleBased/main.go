@@ -0,0 +1,11 @@
+package main
+
+import (
+	"cryptoBot/cmd/analyser"
+	"cryptoBot/pkg/service/trading"
+)
+
+// Backtests rule based strategies from config.yml: ruleBased [from] [to] [name]
+func main() {
+	(&analyser.ConfigAnalyser{Configs: trading.NewRuleBasedStrategyConfigs}).Run()
+}
@@ -3,17 +3,9 @@ package main
 import (
 	"cryptoBot/cmd/analyser"
 	"cryptoBot/cmd/bootstrap"
-	"cryptoBot/pkg/api/bybit/mock"
-	"cryptoBot/pkg/constants"
 	"cryptoBot/pkg/log"
-	"cryptoBot/pkg/repository"
-	"cryptoBot/pkg/service/date"
-	"cryptoBot/pkg/service/exchange"
-	"cryptoBot/pkg/service/indicator"
-	"cryptoBot/pkg/service/indicator/techanLib"
-	"cryptoBot/pkg/service/orders"
 	"cryptoBot/pkg/service/trading"
-	"github.com/spf13/viper"
+	"fmt"
 	"go.uber.org/zap"
 	"os"
 )
@@ -31,42 +23,20 @@ func main() {
 	}()
 	postgresDb := bootstrap.Database(closableClosure)
 
-	repos := repository.NewRepositories(postgresDb)
-
-	mockExchangeApi := mock.NewBybitApiMock()
-
-	clockMock := date.GetClockMock()
-
-	seriesConvertorService := techanLib.NewTechanConvertorService(clockMock, repos.Kline)
-	exchangeDataService := exchange.NewExchangeDataService(repos.Transaction, repos.Coin, mockExchangeApi, clockMock, repos.Kline)
-	priceChangeTrackingService := orders.NewPriceChangeTrackingService(repos.PriceChange)
-
-	orderManagerService := orders.NewOrderManagerService(repos.Transaction, mockExchangeApi, clockMock, exchangeDataService, repos.Kline, constants.SMA_VOLUME_SCALPER, priceChangeTrackingService,
-		orders.NewProfitLossFinderService(clockMock, repos.Kline),
-		viper.GetInt64("strategy.smaVolumeScalper.futures.leverage"),
-		0, 0, 0, 0)
+	backtester := analyser.NewAnalyserBacktester(postgresDb)
 
 	klineInterval := 60
 
-	tradingService := trading.NewSmaVolumeScalperStrategyTradingService(
-		repos.Transaction,
-		clockMock,
-		exchangeDataService,
-		repos.Kline,
-		exchange.NewKlinesFetcherService(mockExchangeApi, repos.Kline, clockMock),
-		orderManagerService,
-		seriesConvertorService,
-		indicator.NewStochasticService(clockMock, repos.Kline, seriesConvertorService),
-		indicator.NewSmaTubeService(clockMock, repos.Kline),
-		indicator.NewLocalExtremumTrendService(clockMock, repos.Kline),
-		indicator.NewRelativeVolumeIndicatorService(),
-		klineInterval,
-	)
-	analyserService := analyser.NewAnalyserRunner(tradingService)
-
-	coin, _ := repos.Coin.FindBySymbol("ETHUSDT")
-
-	analyserService.AnalyseCoin(coin, "2020-11-12", "2023-04-23", klineInterval)
+	fromTime, toTime := analyser.ParsePeriod("2020-11-12", "2023-04-23")
+	run, backtestReport, err := backtester.Backtest(&trading.StrategyConfig{
+		Strategy: trading.SMA_VOLUME_SCALPER_STRATEGY,
+		Coins:    []string{"ETHUSDT"},
+		Interval: klineInterval,
+	}, fromTime, toTime)
+	if err != nil {
+		panic(fmt.Sprintf("Error during backtest: %s", err.Error()))
+	}
+	analyser.PrintReport(run, backtestReport)
 	//BTC from 2020-04-12
 	//ETH from 2020-11-12
 	//ETC from 2021-07-15
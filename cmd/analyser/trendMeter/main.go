@@ -5,7 +5,6 @@ import (
 	"cryptoBot/pkg/api/bybit/mock"
 	"cryptoBot/pkg/constants"
 	"cryptoBot/pkg/log"
-	"cryptoBot/pkg/repository"
 	"cryptoBot/pkg/repository/postgres"
 	"cryptoBot/pkg/service/analyser"
 	"cryptoBot/pkg/service/date"
@@ -13,6 +12,7 @@ import (
 	"cryptoBot/pkg/service/indicator"
 	"cryptoBot/pkg/service/indicator/techanLib"
 	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/signal"
 	"cryptoBot/pkg/service/trading"
 	"fmt"
 	"github.com/jmoiron/sqlx"
@@ -43,11 +43,12 @@ func main() {
 
 	postgresDb := bootstrap.Database(closableClosure)
 
-	repos := repository.NewRepositories(postgresDb)
+	repos := bootstrap.AnalyserRepositories(postgresDb)
 
 	mockExchangeApi := mock.NewBybitApiMock()
 
-	clockMock := date.GetClock()
+	// own clock of the run, the analyser service moves it
+	clockMock := date.NewClockMock(time.Time{})
 
 	seriesConvertorService := techanLib.NewTechanConvertorService(clockMock, repos.Kline)
 	exchangeDataService := exchange.NewExchangeDataService(repos.Transaction, repos.Coin, mockExchangeApi, clockMock, repos.Kline)
@@ -69,6 +70,7 @@ func main() {
 		indicator.NewRelativeStrengthIndexService(seriesConvertorService),
 		indicator.NewExponentialMovingAverageService(seriesConvertorService),
 		orderManagerService,
+		signal.NewExecutionSink(repos.Transaction, orderManagerService, constants.TREND_METER),
 		priceChangeTrackingService,
 		constants.SPOT,
 	)
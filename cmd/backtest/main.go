@@ -0,0 +1,125 @@
+package main
+
+import (
+	"cryptoBot/cmd/analyser"
+	"cryptoBot/cmd/bootstrap"
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/log"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/trading"
+	"flag"
+	"fmt"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"os"
+	"strings"
+)
+
+// paramFlags collects repeated -set key=value flags
+type paramFlags map[string]interface{}
+
+func (p paramFlags) String() string {
+	return fmt.Sprintf("%v", map[string]interface{}(p))
+}
+
+func (p paramFlags) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("expected key=value, got [%s]", value)
+	}
+	// lowercased as keys of the params file, so the flag replaces the value of the file
+	p[strings.ToLower(parts[0])] = parts[1]
+	return nil
+}
+
+// Backtests any registered strategy without changes in code or config.yml:
+// backtest -strategy breakout -coins BTCUSDT -from 2023-01-01 -to 2023-07-01 -interval 240 -capital 1000 -params configs/backtest/breakout.yml -set entryLength=30
+func main() {
+	strategy := flag.String("strategy", "", "name of the registered strategy, e.g. breakout, pairArbitrage")
+	coins := flag.String("coins", "", "comma separated coins, pair strategies take both legs, e.g. XRPUSDT,LTCUSDT")
+	from := flag.String("from", "", "start of the period, "+constants.DATE_FORMAT)
+	to := flag.String("to", "", "end of the period, "+constants.DATE_FORMAT)
+	interval := flag.Int("interval", 60, "kline interval in minutes")
+	capital := flag.Float64("capital", 0, "initial capital in USD, analyser.initialCapital by default")
+	paramsFile := flag.String("params", "", "yml or json file with strategy params overriding defaults of the strategy")
+	name := flag.String("name", "", "optional name of the run, strategy and coins by default")
+	params := paramFlags{}
+	flag.Var(params, "set", "strategy param key=value overriding the params file, can be repeated")
+	flag.Parse()
+
+	if *strategy == "" || *coins == "" || *from == "" || *to == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	bootstrap.Run()
+	log.InitLoggerAnalyser()
+
+	var closableClosure []func()
+
+	defer func() {
+		for i := range closableClosure {
+			closableClosure[i]()
+		}
+	}()
+
+	fromTime, toTime := analyser.ParsePeriod(*from, *to)
+	config := &trading.StrategyConfig{
+		Strategy: *strategy,
+		Name:     *name,
+		Coins:    strings.Split(*coins, ","),
+		Interval: *interval,
+		Params:   readParams(*paramsFile),
+	}
+	for key, value := range params {
+		config.Params[key] = value
+	}
+	if !trading.NewStrategyRegistry(&trading.StrategyDependencies{}).IsRegistered(config.Strategy) {
+		panic(fmt.Sprintf("Strategy [%s] is not registered", config.Strategy))
+	}
+
+	initialCapital := *capital
+	if initialCapital == 0 {
+		initialCapital = analyser.InitialCapital()
+	}
+
+	postgresDb := bootstrap.Database(closableClosure)
+	backtester := analyser.NewAnalyserBacktesterWithCapital(postgresDb, initialCapital)
+
+	if config.Strategy == trading.GRID_STRATEGY {
+		// levels are saved by grid name, so levels of the previous backtest are removed
+		if err := repository.NewRepositories(postgresDb).GridLevel.DeleteAllByGridName(config.Key()); err != nil {
+			panic(fmt.Sprintf("Error during removing levels of %s: %s", config.Key(), err.Error()))
+		}
+	}
+
+	zap.S().Infof("Backtesting %s from %s to %s with params %v", config.Key(), *from, *to, config.Params)
+	run, backtestReport, err := backtester.Backtest(config, fromTime, toTime)
+	if err != nil {
+		if run != nil {
+			fmt.Printf("Run %d failed: %s\n", run.Id, err.Error())
+		}
+		panic(fmt.Sprintf("Error during backtest of %s: %s", config.Key(), err.Error()))
+	}
+	analyser.PrintReport(run, backtestReport)
+
+	if err := postgresDb.Close(); err != nil {
+		zap.S().Errorf("error occured on db connection close: %s", err.Error())
+	}
+
+	os.Exit(0)
+}
+
+// readParams reads params file in any format supported by viper, keys are lowercased as in config.yml
+func readParams(path string) map[string]interface{} {
+	if path == "" {
+		return make(map[string]interface{})
+	}
+
+	reader := viper.New()
+	reader.SetConfigFile(path)
+	if err := reader.ReadInConfig(); err != nil {
+		panic(fmt.Sprintf("Error during reading params from %s: %s", path, err.Error()))
+	}
+	return reader.AllSettings()
+}
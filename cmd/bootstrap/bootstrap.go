@@ -1,6 +1,7 @@
 package bootstrap
 
 import (
+	"cryptoBot/pkg/repository"
 	"cryptoBot/pkg/repository/postgres"
 	"fmt"
 	"github.com/jmoiron/sqlx"
@@ -10,6 +11,7 @@ import (
 	"go.uber.org/zap"
 	"os"
 	"strconv"
+	"time"
 )
 
 func Run() {
@@ -55,6 +57,28 @@ func Database(closableClosure []func()) *sqlx.DB {
 	return postgresDb
 }
 
+// AnalyserRepositories returns postgres repositories or in-memory ones when analyser.inMemory is set,
+// in-memory backtests don't save transactions into postgres
+func AnalyserRepositories(postgresDb *sqlx.DB) *repository.Repository {
+	if !viper.GetBool("analyser.inMemory") {
+		return repository.NewRepositories(postgresDb)
+	}
+
+	return AnalyserMemoryStorage(postgresDb).NewRepositories()
+}
+
+// AnalyserMemoryStorage loads coins and funding rates from postgres and klines from postgres or analyser.klinesCsv,
+// every backtest takes own repositories from the storage
+func AnalyserMemoryStorage(postgresDb *sqlx.DB) *repository.MemoryStorage {
+	start := time.Now()
+	storage, err := repository.NewMemoryStorage(postgresDb, time.Time{}, time.Time{}, viper.GetStringSlice("analyser.klinesCsv")...)
+	if err != nil {
+		panic(fmt.Sprintf("FAILED to init in-memory repositories: %s", err.Error()))
+	}
+	zap.S().Infof("In-memory repositories are loaded in %d ms", time.Since(start).Milliseconds())
+	return storage
+}
+
 func initMigrations(db *sqlx.DB) {
 	migrations := &migrate.FileMigrationSource{
 		Dir: "./migrations",
@@ -5,7 +5,9 @@ import (
 	"cryptoBot/pkg/repository"
 	"cryptoBot/pkg/repository/postgres"
 	"cryptoBot/pkg/service/chart"
+	"cryptoBot/pkg/service/report"
 	"fmt"
+	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -58,9 +60,12 @@ func main() {
 		}
 	})
 
-	repos := repository.NewRepositories(postgresDb)
-
-	chartTradingStrategy(repos)
+	// pairArbitrage [runId]... - charts of backtest runs, charts of trading strategies without run otherwise
+	if len(os.Args) > 1 {
+		chartRuns(postgresDb, os.Args[1:])
+	} else {
+		chartTradingStrategy(repository.NewRepositories(postgresDb))
+	}
 
 	if err := postgresDb.Close(); err != nil {
 		zap.S().Errorf("error occured on db connection close: %s", err.Error())
@@ -71,9 +76,37 @@ func main() {
 
 func chartTradingStrategy(repos *repository.Repository) {
 	snapshotOrderService := chart.NewChartTradingStrategyService(repos.Transaction, repos.Coin)
+	snapshotOrderService.Benchmark = report.NewBenchmarkService(repos.Kline, repos.Coin, report.NewBenchmarkConfig())
+	snapshotOrderService.BenchmarkInterval = "60"
 
 	for i := 600; i < 799; i++ {
 		snapshotOrderService.ChartWalletTradingStrategy(i)
 		snapshotOrderService.ChartTransactionsTradingStrategy(i)
+		snapshotOrderService.ChartEquityCurvesTradingStrategy(i)
+	}
+}
+
+func chartRuns(postgresDb *sqlx.DB, args []string) {
+	runRepo := postgres.NewBacktestRun(postgresDb)
+	for _, arg := range args {
+		runId, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			zap.S().Errorf("Invalid run id [%s]: %s", arg, err.Error())
+			continue
+		}
+		run, err := runRepo.FindById(runId)
+		if err != nil || run == nil {
+			zap.S().Errorf("Run %d not found: %v", runId, err)
+			continue
+		}
+
+		repos := repository.NewRunRepositories(postgresDb, runId)
+		chartService := chart.NewChartTradingStrategyService(repos.Transaction, repos.Coin)
+		chartService.NamePrefix = fmt.Sprintf("run %d ", runId)
+		chartService.Benchmark = report.NewBenchmarkService(repos.Kline, repos.Coin, report.NewBenchmarkConfig())
+		chartService.BenchmarkInterval = strconv.Itoa(run.Interval)
+		chartService.ChartWalletTradingStrategy(int(run.TradingStrategy))
+		chartService.ChartTransactionsTradingStrategy(int(run.TradingStrategy))
+		chartService.ChartEquityCurvesTradingStrategy(int(run.TradingStrategy))
 	}
 }
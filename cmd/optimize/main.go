@@ -0,0 +1,97 @@
+package main
+
+import (
+	"cryptoBot/cmd/analyser"
+	"cryptoBot/cmd/bootstrap"
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/log"
+	"cryptoBot/pkg/service/optimizer"
+	"cryptoBot/pkg/service/trading"
+	"fmt"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"os"
+	"time"
+)
+
+// Searches the best params of optimize.strategy over optimize.ranges from config.yml: optimize [from] [to] [metric].
+// With optimize.walkForward.inSampleDays params are optimized on rolling in-sample periods and validated on the next out-of-sample ones
+func main() {
+	bootstrap.Run()
+	log.InitLoggerAnalyser()
+
+	var closableClosure []func()
+
+	defer func() {
+		for i := range closableClosure {
+			closableClosure[i]()
+		}
+	}()
+	postgresDb := bootstrap.Database(closableClosure)
+	storage := bootstrap.AnalyserMemoryStorage(postgresDb)
+
+	from := viper.GetString("optimize.from")
+	to := viper.GetString("optimize.to")
+	metricName := viper.GetString("optimize.metric")
+	if len(os.Args) > 2 {
+		from = os.Args[1]
+		to = os.Args[2]
+	}
+	if len(os.Args) > 3 {
+		metricName = os.Args[3]
+	}
+
+	fromTime, err := time.Parse(constants.DATE_FORMAT, from)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid from [%s]: %s", from, err.Error()))
+	}
+	toTime, err := time.Parse(constants.DATE_FORMAT, to)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid to [%s]: %s", to, err.Error()))
+	}
+	metric, err := optimizer.ParseMetric(metricName)
+	if err != nil {
+		panic(err.Error())
+	}
+	ranges, err := optimizer.NewParameterRanges(viper.GetStringMap("optimize.ranges"))
+	if err != nil {
+		panic(err.Error())
+	}
+
+	config := &trading.StrategyConfig{
+		Strategy: viper.GetString("optimize.strategy"),
+		Coins:    viper.GetStringSlice("optimize.coins"),
+		Interval: viper.GetInt("optimize.interval"),
+		Params:   viper.GetStringMap("optimize.params"),
+	}
+	if config.Interval == 0 {
+		config.Interval = 60
+	}
+
+	optimizerService := optimizer.NewOptimizerService(analyser.NewInMemoryBacktester(storage, analyser.InitialCapital()), metric, viper.GetInt("optimize.workers"))
+	zap.S().Infof("Optimizing %s on %d combinations from %s to %s by %s", config.Key(), len(optimizer.BuildGrid(ranges)), from, to, metricName)
+
+	inSampleDays := viper.GetInt("optimize.walkForward.inSampleDays")
+	if inSampleDays > 0 {
+		outOfSampleDays := viper.GetInt("optimize.walkForward.outOfSampleDays")
+		windows, err := optimizerService.WalkForward(config, ranges, fromTime, toTime,
+			time.Hour*24*time.Duration(inSampleDays), time.Hour*24*time.Duration(outOfSampleDays))
+		if err != nil {
+			panic(fmt.Sprintf("Walk-forward of %s failed: %s", config.Key(), err.Error()))
+		}
+		fmt.Println(optimizer.FormatWalkForward(windows, ranges, metricName))
+	} else {
+		results, err := optimizerService.Optimize(config, ranges, fromTime, toTime)
+		if err != nil {
+			panic(fmt.Sprintf("Optimization of %s failed: %s", config.Key(), err.Error()))
+		}
+		fmt.Println(optimizer.FormatResults(results, ranges, metricName, viper.GetInt("optimize.top")))
+		fmt.Println(results[0].Report.String())
+	}
+
+	if err := postgresDb.Close(); err != nil {
+		zap.S().Errorf("error occured on db connection close: %s", err.Error())
+	}
+
+	os.Exit(0)
+}
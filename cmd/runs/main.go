@@ -0,0 +1,133 @@
+package main
+
+import (
+	"cryptoBot/cmd/bootstrap"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/log"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/chart"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/report"
+	"fmt"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"os"
+	"strconv"
+)
+
+const DEFAULT_RUNS_LIMIT = 20
+
+// Manages saved backtest runs: runs list [limit] | runs show [id] | runs compare [id] [id]... | runs delete [id]...
+// | runs monteCarlo [id]... - robustness of trades of the runs by shuffled, resampled and skipped trades, charts are saved into charts
+func main() {
+	bootstrap.Run()
+	log.InitLoggerAnalyser()
+
+	var closableClosure []func()
+
+	defer func() {
+		for i := range closableClosure {
+			closableClosure[i]()
+		}
+	}()
+	postgresDb := bootstrap.Database(closableClosure)
+	runService := report.NewBacktestRunService(repository.NewRepositories(postgresDb).BacktestRun, date.GetClock())
+
+	command := "list"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+	ids := parseIds(os.Args[2:])
+
+	switch command {
+	case "list":
+		limit := DEFAULT_RUNS_LIMIT
+		if len(ids) > 0 {
+			limit = int(ids[0])
+		}
+		runs, err := runService.FindAll(limit)
+		if err != nil {
+			panic(fmt.Sprintf("Error during loading runs: %s", err.Error()))
+		}
+		fmt.Print(report.FormatRuns(runs))
+	case "show":
+		runs, err := runService.FindAllByIds(ids)
+		if err != nil {
+			panic(err.Error())
+		}
+		for _, run := range runs {
+			fmt.Printf("%s\nparams: %s\n%s\n", run.String(), run.Params, run.Report.String)
+		}
+	case "compare":
+		runs, err := runService.FindAllByIds(ids)
+		if err != nil {
+			panic(err.Error())
+		}
+		fmt.Print(report.FormatComparison(runs))
+	case "monteCarlo":
+		runs, err := runService.FindAllByIds(ids)
+		if err != nil {
+			panic(err.Error())
+		}
+		for _, run := range runs {
+			if err := monteCarlo(run); err != nil {
+				zap.S().Errorf("Error during Monte Carlo of run %d: %s", run.Id, err.Error())
+			}
+		}
+	case "delete":
+		for _, id := range ids {
+			if err := runService.Delete(id); err != nil {
+				zap.S().Errorf("Error during deleting run %d: %s", id, err.Error())
+				continue
+			}
+			zap.S().Infof("Run %d is deleted", id)
+		}
+	default:
+		panic(fmt.Sprintf("Unknown command [%s], expected list, show, compare, monteCarlo or delete", command))
+	}
+
+	if err := postgresDb.Close(); err != nil {
+		zap.S().Errorf("error occured on db connection close: %s", err.Error())
+	}
+
+	os.Exit(0)
+}
+
+func monteCarlo(run *domains.BacktestRun) error {
+	backtestReport, err := report.ParseReport(run)
+	if err != nil {
+		return err
+	}
+
+	config := report.MonteCarloConfig{
+		Simulations:         viper.GetInt("monteCarlo.simulations"),
+		SkipPercent:         viper.GetFloat64("monteCarlo.skipPercent"),
+		RuinDrawdownPercent: viper.GetFloat64("monteCarlo.ruinDrawdownPercent"),
+		Seed:                viper.GetInt64("monteCarlo.seed"),
+	}
+	results := report.RunMonteCarlo(backtestReport.InitialCapital, backtestReport.TradeList, config)
+	fmt.Print(report.FormatMonteCarlo(fmt.Sprintf("run %d %s", run.Id, run.Name), results))
+
+	actualEquity := make([]float64, 0, len(backtestReport.TradeList)+1)
+	for _, point := range report.BuildEquity(backtestReport.InitialCapital, backtestReport.From, backtestReport.TradeList) {
+		actualEquity = append(actualEquity, point.Equity)
+	}
+	chartService := chart.NewChartMonteCarloService(fmt.Sprintf("run %d ", run.Id))
+	for _, result := range results {
+		chartService.ChartEquityPercentiles(result, actualEquity)
+		chartService.ChartFinalEquityDistribution(result)
+	}
+	return nil
+}
+
+func parseIds(args []string) []int64 {
+	ids := make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("Invalid number [%s]: %s", arg, err.Error()))
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"cryptoBot/cmd/bootstrap"
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/log"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/indicator"
+	"cryptoBot/pkg/service/scanner"
+	"fmt"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"os"
+	"strings"
+	"time"
+)
+
+// Finds cointegrated pairs in kline table: scanner [from] [to] [interval] [symbols comma separated]
+func main() {
+	bootstrap.Run()
+	log.InitLoggerAnalyser()
+
+	var closableClosure []func()
+
+	defer func() {
+		for i := range closableClosure {
+			closableClosure[i]()
+		}
+	}()
+	postgresDb := bootstrap.Database(closableClosure)
+	repos := repository.NewRepositories(postgresDb)
+
+	from := "2023-01-01"
+	to := "2023-07-01"
+	interval := "60"
+	var symbols []string
+	if len(os.Args) > 2 {
+		from = os.Args[1]
+		to = os.Args[2]
+	}
+	if len(os.Args) > 3 {
+		interval = os.Args[3]
+	}
+	if len(os.Args) > 4 {
+		symbols = strings.Split(os.Args[4], ",")
+	}
+
+	timeFrom, err := time.Parse(constants.DATE_FORMAT, from)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid date from [%s]: %s", from, err.Error()))
+	}
+	timeTo, err := time.Parse(constants.DATE_FORMAT, to)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid date to [%s]: %s", to, err.Error()))
+	}
+
+	scannerService := scanner.NewPairScannerService(
+		repos.Coin,
+		repos.Kline,
+		repos.PairScanResult,
+		indicator.NewCointegrationService(viper.GetInt("scanner.adfLags")),
+	)
+
+	start := time.Now()
+	results, err := scannerService.Scan(scanner.PairScanConfig{
+		Symbols:        symbols,
+		Interval:       interval,
+		From:           timeFrom,
+		To:             timeTo,
+		WindowSize:     viper.GetInt("scanner.windowSize"),
+		WindowStep:     viper.GetInt("scanner.windowStep"),
+		MinCorrelation: viper.GetFloat64("scanner.minCorrelation"),
+		MaxHalfLife:    viper.GetFloat64("scanner.maxHalfLife"),
+	})
+	if err != nil {
+		zap.S().Fatalf("Error during scan: %s", err.Error())
+	}
+
+	if err := scannerService.Save(results); err != nil {
+		zap.S().Errorf("Error during saving scan results: %s", err.Error())
+	}
+
+	reportPath := viper.GetString("scanner.report")
+	if err := scannerService.WriteReport(reportPath, results); err != nil {
+		zap.S().Errorf("Error during writing report %s: %s", reportPath, err.Error())
+	}
+
+	zap.S().Infof("SCANNED %d pairs in %s, report %s", len(results), time.Since(start), reportPath)
+
+	if err := postgresDb.Close(); err != nil {
+		zap.S().Errorf("error occured on db connection close: %s", err.Error())
+	}
+
+	os.Exit(0)
+}
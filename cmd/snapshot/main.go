@@ -13,6 +13,7 @@ import (
 	"go.uber.org/zap"
 	"os"
 	"strconv"
+	"time"
 )
 
 func initLocalConfig() error {
@@ -72,7 +73,7 @@ func main() {
 }
 
 func test(repos *repository.Repository) {
-	clockMock := date.GetClockMock()
+	clockMock := date.NewClockMock(time.Time{})
 	snapshotOrderService := snapshot.NewSnapshotOrderService(repos.Transaction, repos.Kline, indicator.NewLocalExtremumTrendService(clockMock, repos.Kline))
 
 	coin, _ := repos.Coin.FindBySymbol("BTCUSDT")
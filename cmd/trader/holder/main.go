@@ -10,6 +10,7 @@ import (
 	"cryptoBot/pkg/log"
 	"cryptoBot/pkg/repository"
 	"cryptoBot/pkg/repository/postgres"
+	"cryptoBot/pkg/service/date"
 	"cryptoBot/pkg/service/telegram"
 	"cryptoBot/pkg/service/trading"
 	"fmt"
@@ -72,7 +73,7 @@ func main() {
 
 	exchangeApi := binance.NewBinanceApi()
 
-	tradingService := trading.NewHolderStrategyTradingService(repos.Transaction, repos.PriceChange, exchangeApi)
+	tradingService := trading.NewHolderStrategyTradingService(repos.Transaction, repos.PriceChange, exchangeApi, date.GetClock())
 	telegramService := telegram.NewTelegramService(repos.Transaction, repos.Coin, exchangeApi)
 
 	if enabled, err := strconv.ParseBool(os.Getenv("TRADING_ENABLED")); enabled && err == nil {
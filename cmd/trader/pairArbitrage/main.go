@@ -4,25 +4,27 @@ import (
 	"context"
 	"cryptoBot"
 	"cryptoBot/cmd/bootstrap"
+	"cryptoBot/pkg/api"
 	"cryptoBot/pkg/api/bybit"
-	"cryptoBot/pkg/constants"
+	telegramApi "cryptoBot/pkg/api/telegram"
 	"cryptoBot/pkg/controller"
 	"cryptoBot/pkg/cron"
 	"cryptoBot/pkg/log"
 	"cryptoBot/pkg/repository"
 	"cryptoBot/pkg/service/date"
-	"cryptoBot/pkg/service/exchange"
-	"cryptoBot/pkg/service/indicator/techanLib"
-	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/portfolio"
+	"cryptoBot/pkg/service/state"
 	"cryptoBot/pkg/service/statistic"
 	"cryptoBot/pkg/service/telegram"
 	"cryptoBot/pkg/service/trading"
 	"fmt"
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func main() {
@@ -42,40 +44,59 @@ func main() {
 	repos := repository.NewRepositories(postgresDb)
 	exchangeApi := bybit.NewBybitApi(os.Getenv("BYBIT_PairTrading1_API_KEY"), os.Getenv("BYBIT_PairTrading1_API_SECRET"))
 	clock := date.GetClock()
+	strategyStateService := state.NewStrategyStateService(repos.StrategyState, clock)
+	capitalAllocator, err := portfolio.NewCapitalAllocatorServiceFromConfig(repos.Transaction, clock)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid portfolio config: %s", err.Error()))
+	}
 
-	seriesConvertorService := techanLib.NewTechanConvertorService(clock, repos.Kline)
-	exchangeDataService := exchange.NewExchangeDataService(repos.Transaction, repos.Coin, exchangeApi, clock, repos.Kline)
-	priceChangeTrackingService := orders.NewPriceChangeTrackingService(repos.PriceChange)
-	klinesFetcherService := exchange.NewKlinesFetcherService(exchangeApi, repos.Kline, clock)
-
-	orderManagerService := orders.NewOrderManagerService(repos.Transaction, exchangeApi, clock, exchangeDataService, repos.Kline, constants.PAIR_ARBITRAGE, priceChangeTrackingService,
-		orders.NewProfitLossFinderService(clock, repos.Kline),
-		0,
-		0, 0, 0, 0)
-
-	tradingService := trading.NewPairArbitrageStrategyTradingService(
-		repos.Coin,
-		repos.Transaction,
-		clock,
-		exchangeDataService,
-		repos.SyntheticKline,
-		klinesFetcherService,
-		orderManagerService,
-		seriesConvertorService,
-		nil,
-		nil,
-	)
-
-	tradingServiceContainer := trading.NewPairArbitrageStrategyTradingServiceContainer(tradingService)
-	tradingServiceContainer.Initialize()
-	cron.InitCronJobs(tradingServiceContainer)
+	registry := trading.NewStrategyRegistry(&trading.StrategyDependencies{
+		Repos: repos,
+		Clock: clock,
+		ExchangeApiProvider: func(account trading.StrategyAccount) api.ExchangeApi {
+			return bybit.NewBybitApi(os.Getenv(account.ApiKeyEnv), os.Getenv(account.ApiSecretEnv))
+		},
+		StrategyStateService: strategyStateService,
+		CapitalAllocator:     capitalAllocator,
+	})
+	executor := trading.NewStrategyExecutor(registry.Instances, viper.GetInt("executor.workers"), time.Duration(viper.GetInt("executor.unitTimeoutSeconds"))*time.Second)
+	tradingServiceContainer := trading.NewPairArbitrageStrategyTradingServiceContainer(registry, executor, repos.SyntheticKline)
+
+	pairs, err := loadPairs()
+	if err != nil {
+		panic(fmt.Sprintf("Invalid strategy.pairArbitrage.pairs: %s", err.Error()))
+	}
+	if err := tradingServiceContainer.Reload(pairs); err != nil {
+		panic(fmt.Sprintf("Error during creating pairs: %s", err.Error()))
+	}
+	// pairs run on klines of their own intervals
+	cron.InitMinuteCronJobs(tradingServiceContainer)
+
+	// pairs can be added, tuned or disabled in config.yml without restart, invalid config is ignored and running pairs keep trading
+	viper.OnConfigChange(func(event fsnotify.Event) {
+		pairs, err := loadPairs()
+		if err == nil {
+			err = tradingServiceContainer.Reload(pairs)
+		}
+		if err != nil {
+			zap.S().Errorf("Pairs aren't reloaded from %s: %s", event.Name, err.Error())
+			telegramApi.SendTextToTelegramChat(fmt.Sprintf("Pairs aren't reloaded: %s", err.Error()))
+			return
+		}
+		zap.S().Infof("Pairs are reloaded from %s", event.Name)
+	})
+	viper.WatchConfig()
 
 	statisticPairTradingService := statistic.NewStatisticPairTradingService(repos.Transaction, repos.Coin, exchangeApi)
 
 	cron.NewStatisticJob(statisticPairTradingService)
-	telegramService := telegram.NewTelegramPairTradingService(repos.Transaction, repos.Coin, exchangeApi, statisticPairTradingService)
+	telegramService := telegram.NewTelegramPairTradingService(repos.Transaction, repos.Coin, exchangeApi, statisticPairTradingService, strategyStateService)
 
 	router := controller.InitControllers(telegramService)
+	controller.InitStrategyStateEndpoints(router, strategyStateService)
+	if capitalAllocator != nil {
+		controller.InitPortfolioEndpoints(router, capitalAllocator)
+	}
 
 	srv := new(cryptoBot.Server)
 	go func() {
@@ -98,3 +119,16 @@ func main() {
 		zap.S().Errorf("error occured on db connection close: %s", err.Error())
 	}
 }
+
+func loadPairs() ([]*trading.PairArbitrageConfig, error) {
+	pairs, err := trading.LoadPairArbitrageConfigs()
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range pairs {
+		if err := pair.ValidateAccount(); err != nil {
+			return nil, err
+		}
+	}
+	return pairs, nil
+}
@@ -16,6 +16,7 @@ import (
 	"cryptoBot/pkg/service/indicator"
 	"cryptoBot/pkg/service/indicator/techanLib"
 	"cryptoBot/pkg/service/orders"
+	strategySignal "cryptoBot/pkg/service/signal"
 	"cryptoBot/pkg/service/telegram"
 	"cryptoBot/pkg/service/trading"
 	"fmt"
@@ -100,7 +101,8 @@ func main() {
 		viper.GetInt64("strategy.trendMeter.futures.leverage"),
 		0.0, 0.0, 0.0, 0.0)
 
-	tradingService := trading.NewTrendMeterStrategyTradingService(repos.Transaction, date.GetClock(), exchangeDataService, repos.Kline, stdDevService, fetcherService, macdService, rsiService, emaService, orderManagerService, priceChangeTrackingService, constants.SPOT)
+	tradingService := trading.NewTrendMeterStrategyTradingService(repos.Transaction, date.GetClock(), exchangeDataService, repos.Kline, stdDevService, fetcherService, macdService, rsiService, emaService, orderManagerService,
+		strategySignal.NewExecutionSink(repos.Transaction, orderManagerService, constants.TREND_METER), priceChangeTrackingService, constants.SPOT)
 
 	telegramService := telegram.NewTelegramService(repos.Transaction, repos.Coin, exchangeApi)
 
@@ -3,6 +3,7 @@ module cryptoBot
 go 1.17
 
 require (
+	github.com/fsnotify/fsnotify v1.5.1
 	github.com/go-chi/chi v1.5.4
 	github.com/jackc/pgx v3.6.2+incompatible
 	github.com/jasonlvhit/gocron v0.0.1
@@ -10,6 +11,7 @@ require (
 	github.com/joho/godotenv v1.4.0
 	github.com/rubenv/sql-migrate v1.0.0
 	github.com/shopspring/decimal v1.3.1
+	github.com/spf13/cast v1.4.1
 	github.com/spf13/viper v1.10.1
 	go.uber.org/zap v1.19.1
 )
@@ -17,7 +19,6 @@ require (
 require (
 	github.com/cockroachdb/apd v1.1.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/fsnotify/fsnotify v1.5.1 // indirect
 	github.com/google/uuid v1.3.1 // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/lib/pq v1.10.2 // indirect
@@ -29,7 +30,6 @@ require (
 	github.com/robfig/cron/v3 v3.0.1 // indirect
 	github.com/sirupsen/logrus v1.7.0 // indirect
 	github.com/spf13/afero v1.6.0 // indirect
-	github.com/spf13/cast v1.4.1 // indirect
 	github.com/spf13/jwalterweatherman v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/stretchr/testify v1.8.2 // indirect
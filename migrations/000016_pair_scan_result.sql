@@ -0,0 +1,32 @@
+-- +migrate Up
+create table if not exists pair_scan_result
+(
+    id                   SERIAL
+        constraint pair_scan_result_pkey primary key,
+    scanned_at           timestamp NOT NULL,
+    coin_id_1            bigint    NOT NULL
+        constraint coin_id_1_fkey references coin,
+    coin_id_2            bigint    NOT NULL
+        constraint coin_id_2_fkey references coin,
+    duration             text      NOT NULL,
+    from_time            timestamp NOT NULL,
+    to_time              timestamp NOT NULL,
+    window_size          int       NOT NULL,
+    correlation          float     NOT NULL,
+    hedge_ratio          float     NOT NULL,
+    adf_statistic        float     NOT NULL,
+    half_life            float     NOT NULL,
+    cointegrated_windows int       NOT NULL,
+    total_windows        int       NOT NULL,
+    score                float     NOT NULL,
+    rank                 int       NOT NULL
+);
+
+-- +migrate Up
+CREATE INDEX pair_scan_result_scanned_at_idx ON pair_scan_result (scanned_at, rank);
+
+-- +migrate Down
+DROP INDEX pair_scan_result_scanned_at_idx;
+
+-- +migrate Down
+DROP TABLE pair_scan_result;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE transaction_table
+    ADD close_reason text;
+
+-- +migrate Down
+ALTER TABLE transaction_table
+    DROP COLUMN close_reason;
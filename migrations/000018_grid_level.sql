@@ -0,0 +1,21 @@
+-- +migrate Up
+create table if not exists grid_level
+(
+    id             SERIAL
+        constraint grid_level_pkey primary key,
+    grid_name      text      NOT NULL,
+    coin_id        bigint    NOT NULL
+        constraint coin_id_fkey references coin,
+    level_index    int       NOT NULL,
+    open_price     float     NOT NULL,
+    close_price    float     NOT NULL,
+    quantity       float     NOT NULL,
+    state          text      NOT NULL,
+    transaction_id bigint
+        constraint transaction_id_fkey references transaction_table,
+    updated_at     timestamp NOT NULL,
+    constraint grid_level_unique unique (grid_name, level_index)
+);
+
+-- +migrate Down
+DROP TABLE grid_level;
@@ -0,0 +1,32 @@
+-- +migrate Up
+create table if not exists funding_rate
+(
+    id           SERIAL
+        constraint funding_rate_pkey primary key,
+    coin_id      bigint    NOT NULL
+        constraint coin_id_fkey references coin,
+    funding_time timestamp NOT NULL,
+    rate         float     NOT NULL,
+    constraint funding_rate_unique unique (coin_id, funding_time)
+);
+
+-- +migrate Up
+create table if not exists funding_payment
+(
+    id             SERIAL
+        constraint funding_payment_pkey primary key,
+    transaction_id bigint    NOT NULL
+        constraint transaction_id_fkey references transaction_table,
+    coin_id        bigint    NOT NULL
+        constraint coin_id_fkey references coin,
+    funding_time   timestamp NOT NULL,
+    rate           float     NOT NULL,
+    income         bigint    NOT NULL,
+    constraint funding_payment_unique unique (transaction_id, funding_time)
+);
+
+-- +migrate Down
+DROP TABLE funding_payment;
+
+-- +migrate Down
+DROP TABLE funding_rate;
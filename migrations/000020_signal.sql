@@ -0,0 +1,26 @@
+-- +migrate Up
+create table if not exists signal
+(
+    id                SERIAL
+        constraint signal_pkey primary key,
+    trading_strategy  int       NOT NULL,
+    trading_key       text      NOT NULL,
+    coin_id           bigint    NOT NULL
+        constraint coin_id_fkey references coin,
+    signal_type       text      NOT NULL,
+    futures_type      int       NOT NULL,
+    reason            text,
+    price             float     NOT NULL,
+    cost              float     NOT NULL,
+    stop_loss_price   float,
+    take_profit_price float,
+    indicators        jsonb,
+    related_signal_id bigint
+        constraint related_signal_id_fkey references signal,
+    transaction_id    bigint
+        constraint transaction_id_fkey references transaction_table,
+    created_at        timestamp NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE signal;
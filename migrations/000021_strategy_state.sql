@@ -0,0 +1,15 @@
+-- +migrate Up
+create table if not exists strategy_state
+(
+    id           SERIAL
+        constraint strategy_state_pkey primary key,
+    strategy_key text      NOT NULL,
+    state_key    text      NOT NULL,
+    value        text      NOT NULL,
+    version      int       NOT NULL,
+    updated_at   timestamp NOT NULL,
+    constraint strategy_state_unique unique (strategy_key, state_key)
+);
+
+-- +migrate Down
+DROP TABLE strategy_state;
@@ -0,0 +1,40 @@
+-- +migrate Up
+create table if not exists backtest_run
+(
+    id                   SERIAL
+        constraint backtest_run_pkey primary key,
+    name                 text      NOT NULL,
+    strategy             text      NOT NULL,
+    trading_strategy     int       NOT NULL,
+    coins                text      NOT NULL,
+    interval             int       NOT NULL,
+    params               jsonb     NOT NULL,
+    from_time            timestamp NOT NULL,
+    to_time              timestamp NOT NULL,
+    git_revision         text      NOT NULL,
+    initial_capital      float     NOT NULL,
+    status               text      NOT NULL,
+    error                text,
+    net_pnl              float     NOT NULL DEFAULT 0,
+    return_percent       float     NOT NULL DEFAULT 0,
+    sharpe               float     NOT NULL DEFAULT 0,
+    max_drawdown_percent float     NOT NULL DEFAULT 0,
+    trades               int       NOT NULL DEFAULT 0,
+    win_rate             float     NOT NULL DEFAULT 0,
+    profit_factor        float     NOT NULL DEFAULT 0,
+    report               jsonb,
+    created_at           timestamp NOT NULL,
+    finished_at          timestamp
+);
+
+ALTER TABLE transaction_table
+    ADD run_id bigint
+        constraint run_id_fkey references backtest_run;
+
+CREATE INDEX transaction_table_run_id_idx ON transaction_table (run_id);
+
+-- +migrate Down
+ALTER TABLE transaction_table
+    DROP COLUMN run_id;
+
+DROP TABLE backtest_run;
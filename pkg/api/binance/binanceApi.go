@@ -33,6 +33,10 @@ func (api *BinanceApi) GetKlines(coin *domains.Coin, interval string, limit int,
 	return nil, errors.New("Not implemented for Binance API")
 }
 
+func (api *BinanceApi) GetFundingRateHistory(coin *domains.Coin, startTime time.Time, endTime time.Time) ([]api.FundingRateDto, error) {
+	return nil, errors.New("Not implemented for Binance API")
+}
+
 func (api *BinanceApi) GetKlinesFutures(coin *domains.Coin, interval string, limit int, fromTime time.Time) (api.KlinesDto, error) {
 	return nil, errors.New("Not implemented for Binance API")
 }
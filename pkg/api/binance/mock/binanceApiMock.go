@@ -17,6 +17,10 @@ func NewBinanceApiMock() api.ExchangeApi {
 type BinanceApiMock struct {
 }
 
+func (api *BinanceApiMock) GetFundingRateHistory(coin *domains.Coin, startTime time.Time, endTime time.Time) ([]api.FundingRateDto, error) {
+	return nil, errors.New("Not implemented for Binance API")
+}
+
 func (api *BinanceApiMock) GetKlines(coin *domains.Coin, interval string, limit int, fromTime time.Time) (api.KlinesDto, error) {
 	return nil, errors.New("Not implemented for Binance API")
 }
@@ -81,6 +81,29 @@ func (bybitApi *BybitApi) GetKlinesFutures(coin *domains.Coin, interval string,
 	return dto, nil
 }
 
+func (bybitApi *BybitApi) GetFundingRateHistory(coin *domains.Coin, startTime time.Time, endTime time.Time) ([]api.FundingRateDto, error) {
+	resp, err := http.Get("https://api.bytick.com/v5/market/funding/history?" +
+		"category=linear" +
+		"&symbol=" + coin.Symbol +
+		"&startTime=" + strconv.FormatInt(util.GetMillisByTime(startTime), 10) +
+		"&endTime=" + strconv.FormatInt(util.GetMillisByTime(endTime), 10) +
+		"&limit=200")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var dto bybit.FundingRateHistoryDto
+	if err := json.NewDecoder(resp.Body).Decode(&dto); err != nil {
+		return nil, err
+	}
+	if dto.RetCode != 0 {
+		return nil, errors.New(dto.RetMsg)
+	}
+
+	return dto.GetFundingRates(), nil
+}
+
 func (api *BybitApi) GetCurrentCoinPriceForFutures(coin *domains.Coin) (float64, error) {
 	resp, err := http.Get("https://api.bytick.com/derivatives/v3/public/tickers?symbol=" + coin.Symbol)
 	if err != nil {
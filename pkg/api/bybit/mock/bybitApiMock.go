@@ -19,7 +19,18 @@ func NewBybitApiMock() api.ExchangeApi {
 	return &BybitApiMock{}
 }
 
+// NewBybitApiMockWithFillModel fills market orders by the fill model instead of the requested price
+func NewBybitApiMockWithFillModel(fillModel *FillModel) api.ExchangeApi {
+	return &BybitApiMock{fillModel: fillModel}
+}
+
 type BybitApiMock struct {
+	/* Optional, nil fills orders at the requested price */
+	fillModel *FillModel
+
+	/* Spot positions bought and not sold yet, kept per instance since backtests may run in parallel */
+	countOfNotSoldTransactions    int
+	maxCountOfNotSoldTransactions int
 }
 
 func (api *BybitApiMock) GetKlines(coin *domains.Coin, interval string, limit int, fromTime time.Time) (api.KlinesDto, error) {
@@ -45,17 +56,34 @@ func (api *BybitApiMock) GetKlinesFutures(coin *domains.Coin, interval string, l
 	return nil, errors.New("Not implemented for Bybit API mock")
 }
 
+func (api *BybitApiMock) GetFundingRateHistory(coin *domains.Coin, startTime time.Time, endTime time.Time) ([]api.FundingRateDto, error) {
+	resp, err := http.Get("https://api.bytick.com/v5/market/funding/history?" +
+		"category=linear" +
+		"&symbol=" + coin.Symbol +
+		"&startTime=" + strconv.FormatInt(util.GetMillisByTime(startTime), 10) +
+		"&endTime=" + strconv.FormatInt(util.GetMillisByTime(endTime), 10) +
+		"&limit=200")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var dto bybit.FundingRateHistoryDto
+	if err := json.NewDecoder(resp.Body).Decode(&dto); err != nil {
+		return nil, err
+	}
+	if dto.RetCode != 0 {
+		return nil, errors.New(dto.RetMsg)
+	}
+
+	return dto.GetFundingRates(), nil
+}
+
 func (api *BybitApiMock) OpenFuturesOrder(coin *domains.Coin, amount float64, price float64, futuresType futureType.FuturesType, stopLossPriceInCents float64) (api.OrderResponseDto, error) {
-	return &orderResponseMockDto{
-		price:  price,
-		amount: amount,
-	}, nil
+	return api.fill(coin, amount, price, futuresType == futureType.LONG, false)
 }
 func (api *BybitApiMock) CloseFuturesOrder(coin *domains.Coin, openedTransaction *domains.Transaction, price float64) (api.OrderResponseDto, error) {
-	return &orderResponseMockDto{
-		price:  price,
-		amount: openedTransaction.Amount,
-	}, nil
+	return api.fill(coin, openedTransaction.Amount, price, openedTransaction.FuturesType == futureType.SHORT, true)
 }
 
 func (api *BybitApiMock) GetCurrentCoinPriceForFutures(coin *domains.Coin) (float64, error) {
@@ -66,30 +94,21 @@ func (api *BybitApiMock) GetCurrentCoinPrice(coin *domains.Coin) (float64, error
 	return 0, errors.New("Shouldn't be called.")
 }
 
-var countOfNotSoldTransactions = 0
-var maxCountOfNotSoldTransactions = 0
-
 func (api *BybitApiMock) BuyCoinByMarket(coin *domains.Coin, amount float64, price float64) (api.OrderResponseDto, error) {
-	countOfNotSoldTransactions = countOfNotSoldTransactions + 1
+	api.countOfNotSoldTransactions = api.countOfNotSoldTransactions + 1
 
-	if countOfNotSoldTransactions > maxCountOfNotSoldTransactions {
-		maxCountOfNotSoldTransactions = countOfNotSoldTransactions
-		fmt.Printf("------------maxCountOfNotSoldTransactions=%v \n", maxCountOfNotSoldTransactions)
+	if api.countOfNotSoldTransactions > api.maxCountOfNotSoldTransactions {
+		api.maxCountOfNotSoldTransactions = api.countOfNotSoldTransactions
+		fmt.Printf("------------maxCountOfNotSoldTransactions=%v \n", api.maxCountOfNotSoldTransactions)
 	}
 
-	return &orderResponseMockDto{
-		price:  price,
-		amount: amount,
-	}, nil
+	return api.fill(coin, amount, price, true, false)
 }
 
 func (api *BybitApiMock) SellCoinByMarket(coin *domains.Coin, amount float64, price float64) (api.OrderResponseDto, error) {
-	countOfNotSoldTransactions = countOfNotSoldTransactions - 1
+	api.countOfNotSoldTransactions = api.countOfNotSoldTransactions - 1
 
-	return &orderResponseMockDto{
-		price:  price,
-		amount: amount,
-	}, nil
+	return api.fill(coin, amount, price, false, true)
 }
 
 func (api *BybitApiMock) GetWalletBalance() (api.WalletBalanceDto, error) {
@@ -124,6 +143,25 @@ func (api *BybitApiMock) SetApiKey(apiKey string) {
 func (api *BybitApiMock) SetSecretKey(secretKey string) {
 }
 
+// fill returns order filled by the fill model, closing orders are always filled completely
+func (api *BybitApiMock) fill(coin *domains.Coin, amount float64, price float64, isBuy bool, isClose bool) (api.OrderResponseDto, error) {
+	if api.fillModel == nil {
+		return &orderResponseMockDto{
+			price:  price,
+			amount: amount,
+		}, nil
+	}
+
+	filledPrice, filledAmount, err := api.fillModel.Fill(coin, amount, price, isBuy, isClose)
+	if err != nil {
+		return nil, err
+	}
+	return &orderResponseMockDto{
+		price:  filledPrice,
+		amount: filledAmount,
+	}, nil
+}
+
 type orderResponseMockDto struct {
 	price  float64
 	amount float64
@@ -0,0 +1,232 @@
+package mock
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type SlippageMode string
+
+const (
+	SLIPPAGE_NONE          SlippageMode = "none"
+	SLIPPAGE_FIXED         SlippageMode = "fixed"
+	SLIPPAGE_VOLATILITY    SlippageMode = "volatility"
+	SLIPPAGE_PARTICIPATION SlippageMode = "participation"
+
+	DEFAULT_FILL_INTERVAL    = "1"
+	DEFAULT_MAX_FILL_CANDLES = 60
+)
+
+// FillConfig describes how the backtest exchange fills market orders, zero value fills at the requested price
+type FillConfig struct {
+	/* none, fixed - SlippageBps, volatility - VolatilityFactor * range of the fill candle,
+	participation - ImpactBps * sqrt(share of the candle volume taken by the order) */
+	Slippage         SlippageMode
+	SlippageBps      float64
+	VolatilityFactor float64
+	ImpactBps        float64
+
+	/* Half of bid-ask spread paid by every order on top of slippage */
+	HalfSpreadBps float64
+
+	/* Order is filled at open of the candle DelayCandles candles after the order, 0 - at the requested price */
+	DelayCandles int
+
+	/* Share of candle volume the order can take, the rest of opening order is cancelled,
+	closing order is filled on the next candles. 0 - unlimited */
+	MaxParticipation float64
+	MaxFillCandles   int
+
+	/* Interval of fill candles */
+	Interval string
+}
+
+// NewFillConfig reads analyser.fill from config.yml
+func NewFillConfig() *FillConfig {
+	config := &FillConfig{
+		Slippage:         SlippageMode(viper.GetString("analyser.fill.slippage")),
+		SlippageBps:      viper.GetFloat64("analyser.fill.slippageBps"),
+		VolatilityFactor: viper.GetFloat64("analyser.fill.volatilityFactor"),
+		ImpactBps:        viper.GetFloat64("analyser.fill.impactBps"),
+		HalfSpreadBps:    viper.GetFloat64("analyser.fill.halfSpreadBps"),
+		DelayCandles:     viper.GetInt("analyser.fill.delayCandles"),
+		MaxParticipation: viper.GetFloat64("analyser.fill.maxParticipation"),
+		MaxFillCandles:   viper.GetInt("analyser.fill.maxFillCandles"),
+		Interval:         viper.GetString("analyser.fill.interval"),
+	}
+	if config.Slippage == "" {
+		config.Slippage = SLIPPAGE_NONE
+	}
+	if config.Interval == "" {
+		config.Interval = DEFAULT_FILL_INTERVAL
+	}
+	if config.MaxFillCandles == 0 {
+		config.MaxFillCandles = DEFAULT_MAX_FILL_CANDLES
+	}
+	return config
+}
+
+// IsExact is true when orders are filled at the requested price, so fill candles aren't needed
+func (c *FillConfig) IsExact() bool {
+	return (c.Slippage == "" || c.Slippage == SLIPPAGE_NONE) && c.HalfSpreadBps == 0 && c.DelayCandles == 0 && c.MaxParticipation == 0
+}
+
+func (c *FillConfig) Validate() error {
+	switch c.Slippage {
+	case "", SLIPPAGE_NONE, SLIPPAGE_FIXED, SLIPPAGE_VOLATILITY, SLIPPAGE_PARTICIPATION:
+	default:
+		return fmt.Errorf("Unknown slippage [%s], expected none, fixed, volatility or participation", c.Slippage)
+	}
+	if c.DelayCandles < 0 || c.MaxParticipation < 0 || c.MaxParticipation > 1 {
+		return fmt.Errorf("Invalid fill config: delayCandles %d, maxParticipation %v", c.DelayCandles, c.MaxParticipation)
+	}
+	return nil
+}
+
+func NewFillModel(config *FillConfig, klineRepo repository.Kline, clock date.Clock) *FillModel {
+	return &FillModel{
+		config:    config,
+		klineRepo: klineRepo,
+		clock:     clock,
+	}
+}
+
+// FillModel simulates market fills of the backtest exchange on fill candles following the order
+type FillModel struct {
+	config    *FillConfig
+	klineRepo repository.Kline
+	clock     date.Clock
+
+	missingCandlesOnce sync.Once
+}
+
+// Fill returns average price and filled amount of the market order. Opening order is filled on one candle only,
+// closing order (fillAll) takes next candles until the whole amount is filled, the rest is filled on the last candle
+func (m *FillModel) Fill(coin *domains.Coin, amount float64, price float64, isBuy bool, fillAll bool) (float64, float64, error) {
+	if m.config.IsExact() {
+		return price, amount, nil
+	}
+
+	candles := m.findCandles(coin)
+	if len(candles) == 0 {
+		// without candles only the spread and fixed slippage are known
+		return m.applyCosts(price, m.config.HalfSpreadBps+m.fixedBps(), isBuy), amount, nil
+	}
+	if !fillAll {
+		candles = candles[:1]
+	}
+
+	remaining := amount
+	cost := 0.0
+	for i, candle := range candles {
+		basePrice := candle.Open
+		if i == 0 && m.config.DelayCandles == 0 {
+			basePrice = price
+		}
+
+		take := remaining
+		if m.config.MaxParticipation > 0 && !(fillAll && i == len(candles)-1) {
+			take = math.Min(remaining, candle.Volume*m.config.MaxParticipation)
+		}
+		if take <= 0 {
+			continue
+		}
+
+		cost += take * m.applyCosts(basePrice, m.config.HalfSpreadBps+m.slippageBps(candle, take), isBuy)
+		remaining -= take
+		if remaining <= 0 {
+			break
+		}
+	}
+
+	filled := amount - remaining
+	if filled <= 0 {
+		return 0, 0, errors.New("Order isn't filled, fill candle has no volume")
+	}
+	return cost / filled, filled, nil
+}
+
+// findCandles returns fill candles starting DelayCandles after the current one sorted by open time
+func (m *FillModel) findCandles(coin *domains.Coin) []*domains.Kline {
+	if m.klineRepo == nil {
+		return nil
+	}
+
+	intervalDuration := time.Duration(parseInterval(m.config.Interval)) * time.Minute
+	openTime := m.clock.NowTime().Truncate(intervalDuration).Add(intervalDuration * time.Duration(m.config.DelayCandles))
+	candlesCount := 1
+	if m.config.MaxParticipation > 0 && m.config.MaxFillCandles > 1 {
+		candlesCount = m.config.MaxFillCandles
+	}
+	klines, err := m.klineRepo.FindAllByCoinIdAndIntervalAndCloseTimeInRange(coin.Id, m.config.Interval,
+		openTime, openTime.Add(intervalDuration*time.Duration(candlesCount)).Add(-time.Second))
+	if err != nil || len(klines) == 0 {
+		m.missingCandlesOnce.Do(func() {
+			zap.S().Warnf("Fill candles of %s with interval %s are not found at %v, orders are filled with spread and fixed slippage only",
+				coin.Symbol, m.config.Interval, openTime)
+		})
+		return nil
+	}
+
+	candles := make([]*domains.Kline, 0, len(klines))
+	for _, kline := range klines {
+		if !kline.OpenTime.Before(openTime) {
+			candles = append(candles, kline)
+		}
+	}
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].OpenTime.Before(candles[j].OpenTime)
+	})
+	return candles
+}
+
+func (m *FillModel) slippageBps(candle *domains.Kline, take float64) float64 {
+	switch m.config.Slippage {
+	case SLIPPAGE_FIXED:
+		return m.config.SlippageBps
+	case SLIPPAGE_VOLATILITY:
+		if candle.Open == 0 {
+			return 0
+		}
+		return m.config.VolatilityFactor * (candle.High - candle.Low) / candle.Open * 10000
+	case SLIPPAGE_PARTICIPATION:
+		if candle.Volume == 0 {
+			return m.config.ImpactBps
+		}
+		return m.config.ImpactBps * math.Sqrt(math.Min(take/candle.Volume, 1))
+	}
+	return 0
+}
+
+func (m *FillModel) fixedBps() float64 {
+	if m.config.Slippage == SLIPPAGE_FIXED {
+		return m.config.SlippageBps
+	}
+	return 0
+}
+
+// applyCosts makes the price worse for the order side
+func (m *FillModel) applyCosts(price float64, bps float64, isBuy bool) float64 {
+	if isBuy {
+		return price * (1 + bps/10000)
+	}
+	return price * (1 - bps/10000)
+}
+
+func parseInterval(interval string) int {
+	minutes, err := strconv.Atoi(interval)
+	if err != nil || minutes <= 0 {
+		return 1
+	}
+	return minutes
+}
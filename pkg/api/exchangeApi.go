@@ -11,6 +11,8 @@ type ExchangeApi interface {
 	GetCurrentCoinPrice(coin *domains.Coin) (float64, error)
 	GetKlines(coin *domains.Coin, interval string, limit int, fromTime time.Time) (KlinesDto, error)
 	GetKlinesFutures(coin *domains.Coin, interval string, limit int, fromTime time.Time) (KlinesDto, error)
+	/* Funding rates of perpetual contract settled in [startTime, endTime], at most 200 per request */
+	GetFundingRateHistory(coin *domains.Coin, startTime time.Time, endTime time.Time) ([]FundingRateDto, error)
 
 	BuyCoinByMarket(coin *domains.Coin, amount float64, price float64) (OrderResponseDto, error)
 	SellCoinByMarket(coin *domains.Coin, amount float64, price float64) (OrderResponseDto, error)
@@ -56,6 +58,11 @@ type KlineDto interface {
 	GetClose() float64
 }
 
+type FundingRateDto interface {
+	GetFundingTime() time.Time
+	GetFundingRate() float64
+}
+
 type WalletBalanceDto interface {
 	GetAvailableBalanceInCents() float64
 }
@@ -0,0 +1,12 @@
+package constants
+
+type BacktestRunStatus string
+
+const (
+	/* Strategy is executed, transactions of the run are being saved */
+	BACKTEST_RUN_RUNNING BacktestRunStatus = "running"
+	/* Results of the run are saved */
+	BACKTEST_RUN_FINISHED BacktestRunStatus = "finished"
+	/* Run was stopped by error, saved transactions may be incomplete */
+	BACKTEST_RUN_FAILED BacktestRunStatus = "failed"
+)
@@ -0,0 +1,19 @@
+package constants
+
+// CloseReason is saved on the closing transaction to analyse which exits work
+type CloseReason string
+
+const (
+	CLOSE_REASON_SIGNAL       CloseReason = "signal"
+	CLOSE_REASON_EXCHANGE     CloseReason = "exchange"
+	CLOSE_REASON_STOP_LOSS    CloseReason = "stopLoss"
+	CLOSE_REASON_TAKE_PROFIT  CloseReason = "takeProfit"
+	CLOSE_REASON_PROFIT       CloseReason = "profit"
+	CLOSE_REASON_MAX_LOSS     CloseReason = "maxLoss"
+	CLOSE_REASON_Z_SCORE      CloseReason = "zScore"
+	CLOSE_REASON_Z_SCORE_STOP CloseReason = "zScoreStop"
+	CLOSE_REASON_TIME_STOP    CloseReason = "timeStop"
+	CLOSE_REASON_LEG_CLOSED   CloseReason = "legClosed"
+	CLOSE_REASON_RANGE_EXIT   CloseReason = "rangeExit"
+	CLOSE_REASON_FUNDING      CloseReason = "funding"
+)
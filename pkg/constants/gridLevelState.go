@@ -0,0 +1,12 @@
+package constants
+
+type GridLevelState string
+
+const (
+	/* Waiting for the price to reach open price of the level */
+	GRID_LEVEL_ARMED GridLevelState = "armed"
+	/* Position of the level is opened, waiting for close price */
+	GRID_LEVEL_FILLED GridLevelState = "filled"
+	/* Price left the range of the grid, level isn't traded anymore */
+	GRID_LEVEL_STOPPED GridLevelState = "stopped"
+)
@@ -0,0 +1,22 @@
+package constants
+
+// SignalType is decision of the strategy, it's executed or only recorded depending on SignalMode
+type SignalType string
+
+const (
+	SIGNAL_OPEN_LONG  SignalType = "openLong"
+	SIGNAL_OPEN_SHORT SignalType = "openShort"
+	SIGNAL_CLOSE      SignalType = "close"
+)
+
+// SignalMode routes signals of the strategy
+type SignalMode string
+
+const (
+	/* Orders are placed, nothing is recorded */
+	SIGNAL_MODE_EXECUTE SignalMode = "execute"
+	/* Signals are saved in signal table and sent to Telegram, no orders are placed */
+	SIGNAL_MODE_RECORD SignalMode = "signal"
+	/* Orders are placed and signals are recorded */
+	SIGNAL_MODE_BOTH SignalMode = "both"
+)
@@ -10,4 +10,9 @@ const (
 	SESSION_SCALPER
 	SMA_VOLUME_SCALPER
 	PAIR_ARBITRAGE
+	RULE_BASED
+	GRID
+	FUNDING_BASIS
+	MEAN_REVERSION
+	BREAKOUT
 )
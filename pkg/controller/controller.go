@@ -2,6 +2,8 @@ package controller
 
 import (
 	telegramDto "cryptoBot/pkg/data/dto/telegram"
+	"cryptoBot/pkg/service/portfolio"
+	"cryptoBot/pkg/service/state"
 	"cryptoBot/pkg/service/telegram"
 	"encoding/json"
 	"github.com/go-chi/chi"
@@ -38,6 +40,40 @@ func InitTelegramWebhookEndpoints(r *chi.Mux, telegramService telegram.ITelegram
 	})
 }
 
+// InitStrategyStateEndpoints returns saved state of strategies: /strategy/state?prefix=smaVolumeScalper
+func InitStrategyStateEndpoints(r *chi.Mux, strategyStateService *state.StrategyStateService) {
+	r.Get("/strategy/state", func(res http.ResponseWriter, req *http.Request) {
+		states, err := strategyStateService.FindAll(req.URL.Query().Get("prefix"))
+		if err != nil {
+			zap.S().Errorf("Error during loading strategy state: %s", err.Error())
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		res.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(res).Encode(states); err != nil {
+			zap.S().Errorf("Error during encoding strategy state: %s", err.Error())
+		}
+	})
+}
+
+// InitPortfolioEndpoints returns capital allocated to strategy instances: /portfolio/allocations
+func InitPortfolioEndpoints(r *chi.Mux, capitalAllocator *portfolio.CapitalAllocatorService) {
+	r.Get("/portfolio/allocations", func(res http.ResponseWriter, req *http.Request) {
+		allocations, err := capitalAllocator.Allocations()
+		if err != nil {
+			zap.S().Errorf("Error during loading allocations: %s", err.Error())
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		res.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(res).Encode(allocations); err != nil {
+			zap.S().Errorf("Error during encoding allocations: %s", err.Error())
+		}
+	})
+}
+
 func parseTelegramRequest(r *http.Request) (*telegramDto.Update, error) {
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
@@ -5,6 +5,12 @@ import (
 )
 
 func InitCronJobs(tradingService trading.TradingService) {
-	tradingJob := newTradingJob(tradingService)
+	tradingJob := newTradingJob(tradingService, "1 0 * * * *") // every hour at 0 min 1 sec
+	tradingJob.initTradingJob()
+}
+
+// InitMinuteCronJobs runs the trading service every minute, it decides itself which of its instances are due
+func InitMinuteCronJobs(tradingService trading.TradingService) {
+	tradingJob := newTradingJob(tradingService, "1 * * * * *") // every minute at 1 sec
 	tradingJob.initTradingJob()
 }
@@ -8,15 +8,17 @@ import (
 
 type tradingJob struct {
 	tradingService trading.TradingService
+	/* Cron expression with seconds */
+	spec string
 }
 
-func newTradingJob(tradingService trading.TradingService) *tradingJob {
-	return &tradingJob{tradingService: tradingService}
+func newTradingJob(tradingService trading.TradingService, spec string) *tradingJob {
+	return &tradingJob{tradingService: tradingService, spec: spec}
 }
 
 func (j *tradingJob) initTradingJob() {
 	s := gocron.NewScheduler(time.UTC)
-	s.CronWithSeconds("1 0 * * * *").Do(j.execute) // every hour at 0 min 1 sec
+	s.CronWithSeconds(j.spec).Do(j.execute)
 	s.SingletonModeAll()
 	s.StartAsync()
 }
@@ -0,0 +1,51 @@
+package domains
+
+import (
+	"cryptoBot/pkg/constants"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// BacktestRun is one backtest of the strategy, transactions of the run are saved with its id in run_id
+type BacktestRun struct {
+	Id int64
+
+	/* Key of the strategy instance, e.g. breakout:btcTurtle */
+	Name            string
+	Strategy        string
+	TradingStrategy constants.TradingStrategy `db:"trading_strategy"`
+	/* Comma separated symbols */
+	Coins    string
+	Interval int
+	/* Json of params overriding defaults of the strategy */
+	Params string
+
+	FromTime time.Time `db:"from_time"`
+	ToTime   time.Time `db:"to_time"`
+
+	/* Revision of the code the run was made with, empty when git isn't available */
+	GitRevision    string  `db:"git_revision"`
+	InitialCapital float64 `db:"initial_capital"`
+
+	Status constants.BacktestRunStatus
+	Error  sql.NullString
+
+	NetPnl             float64 `db:"net_pnl"`
+	ReturnPercent      float64 `db:"return_percent"`
+	Sharpe             float64
+	MaxDrawdownPercent float64 `db:"max_drawdown_percent"`
+	Trades             int
+	WinRate            float64 `db:"win_rate"`
+	ProfitFactor       float64 `db:"profit_factor"`
+	/* Json of the whole backtest report */
+	Report sql.NullString
+
+	CreatedAt  time.Time    `db:"created_at"`
+	FinishedAt sql.NullTime `db:"finished_at"`
+}
+
+func (d *BacktestRun) String() string {
+	return fmt.Sprintf("BacktestRun {id: %v, name: %v, status: %v, from: %v, to: %v, netPnl: %.2f}",
+		d.Id, d.Name, d.Status, d.FromTime, d.ToTime, d.NetPnl)
+}
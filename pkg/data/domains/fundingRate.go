@@ -0,0 +1,33 @@
+package domains
+
+import (
+	"fmt"
+	"time"
+)
+
+// FundingRate is settled funding of perpetual contract, positive rate is paid by longs to shorts
+type FundingRate struct {
+	Id          int64
+	CoinId      int64     `db:"coin_id"`
+	FundingTime time.Time `db:"funding_time"`
+	Rate        float64
+}
+
+func (d *FundingRate) String() string {
+	return fmt.Sprintf("FundingRate {coinId: %v, fundingTime: %v, rate: %v}", d.CoinId, d.FundingTime, d.Rate)
+}
+
+// FundingPayment is funding received (positive) or paid (negative) by the opened futures transaction
+type FundingPayment struct {
+	Id            int64
+	TransactionId int64     `db:"transaction_id"`
+	CoinId        int64     `db:"coin_id"`
+	FundingTime   time.Time `db:"funding_time"`
+	Rate          float64
+	/* In cents as transaction profit */
+	Income int64
+}
+
+func (d *FundingPayment) String() string {
+	return fmt.Sprintf("FundingPayment {transactionId: %v, fundingTime: %v, rate: %v, income: %v}", d.TransactionId, d.FundingTime, d.Rate, d.Income)
+}
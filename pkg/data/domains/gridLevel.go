@@ -0,0 +1,37 @@
+package domains
+
+import (
+	"cryptoBot/pkg/constants"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GridLevel is one level of the grid strategy, filled level is linked to the open transaction,
+// the close transaction is linked to the open one by related_transaction_id
+type GridLevel struct {
+	Id int64
+
+	GridName   string `db:"grid_name"`
+	CoinId     int64  `db:"coin_id"`
+	LevelIndex int    `db:"level_index"`
+
+	/* Buy price and sell price of the level for long grid, the opposite for short grid */
+	OpenPrice  float64 `db:"open_price"`
+	ClosePrice float64 `db:"close_price"`
+
+	/* Amount of coin per level */
+	Quantity float64
+
+	State constants.GridLevelState
+
+	/* Open transaction while the level is filled */
+	TransactionId sql.NullInt64 `db:"transaction_id"`
+
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (d *GridLevel) String() string {
+	return fmt.Sprintf("GridLevel {grid: %v, index: %v, open: %v, close: %v, quantity: %v, state: %v, transactionId: %v}",
+		d.GridName, d.LevelIndex, d.OpenPrice, d.ClosePrice, d.Quantity, d.State, d.TransactionId.Int64)
+}
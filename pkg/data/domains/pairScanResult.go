@@ -0,0 +1,47 @@
+package domains
+
+import (
+	"fmt"
+	"time"
+)
+
+// PairScanResult is a candidate pair for pair arbitrage found by cmd/scanner
+type PairScanResult struct {
+	Id        int64
+	ScannedAt time.Time `db:"scanned_at"`
+	CoinId1   int64     `db:"coin_id_1"`
+	CoinId2   int64     `db:"coin_id_2"`
+	Interval  string    `db:"duration"`
+	FromTime  time.Time `db:"from_time"`
+	ToTime    time.Time `db:"to_time"`
+
+	/* Klines in one rolling window */
+	WindowSize int `db:"window_size"`
+
+	/* Average correlation of windows */
+	Correlation float64 `db:"correlation"`
+	/* Hedge ratio and ADF statistic of the whole period */
+	HedgeRatio   float64 `db:"hedge_ratio"`
+	AdfStatistic float64 `db:"adf_statistic"`
+	/* Median half-life of the spread of windows in klines */
+	HalfLife float64 `db:"half_life"`
+
+	/* Windows passing correlation, cointegration and half-life tests */
+	CointegratedWindows int `db:"cointegrated_windows"`
+	TotalWindows        int `db:"total_windows"`
+
+	Score float64
+	Rank  int
+}
+
+func (d *PairScanResult) GetCointegratedWindowsRatio() float64 {
+	if d.TotalWindows == 0 {
+		return 0
+	}
+	return float64(d.CointegratedWindows) / float64(d.TotalWindows)
+}
+
+func (d *PairScanResult) String() string {
+	return fmt.Sprintf("PairScanResult {rank: %v, coinId1: %v, coinId2: %v, correlation: %.3f, adf: %.3f, halfLife: %.1f, windows: %v/%v, score: %.3f}",
+		d.Rank, d.CoinId1, d.CoinId2, d.Correlation, d.AdfStatistic, d.HalfLife, d.CointegratedWindows, d.TotalWindows, d.Score)
+}
@@ -0,0 +1,37 @@
+package domains
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/constants/futureType"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type Signal struct {
+	Id              int64
+	TradingStrategy constants.TradingStrategy `db:"trading_strategy"`
+	TradingKey      string                    `db:"trading_key"`
+	CoinId          int64                     `db:"coin_id"`
+	SignalType      constants.SignalType      `db:"signal_type"`
+	FuturesType     futureType.FuturesType    `db:"futures_type"`
+	/* Close reason or entry rule */
+	Reason sql.NullString
+	/* Price at the moment of the signal */
+	Price           float64
+	Cost            float64
+	StopLossPrice   sql.NullFloat64 `db:"stop_loss_price"`
+	TakeProfitPrice sql.NullFloat64 `db:"take_profit_price"`
+	/* JSON object of indicator values the decision was made on */
+	Indicators sql.NullString
+	/* Close signal is linked to the open one */
+	RelatedSignalId sql.NullInt64 `db:"related_signal_id"`
+	/* Transaction of the executed signal */
+	TransactionId sql.NullInt64 `db:"transaction_id"`
+	CreatedAt     time.Time     `db:"created_at"`
+}
+
+func (d *Signal) String() string {
+	return fmt.Sprintf("Signal {type: %s, tradingKey: %s, coinId: %v, price: %v, reason: %s, createdAt: %v}",
+		d.SignalType, d.TradingKey, d.CoinId, d.Price, d.Reason.String, d.CreatedAt)
+}
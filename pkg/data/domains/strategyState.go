@@ -0,0 +1,21 @@
+package domains
+
+import (
+	"fmt"
+	"time"
+)
+
+// StrategyState is one value of in-memory state of the strategy instance, it's restored after restart
+type StrategyState struct {
+	Id          int64
+	StrategyKey string `db:"strategy_key"`
+	StateKey    string `db:"state_key"`
+	Value       string
+	/* Incremented on every change, update of stale version is rejected */
+	Version   int
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (d *StrategyState) String() string {
+	return fmt.Sprintf("StrategyState {strategyKey: %s, stateKey: %s, value: %s, version: %d}", d.StrategyKey, d.StateKey, d.Value, d.Version)
+}
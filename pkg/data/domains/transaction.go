@@ -53,6 +53,12 @@ type Transaction struct {
 	IsFake bool `db:"fake"`
 
 	TradingKey string `db:"trading_key"`
+
+	/* Why the position was closed, set on closing transaction */
+	CloseReason sql.NullString `db:"close_reason"`
+
+	/* Backtest run the transaction was saved by, null for live trading */
+	RunId sql.NullInt64 `db:"run_id"`
 }
 
 func (t *Transaction) String() string {
@@ -0,0 +1,42 @@
+package bybit
+
+import (
+	"cryptoBot/pkg/api"
+	"github.com/shopspring/decimal"
+	"strconv"
+	"time"
+)
+
+type FundingRateHistoryDto struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		Category string            `json:"category"`
+		List     []*FundingRateDto `json:"list"`
+	} `json:"result"`
+	Time int64 `json:"time"`
+}
+
+type FundingRateDto struct {
+	Symbol               string `json:"symbol"`
+	FundingRate          string `json:"fundingRate"`
+	FundingRateTimestamp string `json:"fundingRateTimestamp"`
+}
+
+func (d *FundingRateHistoryDto) GetFundingRates() []api.FundingRateDto {
+	result := make([]api.FundingRateDto, 0, len(d.Result.List))
+	for _, dto := range d.Result.List {
+		result = append(result, dto)
+	}
+	return result
+}
+
+func (d *FundingRateDto) GetFundingTime() time.Time {
+	millis, _ := strconv.ParseInt(d.FundingRateTimestamp, 10, 64)
+	return time.UnixMilli(millis).UTC()
+}
+
+func (d *FundingRateDto) GetFundingRate() float64 {
+	rate, _ := decimal.NewFromString(d.FundingRate)
+	return rate.InexactFloat64()
+}
@@ -12,6 +12,7 @@ import (
 type Coin interface {
 	FindBySymbol(symbol string) (*domains.Coin, error)
 	FindById(id int64) (*domains.Coin, error)
+	FindAll() ([]*domains.Coin, error)
 }
 
 type Transaction interface {
@@ -24,6 +25,7 @@ type Transaction interface {
 	CalculateSumOfProfit(tradingStrategy constants.TradingStrategy) (int64, error)
 	CalculateSumOfProfitByCoin(coinId int64, tradingStrategy constants.TradingStrategy) (int64, error)
 	CalculateSumOfProfitByCoinAndTradingKey(coinId int64, tradingStrategy constants.TradingStrategy, tradingKey string) (int64, error)
+	CalculateSumOfProfitByTradingKeyPrefix(tradingStrategy constants.TradingStrategy, tradingKeyPrefix string) (int64, error)
 	CalculateSumOfSpentTransactions(tradingStrategy constants.TradingStrategy) (int64, error)
 	CalculateSumOfSpentTransactionsAndCreatedAfter(date time.Time, tradingStrategy constants.TradingStrategy) (int64, error)
 	CalculateSumOfProfitByDate(date time.Time, tradingStrategy constants.TradingStrategy) (int64, error)
@@ -33,10 +35,12 @@ type Transaction interface {
 
 	FindOpenedTransaction(tradingStrategy constants.TradingStrategy) (*domains.Transaction, error)
 	FindAllOpenedTransactions(tradingStrategy constants.TradingStrategy) ([]*domains.Transaction, error)
+	FindAllByTradingStrategyAndCreatedAtInRange(tradingStrategy constants.TradingStrategy, from time.Time, to time.Time) ([]*domains.Transaction, error)
 	FindOpenedTransactionByCoin(tradingStrategy constants.TradingStrategy, coinId int64) (*domains.Transaction, error)
 	FindOpenedTransactionByCoinAndTradingKey(tradingStrategy constants.TradingStrategy, coinId int64, tradingKey string) (*domains.Transaction, error)
 
 	FindAllProfitPercents(tradingStrategy int) ([]transaction.TransactionProfitPercentsDto, error)
+	FindAllRealProfitPercents(tradingStrategy int) ([]transaction.TransactionProfitPercentsDto, error)
 	FetchStatisticByDays(tradingStrategy int, coinIds []int64) ([]transaction.PairTransactionProfitPercentsDto, error)
 	FindAllCoinIds(tradingStrategy int) ([]int64, error)
 }
@@ -65,6 +69,46 @@ type SyntheticKline interface {
 	RefreshView() error
 }
 
+type PairScanResult interface {
+	SavePairScanResult(domain *domains.PairScanResult) error
+	FindAllByLastScan(limit int) ([]*domains.PairScanResult, error)
+}
+
+type GridLevel interface {
+	FindAllByGridName(gridName string) ([]*domains.GridLevel, error)
+	SaveGridLevel(level *domains.GridLevel) error
+	DeleteAllByGridName(gridName string) error
+}
+
+type FundingRate interface {
+	SaveFundingRate(domain *domains.FundingRate) error
+	FindLastByCoinIdAndFundingTimeLessOrEqual(coinId int64, fundingTime time.Time) (*domains.FundingRate, error)
+	FindAllByCoinIdAndFundingTimeInRange(coinId int64, from time.Time, to time.Time) ([]*domains.FundingRate, error)
+	SaveFundingPayment(domain *domains.FundingPayment) error
+	FindLastPaymentTimeByTransactionId(transactionId int64) (*time.Time, error)
+	CalculateSumOfFundingIncome(tradingStrategy int, tradingKeyPrefix string) (int64, error)
+}
+
+type Signal interface {
+	SaveSignal(domain *domains.Signal) error
+	FindOpenedSignalByCoinAndTradingKey(tradingStrategy constants.TradingStrategy, coinId int64, tradingKey string) (*domains.Signal, error)
+	FindAllOpenedSignalsByCoinAndTradingKey(tradingStrategy constants.TradingStrategy, coinId int64, tradingKey string) ([]*domains.Signal, error)
+	FindAllByTradingStrategy(tradingStrategy constants.TradingStrategy, limit int) ([]*domains.Signal, error)
+}
+
+type StrategyState interface {
+	FindAllByStrategyKey(strategyKey string) ([]*domains.StrategyState, error)
+	FindAllByStrategyKeyPrefix(strategyKeyPrefix string) ([]*domains.StrategyState, error)
+	SaveStrategyState(domain *domains.StrategyState) error
+}
+
+type BacktestRun interface {
+	FindById(id int64) (*domains.BacktestRun, error)
+	FindAll(limit int) ([]*domains.BacktestRun, error)
+	SaveBacktestRun(domain *domains.BacktestRun) error
+	DeleteById(id int64) error
+}
+
 type Repository struct {
 	Coin             Coin
 	Transaction      Transaction
@@ -72,6 +116,12 @@ type Repository struct {
 	Kline            Kline
 	ConditionalOrder ConditionalOrder
 	SyntheticKline   SyntheticKline
+	PairScanResult   PairScanResult
+	GridLevel        GridLevel
+	FundingRate      FundingRate
+	Signal           Signal
+	StrategyState    StrategyState
+	BacktestRun      BacktestRun
 }
 
 func NewRepositories(postgresDb *sqlx.DB) *Repository {
@@ -82,5 +132,19 @@ func NewRepositories(postgresDb *sqlx.DB) *Repository {
 		Kline:            postgres.NewKline(postgresDb),
 		ConditionalOrder: postgres.NewConditionalOrder(postgresDb),
 		SyntheticKline:   postgres.NewSyntheticKline(postgresDb),
+		PairScanResult:   postgres.NewPairScanResult(postgresDb),
+		GridLevel:        postgres.NewGridLevel(postgresDb),
+		FundingRate:      postgres.NewFundingRate(postgresDb),
+		Signal:           postgres.NewSignal(postgresDb),
+		StrategyState:    postgres.NewStrategyState(postgresDb),
+		BacktestRun:      postgres.NewBacktestRun(postgresDb),
 	}
 }
+
+// NewRunRepositories saves transactions with the backtest run id and finds only transactions of the run
+func NewRunRepositories(postgresDb *sqlx.DB, runId int64) *Repository {
+	repos := NewRepositories(postgresDb)
+	repos.Transaction = postgres.NewRunTransaction(postgresDb, runId)
+	repos.FundingRate = postgres.NewRunFundingRate(postgresDb, runId)
+	return repos
+}
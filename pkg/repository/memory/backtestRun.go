@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"sort"
+	"sync"
+)
+
+func NewBacktestRun() *BacktestRun {
+	return &BacktestRun{runs: make(map[int64]*domains.BacktestRun)}
+}
+
+// BacktestRun keeps runs of the process, transactions of a run live in its own transaction repository
+type BacktestRun struct {
+	mutex  sync.RWMutex
+	lastId int64
+	runs   map[int64]*domains.BacktestRun
+}
+
+func (r *BacktestRun) FindById(id int64) (*domains.BacktestRun, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if run, ok := r.runs[id]; ok {
+		copied := *run
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+// FindAll returns the latest runs first
+func (r *BacktestRun) FindAll(limit int) ([]*domains.BacktestRun, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]*domains.BacktestRun, 0, len(r.runs))
+	for _, run := range r.runs {
+		copied := *run
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id > result[j].Id
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// SaveBacktestRun updates the same columns as postgres repository does
+func (r *BacktestRun) SaveBacktestRun(domain *domains.BacktestRun) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if domain.Id == 0 {
+		r.lastId++
+		domain.Id = r.lastId
+		stored := *domain
+		r.runs[domain.Id] = &stored
+		return nil
+	}
+
+	if stored, ok := r.runs[domain.Id]; ok {
+		stored.Status = domain.Status
+		stored.Error = domain.Error
+		stored.NetPnl = domain.NetPnl
+		stored.ReturnPercent = domain.ReturnPercent
+		stored.Sharpe = domain.Sharpe
+		stored.MaxDrawdownPercent = domain.MaxDrawdownPercent
+		stored.Trades = domain.Trades
+		stored.WinRate = domain.WinRate
+		stored.ProfitFactor = domain.ProfitFactor
+		stored.Report = domain.Report
+		stored.FinishedAt = domain.FinishedAt
+	}
+	return nil
+}
+
+func (r *BacktestRun) DeleteById(id int64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.runs, id)
+	return nil
+}
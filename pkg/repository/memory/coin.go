@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"sort"
+	"sync"
+)
+
+func NewCoin() *Coin {
+	return &Coin{coins: make(map[int64]*domains.Coin)}
+}
+
+type Coin struct {
+	mutex  sync.RWMutex
+	lastId int64
+	coins  map[int64]*domains.Coin
+}
+
+func (r *Coin) FindBySymbol(symbol string) (*domains.Coin, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, coin := range r.coins {
+		if coin.Symbol == symbol {
+			result := *coin
+			return &result, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *Coin) FindById(id int64) (*domains.Coin, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	coin, ok := r.coins[id]
+	if !ok {
+		return nil, nil
+	}
+	result := *coin
+	return &result, nil
+}
+
+func (r *Coin) FindAll() ([]*domains.Coin, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]*domains.Coin, 0, len(r.coins))
+	for _, coin := range r.coins {
+		copied := *coin
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+	return result, nil
+}
+
+// SaveCoin keeps id of the coin loaded from postgres, new coin gets the next id
+func (r *Coin) SaveCoin(domain *domains.Coin) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if domain.Id == 0 {
+		domain.Id = r.lastId + 1
+	}
+	if domain.Id > r.lastId {
+		r.lastId = domain.Id
+	}
+	stored := *domain
+	r.coins[domain.Id] = &stored
+	return nil
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"fmt"
+	"sync"
+)
+
+func NewConditionalOrder() *ConditionalOrder {
+	return &ConditionalOrder{orders: make(map[int64]*domains.ConditionalOrder)}
+}
+
+type ConditionalOrder struct {
+	mutex  sync.RWMutex
+	lastId int64
+	orders map[int64]*domains.ConditionalOrder
+}
+
+func (r *ConditionalOrder) FindByTransaction(transaction *domains.Transaction) (*domains.ConditionalOrder, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, order := range r.orders {
+		if order.RelatedTransactionId.Valid && order.RelatedTransactionId.Int64 == transaction.Id {
+			result := *order
+			return &result, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *ConditionalOrder) SaveConditionalOrder(order *domains.ConditionalOrder) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if order.Id == 0 {
+		r.lastId++
+		order.Id = r.lastId
+	} else if _, ok := r.orders[order.Id]; !ok {
+		return fmt.Errorf("Unexpected updated rows count: %d", 0)
+	}
+	stored := *order
+	r.orders[order.Id] = &stored
+	return nil
+}
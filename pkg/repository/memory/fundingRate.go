@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/data/domains"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewFundingRate needs transactions of the same repositories to sum funding income by trading key
+func NewFundingRate(transactionRepo *Transaction) *FundingRate {
+	return &FundingRate{transactionRepo: transactionRepo}
+}
+
+type FundingRate struct {
+	transactionRepo *Transaction
+
+	mutex         sync.RWMutex
+	lastId        int64
+	rates         []*domains.FundingRate
+	lastPaymentId int64
+	payments      []*domains.FundingPayment
+}
+
+// SaveFundingRate ignores already saved rate
+func (r *FundingRate) SaveFundingRate(domain *domains.FundingRate) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, rate := range r.rates {
+		if rate.CoinId == domain.CoinId && rate.FundingTime.Equal(domain.FundingTime) {
+			return nil
+		}
+	}
+	r.lastId++
+	stored := *domain
+	stored.Id = r.lastId
+	r.rates = append(r.rates, &stored)
+	sort.SliceStable(r.rates, func(i, j int) bool {
+		return r.rates[i].FundingTime.Before(r.rates[j].FundingTime)
+	})
+	return nil
+}
+
+// LoadFundingRates adds rates loaded from postgres at once, they must be unique by coin and funding time
+func (r *FundingRate) LoadFundingRates(rates []*domains.FundingRate) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, rate := range rates {
+		r.lastId++
+		stored := *rate
+		stored.Id = r.lastId
+		r.rates = append(r.rates, &stored)
+	}
+	sort.SliceStable(r.rates, func(i, j int) bool {
+		return r.rates[i].FundingTime.Before(r.rates[j].FundingTime)
+	})
+}
+
+func (r *FundingRate) FindLastByCoinIdAndFundingTimeLessOrEqual(coinId int64, fundingTime time.Time) (*domains.FundingRate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for i := len(r.rates) - 1; i >= 0; i-- {
+		if r.rates[i].CoinId == coinId && !r.rates[i].FundingTime.After(fundingTime) {
+			result := *r.rates[i]
+			return &result, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *FundingRate) FindAllByCoinIdAndFundingTimeInRange(coinId int64, from time.Time, to time.Time) ([]*domains.FundingRate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]*domains.FundingRate, 0)
+	for _, rate := range r.rates {
+		if rate.CoinId == coinId && rate.FundingTime.After(from) && !rate.FundingTime.After(to) {
+			copied := *rate
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (r *FundingRate) SaveFundingPayment(domain *domains.FundingPayment) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.lastPaymentId++
+	domain.Id = r.lastPaymentId
+	stored := *domain
+	r.payments = append(r.payments, &stored)
+	return nil
+}
+
+func (r *FundingRate) FindLastPaymentTimeByTransactionId(transactionId int64) (*time.Time, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var last *time.Time
+	for _, payment := range r.payments {
+		if payment.TransactionId == transactionId && (last == nil || payment.FundingTime.After(*last)) {
+			fundingTime := payment.FundingTime
+			last = &fundingTime
+		}
+	}
+	return last, nil
+}
+
+// CalculateSumOfFundingIncome returns income in cents of all transactions of the trading strategy with the trading key prefix
+func (r *FundingRate) CalculateSumOfFundingIncome(tradingStrategy int, tradingKeyPrefix string) (int64, error) {
+	r.mutex.RLock()
+	payments := make([]domains.FundingPayment, 0, len(r.payments))
+	for _, payment := range r.payments {
+		payments = append(payments, *payment)
+	}
+	r.mutex.RUnlock()
+
+	income := int64(0)
+	for _, payment := range payments {
+		t, _ := r.transactionRepo.FindById(payment.TransactionId)
+		if t != nil && t.TradingStrategy == constants.TradingStrategy(tradingStrategy) && strings.HasPrefix(t.TradingKey, tradingKeyPrefix) {
+			income += payment.Income
+		}
+	}
+	return income, nil
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"sort"
+	"sync"
+)
+
+func NewGridLevel() *GridLevel {
+	return &GridLevel{levels: make(map[int64]*domains.GridLevel)}
+}
+
+type GridLevel struct {
+	mutex  sync.RWMutex
+	lastId int64
+	levels map[int64]*domains.GridLevel
+}
+
+func (r *GridLevel) FindAllByGridName(gridName string) ([]*domains.GridLevel, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]*domains.GridLevel, 0)
+	for _, level := range r.levels {
+		if level.GridName == gridName {
+			copied := *level
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].LevelIndex == result[j].LevelIndex {
+			return result[i].Id < result[j].Id
+		}
+		return result[i].LevelIndex < result[j].LevelIndex
+	})
+	return result, nil
+}
+
+// SaveGridLevel updates the same columns as postgres repository does
+func (r *GridLevel) SaveGridLevel(level *domains.GridLevel) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if level.Id == 0 {
+		r.lastId++
+		level.Id = r.lastId
+		stored := *level
+		r.levels[level.Id] = &stored
+		return nil
+	}
+
+	if stored, ok := r.levels[level.Id]; ok {
+		stored.OpenPrice = level.OpenPrice
+		stored.ClosePrice = level.ClosePrice
+		stored.Quantity = level.Quantity
+		stored.State = level.State
+		stored.TransactionId = level.TransactionId
+		stored.UpdatedAt = level.UpdatedAt
+	}
+	return nil
+}
+
+func (r *GridLevel) DeleteAllByGridName(gridName string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for id, level := range r.levels {
+		if level.GridName == gridName {
+			delete(r.levels, id)
+		}
+	}
+	return nil
+}
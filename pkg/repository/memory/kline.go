@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/util"
+	"sort"
+	"time"
+)
+
+func NewKline(store *KlineStore) *Kline {
+	return &Kline{store: store}
+}
+
+type Kline struct {
+	store *KlineStore
+}
+
+func (r *Kline) find(coinId int64, interval string, filter func(kline *domains.Kline) bool) (*domains.Kline, error) {
+	series, err := r.store.getSeries(coinId, interval)
+	if err != nil {
+		return nil, err
+	}
+	for _, kline := range series {
+		if filter(kline) {
+			result := *kline
+			return &result, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *Kline) FindOpenedAtMoment(coinId int64, momentTime time.Time, interval string) (*domains.Kline, error) {
+	openTime := util.RoundToMinutes(momentTime)
+	return r.find(coinId, interval, func(kline *domains.Kline) bool {
+		return kline.OpenTime.Equal(openTime)
+	})
+}
+
+func (r *Kline) FindClosedAtMoment(coinId int64, momentTime time.Time, interval string) (*domains.Kline, error) {
+	closeTime := util.RoundToMinutesWithInterval(momentTime, interval)
+	return r.find(coinId, interval, func(kline *domains.Kline) bool {
+		return kline.CloseTime.Equal(closeTime)
+	})
+}
+
+func (r *Kline) FindLast(coinId int64, interval string) (*domains.Kline, error) {
+	series, err := r.store.getSeries(coinId, interval)
+	if err != nil || len(series) == 0 {
+		return nil, err
+	}
+	result := *series[len(series)-1]
+	return &result, nil
+}
+
+// FindAllByCoinIdAndIntervalAndCloseTimeLessOrderByOpenTimeWithLimit returns the last klines sorted by open time ascending
+func (r *Kline) FindAllByCoinIdAndIntervalAndCloseTimeLessOrderByOpenTimeWithLimit(
+	coinId int64, interval string, closeTime time.Time, limit int64) ([]*domains.Kline, error) {
+	series, err := r.store.getSeries(coinId, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	end := sort.Search(len(series), func(i int) bool {
+		return series[i].CloseTime.After(closeTime)
+	})
+	start := end - int(limit)
+	if start < 0 {
+		start = 0
+	}
+	return copyKlines(series[start:end], false), nil
+}
+
+// FindAllByCoinIdAndIntervalAndCloseTimeInRange returns klines sorted by open time descending as postgres repository does
+func (r *Kline) FindAllByCoinIdAndIntervalAndCloseTimeInRange(coinId int64, interval string, openTime time.Time, closeTime time.Time) ([]*domains.Kline, error) {
+	series, err := r.store.getSeries(coinId, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	start := sort.Search(len(series), func(i int) bool {
+		return !series[i].CloseTime.Before(openTime)
+	})
+	end := sort.Search(len(series), func(i int) bool {
+		return series[i].CloseTime.After(closeTime)
+	})
+	if end < start {
+		end = start
+	}
+	return copyKlines(series[start:end], true), nil
+}
+
+func (r *Kline) SaveKline(domain *domains.Kline) error {
+	return r.store.save(domain)
+}
+
+func copyKlines(klines []*domains.Kline, reversed bool) []*domains.Kline {
+	result := make([]*domains.Kline, len(klines))
+	for i, kline := range klines {
+		copied := *kline
+		if reversed {
+			result[len(klines)-1-i] = &copied
+		} else {
+			result[i] = &copied
+		}
+	}
+	return result
+}
@@ -0,0 +1,238 @@
+package memory
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KlineSource is a repository the klines are bulk loaded from, postgres.Kline satisfies it
+type KlineSource interface {
+	FindAllByCoinIdAndIntervalAndCloseTimeInRange(coinId int64, interval string, openTime time.Time, closeTime time.Time) ([]*domains.Kline, error)
+}
+
+var KLINES_CSV_HEADER = []string{"symbol", "interval", "open_time", "close_time", "open", "high", "low", "close", "volume"}
+
+type klineKey struct {
+	coinId   int64
+	interval string
+}
+
+// NewKlineStore creates klines shared by all in-memory repositories of backtests running in parallel.
+// Klines of the coin and interval are loaded from the source once on the first query, nil source means
+// only klines loaded from csv are available. Zero from and to load the whole history.
+func NewKlineStore(source KlineSource, from time.Time, to time.Time) *KlineStore {
+	if to.IsZero() {
+		to = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return &KlineStore{
+		source: source,
+		from:   from,
+		to:     to,
+		series: make(map[klineKey][]*domains.Kline),
+		loaded: make(map[klineKey]bool),
+	}
+}
+
+type KlineStore struct {
+	source KlineSource
+	/* Range of close time loaded from the source */
+	from time.Time
+	to   time.Time
+
+	mutex sync.RWMutex
+	/* Sorted by open time ascending */
+	series map[klineKey][]*domains.Kline
+	loaded map[klineKey]bool
+	lastId int64
+}
+
+// getSeries returns klines sorted by open time, callers must not change them
+func (s *KlineStore) getSeries(coinId int64, interval string) ([]*domains.Kline, error) {
+	key := klineKey{coinId: coinId, interval: interval}
+
+	s.mutex.RLock()
+	series, loaded := s.series[key], s.loaded[key]
+	s.mutex.RUnlock()
+	if loaded || s.source == nil {
+		return series, nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.loaded[key] {
+		return s.series[key], nil
+	}
+	klines, err := s.source.FindAllByCoinIdAndIntervalAndCloseTimeInRange(coinId, interval, s.from, s.to)
+	if err != nil {
+		return nil, fmt.Errorf("Error during loading klines of coin %d and interval %s: %s", coinId, interval, err.Error())
+	}
+	for _, kline := range klines {
+		s.insert(kline)
+	}
+	s.loaded[key] = true
+	return s.series[key], nil
+}
+
+// insert adds the kline keeping the series sorted, the lock must be held
+func (s *KlineStore) insert(kline *domains.Kline) {
+	key := klineKey{coinId: kline.CoinId, interval: kline.Interval}
+	if kline.Id > s.lastId {
+		s.lastId = kline.Id
+	}
+
+	series := s.series[key]
+	i := sort.Search(len(series), func(i int) bool {
+		return series[i].OpenTime.After(kline.OpenTime)
+	})
+	// copy on write, readers keep the previous slice
+	updated := make([]*domains.Kline, 0, len(series)+1)
+	updated = append(updated, series[:i]...)
+	updated = append(updated, kline)
+	s.series[key] = append(updated, series[i:]...)
+}
+
+func (s *KlineStore) save(domain *domains.Kline) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if domain.Id == 0 {
+		domain.Id = s.lastId + 1
+		stored := *domain
+		s.insert(&stored)
+		return nil
+	}
+
+	key := klineKey{coinId: domain.CoinId, interval: domain.Interval}
+	for i, stored := range s.series[key] {
+		if stored.Id != domain.Id {
+			continue
+		}
+		updated := *stored
+		updated.CloseTime = domain.CloseTime
+		updated.High = domain.High
+		updated.Low = domain.Low
+		updated.Close = domain.Close
+		updated.Volume = domain.Volume
+
+		series := make([]*domains.Kline, len(s.series[key]))
+		copy(series, s.series[key])
+		series[i] = &updated
+		s.series[key] = series
+		return nil
+	}
+	return fmt.Errorf("Unexpected updated rows count: %d", 0)
+}
+
+// LoadCsv reads klines with KLINES_CSV_HEADER, coins are found by symbol and created when they are missing.
+// Times are RFC3339, loaded coin and interval aren't queried from the source.
+func (s *KlineStore) LoadCsv(path string, coinRepo *Coin) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("Error during reading header of %s: %s", path, err.Error())
+	}
+	if strings.Join(header, ",") != strings.Join(KLINES_CSV_HEADER, ",") {
+		return fmt.Errorf("Invalid header of %s, expected %s", path, strings.Join(KLINES_CSV_HEADER, ","))
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Error during reading %s: %s", path, err.Error())
+		}
+
+		kline, err := parseKlineRecord(record, coinRepo)
+		if err != nil {
+			return fmt.Errorf("Invalid line %d of %s: %s", line, path, err.Error())
+		}
+		kline.Id = s.lastId + 1
+		s.insert(kline)
+		s.loaded[klineKey{coinId: kline.CoinId, interval: kline.Interval}] = true
+	}
+}
+
+func parseKlineRecord(record []string, coinRepo *Coin) (*domains.Kline, error) {
+	coin, _ := coinRepo.FindBySymbol(record[0])
+	if coin == nil {
+		coin = &domains.Coin{Name: record[0], Symbol: record[0]}
+		if err := coinRepo.SaveCoin(coin); err != nil {
+			return nil, err
+		}
+	}
+
+	openTime, err := time.Parse(time.RFC3339Nano, record[2])
+	if err != nil {
+		return nil, err
+	}
+	closeTime, err := time.Parse(time.RFC3339Nano, record[3])
+	if err != nil {
+		return nil, err
+	}
+	prices := make([]float64, 0, 5)
+	for _, value := range record[4:9] {
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+
+	return &domains.Kline{
+		CoinId:    coin.Id,
+		OpenTime:  openTime,
+		CloseTime: closeTime,
+		Interval:  record[1],
+		Open:      prices[0],
+		High:      prices[1],
+		Low:       prices[2],
+		Close:     prices[3],
+		Volume:    prices[4],
+	}, nil
+}
+
+// WriteCsv saves klines of the coin with KLINES_CSV_HEADER, the file is readable by LoadCsv
+func WriteCsv(writer io.Writer, coin *domains.Coin, klines []*domains.Kline) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(KLINES_CSV_HEADER); err != nil {
+		return err
+	}
+	for _, kline := range klines {
+		err := csvWriter.Write([]string{
+			coin.Symbol,
+			kline.Interval,
+			kline.OpenTime.UTC().Format(time.RFC3339Nano),
+			kline.CloseTime.UTC().Format(time.RFC3339Nano),
+			strconv.FormatFloat(kline.Open, 'f', -1, 64),
+			strconv.FormatFloat(kline.High, 'f', -1, 64),
+			strconv.FormatFloat(kline.Low, 'f', -1, 64),
+			strconv.FormatFloat(kline.Close, 'f', -1, 64),
+			strconv.FormatFloat(kline.Volume, 'f', -1, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
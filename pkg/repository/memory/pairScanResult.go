@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"sort"
+	"sync"
+)
+
+func NewPairScanResult() *PairScanResult {
+	return &PairScanResult{}
+}
+
+type PairScanResult struct {
+	mutex   sync.RWMutex
+	lastId  int64
+	results []*domains.PairScanResult
+}
+
+func (r *PairScanResult) SavePairScanResult(domain *domains.PairScanResult) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.lastId++
+	domain.Id = r.lastId
+	stored := *domain
+	r.results = append(r.results, &stored)
+	return nil
+}
+
+// FindAllByLastScan returns results of the latest scan ordered by rank, all results when limit is 0
+func (r *PairScanResult) FindAllByLastScan(limit int) ([]*domains.PairScanResult, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]*domains.PairScanResult, 0)
+	if len(r.results) == 0 {
+		return result, nil
+	}
+
+	lastScannedAt := r.results[0].ScannedAt
+	for _, scanResult := range r.results {
+		if scanResult.ScannedAt.After(lastScannedAt) {
+			lastScannedAt = scanResult.ScannedAt
+		}
+	}
+	for _, scanResult := range r.results {
+		if scanResult.ScannedAt.Equal(lastScannedAt) {
+			copied := *scanResult
+			result = append(result, &copied)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Rank < result[j].Rank
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
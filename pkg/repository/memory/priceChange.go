@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"fmt"
+	"sync"
+)
+
+func NewPriceChange() *PriceChange {
+	return &PriceChange{priceChanges: make(map[int64]*domains.PriceChange)}
+}
+
+type PriceChange struct {
+	mutex        sync.RWMutex
+	lastId       int64
+	priceChanges map[int64]*domains.PriceChange
+}
+
+func (r *PriceChange) FindByTransactionId(transactionId int64) (*domains.PriceChange, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, priceChange := range r.priceChanges {
+		if priceChange.TransactionId == transactionId {
+			result := *priceChange
+			return &result, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *PriceChange) SavePriceChange(domain *domains.PriceChange) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if domain.Id == 0 {
+		r.lastId++
+		domain.Id = r.lastId
+	} else if _, ok := r.priceChanges[domain.Id]; !ok {
+		return fmt.Errorf("Unexpected updated rows count: %d", 0)
+	}
+	stored := *domain
+	r.priceChanges[domain.Id] = &stored
+	return nil
+}
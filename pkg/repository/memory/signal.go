@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/data/domains"
+	"sort"
+	"sync"
+)
+
+func NewSignal() *Signal {
+	return &Signal{}
+}
+
+type Signal struct {
+	mutex   sync.RWMutex
+	lastId  int64
+	signals []*domains.Signal
+}
+
+func (r *Signal) SaveSignal(domain *domains.Signal) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.lastId++
+	domain.Id = r.lastId
+	stored := *domain
+	r.signals = append(r.signals, &stored)
+	return nil
+}
+
+// FindOpenedSignalByCoinAndTradingKey returns the last open signal without close signal
+func (r *Signal) FindOpenedSignalByCoinAndTradingKey(tradingStrategy constants.TradingStrategy, coinId int64, tradingKey string) (*domains.Signal, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	isClosed := make(map[int64]bool)
+	for _, signal := range r.signals {
+		if signal.RelatedSignalId.Valid {
+			isClosed[signal.RelatedSignalId.Int64] = true
+		}
+	}
+
+	var last *domains.Signal
+	for _, signal := range r.signals {
+		if signal.TradingStrategy == tradingStrategy && signal.CoinId == coinId && signal.TradingKey == tradingKey &&
+			signal.SignalType != constants.SIGNAL_CLOSE && !isClosed[signal.Id] &&
+			(last == nil || !signal.CreatedAt.Before(last.CreatedAt)) {
+			last = signal
+		}
+	}
+	if last == nil {
+		return nil, nil
+	}
+	result := *last
+	return &result, nil
+}
+
+// FindAllOpenedSignalsByCoinAndTradingKey returns open signals without close signal, the newest first
+func (r *Signal) FindAllOpenedSignalsByCoinAndTradingKey(tradingStrategy constants.TradingStrategy, coinId int64, tradingKey string) ([]*domains.Signal, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	isClosed := make(map[int64]bool)
+	for _, signal := range r.signals {
+		if signal.RelatedSignalId.Valid {
+			isClosed[signal.RelatedSignalId.Int64] = true
+		}
+	}
+
+	result := make([]*domains.Signal, 0)
+	for i := len(r.signals) - 1; i >= 0; i-- {
+		signal := r.signals[i]
+		if signal.TradingStrategy == tradingStrategy && signal.CoinId == coinId && signal.TradingKey == tradingKey &&
+			signal.SignalType != constants.SIGNAL_CLOSE && !isClosed[signal.Id] {
+			copied := *signal
+			result = append(result, &copied)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (r *Signal) FindAllByTradingStrategy(tradingStrategy constants.TradingStrategy, limit int) ([]*domains.Signal, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]*domains.Signal, 0)
+	for i := len(r.signals) - 1; i >= 0; i-- {
+		if r.signals[i].TradingStrategy == tradingStrategy {
+			copied := *r.signals[i]
+			result = append(result, &copied)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+func NewStrategyState() *StrategyState {
+	return &StrategyState{states: make(map[int64]*domains.StrategyState)}
+}
+
+type StrategyState struct {
+	mutex  sync.RWMutex
+	lastId int64
+	states map[int64]*domains.StrategyState
+}
+
+func (r *StrategyState) FindAllByStrategyKey(strategyKey string) ([]*domains.StrategyState, error) {
+	return r.findAll(func(state *domains.StrategyState) bool {
+		return state.StrategyKey == strategyKey
+	}), nil
+}
+
+func (r *StrategyState) FindAllByStrategyKeyPrefix(strategyKeyPrefix string) ([]*domains.StrategyState, error) {
+	return r.findAll(func(state *domains.StrategyState) bool {
+		return strings.HasPrefix(state.StrategyKey, strategyKeyPrefix)
+	}), nil
+}
+
+// findAll returns states sorted by strategy key and state key
+func (r *StrategyState) findAll(filter func(state *domains.StrategyState) bool) []*domains.StrategyState {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]*domains.StrategyState, 0)
+	for _, state := range r.states {
+		if filter(state) {
+			copied := *state
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].StrategyKey == result[j].StrategyKey {
+			return result[i].StateKey < result[j].StateKey
+		}
+		return result[i].StrategyKey < result[j].StrategyKey
+	})
+	return result
+}
+
+// SaveStrategyState inserts the first version or updates the value when saved version is Version-1
+func (r *StrategyState) SaveStrategyState(domain *domains.StrategyState) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if domain.Id == 0 {
+		for _, state := range r.states {
+			if state.StrategyKey == domain.StrategyKey && state.StateKey == domain.StateKey {
+				return fmt.Errorf("state %s already exists", domain.String())
+			}
+		}
+		r.lastId++
+		domain.Id = r.lastId
+		stored := *domain
+		r.states[domain.Id] = &stored
+		return nil
+	}
+
+	stored, ok := r.states[domain.Id]
+	if !ok || stored.Version != domain.Version-1 {
+		return fmt.Errorf("state %s is changed by another instance", domain.String())
+	}
+	stored.Value = domain.Value
+	stored.Version = domain.Version
+	stored.UpdatedAt = domain.UpdatedAt
+	return nil
+}
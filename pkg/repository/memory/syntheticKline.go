@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+func NewSyntheticKline(store *KlineStore) *SyntheticKline {
+	return &SyntheticKline{
+		store:  store,
+		series: make(map[syntheticKlineKey][]*domains.SyntheticKline),
+	}
+}
+
+type syntheticKlineKey struct {
+	coinId1  int64
+	coinId2  int64
+	interval string
+}
+
+// SyntheticKline joins klines of two coins of the store as synthetic_kline view does, joined series are cached until RefreshView
+type SyntheticKline struct {
+	store *KlineStore
+
+	mutex  sync.Mutex
+	series map[syntheticKlineKey][]*domains.SyntheticKline
+}
+
+// getSeries returns synthetic klines sorted by close time ascending
+func (r *SyntheticKline) getSeries(coinId1 int64, coinId2 int64, interval string) ([]*domains.SyntheticKline, error) {
+	key := syntheticKlineKey{coinId1: coinId1, coinId2: coinId2, interval: interval}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if series, ok := r.series[key]; ok {
+		return series, nil
+	}
+
+	klines1, err := r.store.getSeries(coinId1, interval)
+	if err != nil {
+		return nil, err
+	}
+	klines2, err := r.store.getSeries(coinId2, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]*domains.SyntheticKline, 0, len(klines1))
+	if coinId1 != coinId2 {
+		for i, j := 0, 0; i < len(klines1) && j < len(klines2); {
+			k1, k2 := klines1[i], klines2[j]
+			if k1.OpenTime.Before(k2.OpenTime) {
+				i++
+				continue
+			}
+			if k2.OpenTime.Before(k1.OpenTime) {
+				j++
+				continue
+			}
+			if k1.CloseTime.Equal(k2.CloseTime) {
+				series = append(series, &domains.SyntheticKline{
+					CoinId1:        coinId1,
+					CoinId2:        coinId2,
+					OpenTime:       k1.OpenTime,
+					CloseTime:      k1.CloseTime,
+					Interval:       interval,
+					Open1:          k1.Open,
+					Close1:         k1.Close,
+					Open2:          k2.Open,
+					Close2:         k2.Close,
+					SyntheticOpen:  k1.Open / k2.Open * 10000,
+					SyntheticClose: k1.Close / k2.Close * 10000,
+				})
+			}
+			i++
+			j++
+		}
+	}
+	sort.SliceStable(series, func(i, j int) bool {
+		return series[i].CloseTime.Before(series[j].CloseTime)
+	})
+
+	r.series[key] = series
+	return series, nil
+}
+
+// FindAllByCoinIdsAndIntervalAndCloseTimeInRange returns klines sorted by close time descending as postgres repository does
+func (r *SyntheticKline) FindAllByCoinIdsAndIntervalAndCloseTimeInRange(coinId1 int64, coinId2 int64, interval string, openTime time.Time, closeTime time.Time) ([]domains.IKline, error) {
+	series, err := r.getSeries(coinId1, coinId2, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	start := sort.Search(len(series), func(i int) bool {
+		return !series[i].CloseTime.Before(openTime)
+	})
+	end := sort.Search(len(series), func(i int) bool {
+		return series[i].CloseTime.After(closeTime)
+	})
+	if end < start {
+		end = start
+	}
+
+	result := make([]domains.IKline, 0, end-start)
+	for i := end - 1; i >= start; i-- {
+		copied := *series[i]
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+// FindAllByCoinIdAndIntervalAndCloseTimeLessOrderByOpenTimeWithLimit returns the last klines sorted by close time ascending,
+// klines older than limit+1 intervals are skipped as postgres repository does
+func (r *SyntheticKline) FindAllByCoinIdAndIntervalAndCloseTimeLessOrderByOpenTimeWithLimit(coinId1 int64, coinId2 int64, interval string, closeTime time.Time, limit int) ([]domains.IKline, error) {
+	series, err := r.getSeries(coinId1, coinId2, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	intervalInt, _ := strconv.Atoi(interval)
+	minCloseTime := closeTime.Add(time.Minute * time.Duration(-intervalInt*(limit+1)))
+
+	end := sort.Search(len(series), func(i int) bool {
+		return series[i].CloseTime.After(closeTime)
+	})
+	start := end - limit
+	if start < 0 {
+		start = 0
+	}
+
+	result := make([]domains.IKline, 0, end-start)
+	for _, kline := range series[start:end] {
+		if kline.CloseTime.Before(minCloseTime) {
+			continue
+		}
+		copied := *kline
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+// RefreshView drops joined series, they are joined again from the store on the next query
+func (r *SyntheticKline) RefreshView() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.series = make(map[syntheticKlineKey][]*domains.SyntheticKline)
+	return nil
+}
@@ -0,0 +1,360 @@
+package memory
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/data/dto/postgres/transaction"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+func NewTransaction() *Transaction {
+	return &Transaction{}
+}
+
+// NewRunTransaction saves transactions with the backtest run id, the repository keeps only one run
+func NewRunTransaction(runId int64) *Transaction {
+	return &Transaction{runId: sql.NullInt64{Int64: runId, Valid: true}}
+}
+
+// Transaction keeps transactions in the order of saving, queries return copies as postgres does
+type Transaction struct {
+	mutex        sync.RWMutex
+	lastId       int64
+	runId        sql.NullInt64
+	transactions []*domains.Transaction
+}
+
+// findLast returns the latest created transaction matching the filter, the later saved one wins on the same created_at
+func (r *Transaction) findLast(filter func(t *domains.Transaction) bool) *domains.Transaction {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var last *domains.Transaction
+	for _, t := range r.transactions {
+		if filter(t) && (last == nil || !t.CreatedAt.Before(last.CreatedAt)) {
+			last = t
+		}
+	}
+	if last == nil {
+		return nil
+	}
+	result := *last
+	return &result
+}
+
+// findAll returns copies of matching transactions sorted by created_at ascending
+func (r *Transaction) findAll(filter func(t *domains.Transaction) bool) []*domains.Transaction {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]*domains.Transaction, 0)
+	for _, t := range r.transactions {
+		if filter(t) {
+			copied := *t
+			result = append(result, &copied)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+func (r *Transaction) sum(filter func(t *domains.Transaction) bool, value func(t *domains.Transaction) int64) int64 {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	sum := int64(0)
+	for _, t := range r.transactions {
+		if filter(t) {
+			sum += value(t)
+		}
+	}
+	return sum
+}
+
+func isOpened(t *domains.Transaction) bool {
+	return !t.RelatedTransactionId.Valid
+}
+
+func isClosed(t *domains.Transaction) bool {
+	return t.Profit.Valid
+}
+
+func profit(t *domains.Transaction) int64 {
+	return t.Profit.Int64
+}
+
+// totalCost is rounded as postgres does on sum of double into bigint
+func totalCost(t *domains.Transaction) int64 {
+	return int64(t.TotalCost)
+}
+
+// isCreatedAtDay is date_trunc('day', created_at) = date
+func isCreatedAtDay(t *domains.Transaction, date time.Time) bool {
+	return t.CreatedAt.Truncate(24 * time.Hour).Equal(date)
+}
+
+func (r *Transaction) FindById(id int64) (*domains.Transaction, error) {
+	return r.findLast(func(t *domains.Transaction) bool {
+		return t.Id == id
+	}), nil
+}
+
+func (r *Transaction) FindLastByCoinId(coinId int64, tradingStrategy constants.TradingStrategy) (*domains.Transaction, error) {
+	return r.findLast(func(t *domains.Transaction) bool {
+		return t.CoinId == coinId && t.TradingStrategy == tradingStrategy
+	}), nil
+}
+
+func (r *Transaction) FindLastByCoinIdAndType(coinId int64, transactionType constants.TransactionType, tradingStrategy constants.TradingStrategy) (*domains.Transaction, error) {
+	return r.findLast(func(t *domains.Transaction) bool {
+		return t.CoinId == coinId && t.TransactionType == transactionType && t.TradingStrategy == tradingStrategy
+	}), nil
+}
+
+func (r *Transaction) FindLastBoughtNotSold(coinId int64, tradingStrategy constants.TradingStrategy) (*domains.Transaction, error) {
+	return r.findLast(func(t *domains.Transaction) bool {
+		return t.CoinId == coinId && t.TransactionType == constants.BUY && isOpened(t) && t.TradingStrategy == tradingStrategy
+	}), nil
+}
+
+func (r *Transaction) FindLastBoughtNotSoldAndDate(date time.Time, tradingStrategy constants.TradingStrategy) (*domains.Transaction, error) {
+	return r.findLast(func(t *domains.Transaction) bool {
+		return t.TransactionType == constants.BUY && isOpened(t) && isCreatedAtDay(t, date) && t.TradingStrategy == tradingStrategy
+	}), nil
+}
+
+func (r *Transaction) FindOpenedTransaction(tradingStrategy constants.TradingStrategy) (*domains.Transaction, error) {
+	return r.findLast(func(t *domains.Transaction) bool {
+		return isOpened(t) && t.TradingStrategy == tradingStrategy
+	}), nil
+}
+
+func (r *Transaction) FindOpenedTransactionByCoin(tradingStrategy constants.TradingStrategy, coinId int64) (*domains.Transaction, error) {
+	return r.findLast(func(t *domains.Transaction) bool {
+		return isOpened(t) && t.TradingStrategy == tradingStrategy && t.CoinId == coinId
+	}), nil
+}
+
+func (r *Transaction) FindOpenedTransactionByCoinAndTradingKey(tradingStrategy constants.TradingStrategy, coinId int64, tradingKey string) (*domains.Transaction, error) {
+	return r.findLast(func(t *domains.Transaction) bool {
+		return isOpened(t) && t.TradingStrategy == tradingStrategy && t.CoinId == coinId && t.TradingKey == tradingKey
+	}), nil
+}
+
+// FindAllOpenedTransactions returns transactions sorted by created_at descending as postgres repository does
+func (r *Transaction) FindAllOpenedTransactions(tradingStrategy constants.TradingStrategy) ([]*domains.Transaction, error) {
+	result := r.findAll(func(t *domains.Transaction) bool {
+		return isOpened(t) && t.TradingStrategy == tradingStrategy
+	})
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
+
+// FindAllByTradingStrategyAndCreatedAtInRange returns open and close transactions sorted by created_at and id ascending, so the open transaction precedes its close one
+func (r *Transaction) FindAllByTradingStrategyAndCreatedAtInRange(tradingStrategy constants.TradingStrategy, from time.Time, to time.Time) ([]*domains.Transaction, error) {
+	return r.findAll(func(t *domains.Transaction) bool {
+		return t.TradingStrategy == tradingStrategy && !t.CreatedAt.Before(from) && !t.CreatedAt.After(to)
+	}), nil
+}
+
+func (r *Transaction) CalculateSumOfProfit(tradingStrategy constants.TradingStrategy) (int64, error) {
+	return r.sum(func(t *domains.Transaction) bool {
+		return isClosed(t) && t.TradingStrategy == tradingStrategy
+	}, profit), nil
+}
+
+func (r *Transaction) CalculateSumOfProfitByCoin(coinId int64, tradingStrategy constants.TradingStrategy) (int64, error) {
+	return r.sum(func(t *domains.Transaction) bool {
+		return isClosed(t) && t.CoinId == coinId && t.TradingStrategy == tradingStrategy && !t.IsFake
+	}, profit), nil
+}
+
+func (r *Transaction) CalculateSumOfProfitByCoinAndTradingKey(coinId int64, tradingStrategy constants.TradingStrategy, tradingKey string) (int64, error) {
+	return r.sum(func(t *domains.Transaction) bool {
+		return isClosed(t) && t.CoinId == coinId && t.TradingStrategy == tradingStrategy && !t.IsFake && t.TradingKey == tradingKey
+	}, profit), nil
+}
+
+func (r *Transaction) CalculateSumOfProfitByTradingKeyPrefix(tradingStrategy constants.TradingStrategy, tradingKeyPrefix string) (int64, error) {
+	return r.sum(func(t *domains.Transaction) bool {
+		return isClosed(t) && t.TradingStrategy == tradingStrategy && !t.IsFake && strings.HasPrefix(t.TradingKey, tradingKeyPrefix)
+	}, profit), nil
+}
+
+func (r *Transaction) CalculateSumOfSpentTransactions(tradingStrategy constants.TradingStrategy) (int64, error) {
+	return r.sum(func(t *domains.Transaction) bool {
+		return isOpened(t) && t.TradingStrategy == tradingStrategy
+	}, totalCost), nil
+}
+
+func (r *Transaction) CalculateSumOfSpentTransactionsAndCreatedAfter(date time.Time, tradingStrategy constants.TradingStrategy) (int64, error) {
+	return r.sum(func(t *domains.Transaction) bool {
+		return isOpened(t) && t.CreatedAt.After(date) && t.TradingStrategy == tradingStrategy
+	}, totalCost), nil
+}
+
+func (r *Transaction) CalculateSumOfProfitByDate(date time.Time, tradingStrategy constants.TradingStrategy) (int64, error) {
+	return r.sum(func(t *domains.Transaction) bool {
+		return isClosed(t) && isCreatedAtDay(t, date) && t.TradingStrategy == tradingStrategy
+	}, profit), nil
+}
+
+func (r *Transaction) FindMinPriceByDate(date time.Time, tradingStrategy constants.TradingStrategy) (int64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	found := false
+	minPrice := float64(0)
+	for _, t := range r.transactions {
+		if isCreatedAtDay(t, date) && t.TradingStrategy == tradingStrategy && (!found || t.Price < minPrice) {
+			minPrice = t.Price
+			found = true
+		}
+	}
+	return int64(minPrice), nil
+}
+
+func (r *Transaction) CalculateSumOfSpentTransactionsByDate(date time.Time, tradingStrategy constants.TradingStrategy) (int64, error) {
+	return r.sum(func(t *domains.Transaction) bool {
+		return isOpened(t) && isCreatedAtDay(t, date) && t.TradingStrategy == tradingStrategy
+	}, totalCost), nil
+}
+
+func (r *Transaction) CalculateSumOfTransactionsByDateAndType(date time.Time, transType constants.TransactionType, tradingStrategy constants.TradingStrategy) (int64, error) {
+	return r.sum(func(t *domains.Transaction) bool {
+		return isCreatedAtDay(t, date) && t.TransactionType == transType && t.TradingStrategy == tradingStrategy
+	}, totalCost), nil
+}
+
+func (r *Transaction) FindAllProfitPercents(tradingStrategy int) ([]transaction.TransactionProfitPercentsDto, error) {
+	return r.findAllProfitPercents(func(t *domains.Transaction) bool {
+		return t.TradingStrategy == constants.TradingStrategy(tradingStrategy)
+	}), nil
+}
+
+// FindAllRealProfitPercents skips fake transactions, they are shadow trades without exchange orders
+func (r *Transaction) FindAllRealProfitPercents(tradingStrategy int) ([]transaction.TransactionProfitPercentsDto, error) {
+	return r.findAllProfitPercents(func(t *domains.Transaction) bool {
+		return t.TradingStrategy == constants.TradingStrategy(tradingStrategy) && !t.IsFake
+	}), nil
+}
+
+// findAllProfitPercents sums percents of closed transactions grouped by created_at ascending
+func (r *Transaction) findAllProfitPercents(filter func(t *domains.Transaction) bool) []transaction.TransactionProfitPercentsDto {
+	profitPercents := make([]transaction.TransactionProfitPercentsDto, 0)
+	for _, t := range r.findAll(func(t *domains.Transaction) bool { return isClosed(t) && filter(t) }) {
+		last := len(profitPercents) - 1
+		if last >= 0 && profitPercents[last].CreatedAt.Equal(t.CreatedAt) {
+			profitPercents[last].ProfitPercent += t.PercentProfit.Float64
+			continue
+		}
+		profitPercents = append(profitPercents, transaction.TransactionProfitPercentsDto{
+			CreatedAt:     t.CreatedAt,
+			ProfitPercent: t.PercentProfit.Float64,
+		})
+	}
+	return profitPercents
+}
+
+// FetchStatisticByDays returns statistic of the last 5 days with closed transactions, the latest day is first
+func (r *Transaction) FetchStatisticByDays(tradingStrategy int, coinIds []int64) ([]transaction.PairTransactionProfitPercentsDto, error) {
+	isOfCoins := make(map[int64]bool, len(coinIds))
+	for _, coinId := range coinIds {
+		isOfCoins[coinId] = true
+	}
+
+	byDays := make(map[string]*transaction.PairTransactionProfitPercentsDto)
+	counts := make(map[string]int64)
+	for _, t := range r.findAll(func(t *domains.Transaction) bool {
+		return t.TradingStrategy == constants.TradingStrategy(tradingStrategy) && isClosed(t) && isOfCoins[t.CoinId]
+	}) {
+		day := t.CreatedAt.Format(constants.DATE_FORMAT)
+		if _, ok := byDays[day]; !ok {
+			byDays[day] = &transaction.PairTransactionProfitPercentsDto{CreatedAt: day}
+		}
+		byDays[day].ProfitPercent += t.PercentProfit.Float64
+		byDays[day].ProfitInCents += t.Profit.Int64
+		counts[day]++
+	}
+
+	result := make([]transaction.PairTransactionProfitPercentsDto, 0, len(byDays))
+	for day, statistic := range byDays {
+		statistic.OrdersSize = counts[day] / 2
+		result = append(result, *statistic)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt > result[j].CreatedAt
+	})
+	if len(result) > 5 {
+		result = result[:5]
+	}
+	return result, nil
+}
+
+func (r *Transaction) FindAllCoinIds(tradingStrategy int) ([]int64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	isAdded := make(map[int64]bool)
+	result := make([]int64, 0)
+	for _, t := range r.transactions {
+		if t.TradingStrategy == constants.TradingStrategy(tradingStrategy) && !isAdded[t.CoinId] {
+			isAdded[t.CoinId] = true
+			result = append(result, t.CoinId)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] < result[j]
+	})
+	return result, nil
+}
+
+// SaveTransaction inserts new transaction or updates the same columns as postgres repository does
+func (r *Transaction) SaveTransaction(trnsctn *domains.Transaction) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if trnsctn.Id == 0 {
+		r.lastId++
+		trnsctn.Id = r.lastId
+		trnsctn.RunId = r.runId
+		stored := *trnsctn
+		r.transactions = append(r.transactions, &stored)
+		return nil
+	}
+
+	for _, stored := range r.transactions {
+		if stored.Id != trnsctn.Id {
+			continue
+		}
+		stored.CoinId = trnsctn.CoinId
+		stored.TransactionType = trnsctn.TransactionType
+		stored.Amount = trnsctn.Amount
+		stored.Price = trnsctn.Price
+		stored.TotalCost = trnsctn.TotalCost
+		stored.ClientOrderId = trnsctn.ClientOrderId
+		stored.ApiError = trnsctn.ApiError
+		stored.RelatedTransactionId = trnsctn.RelatedTransactionId
+		stored.Profit = trnsctn.Profit
+		stored.PercentProfit = trnsctn.PercentProfit
+		stored.Commission = trnsctn.Commission
+		return nil
+	}
+	return fmt.Errorf("Unexpected updated rows count: %d", 0)
+}
+
+// FindAll returns all saved transactions sorted by created_at ascending, it's used by reports of backtests
+func (r *Transaction) FindAll() []*domains.Transaction {
+	return r.findAll(func(t *domains.Transaction) bool {
+		return true
+	})
+}
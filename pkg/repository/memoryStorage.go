@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository/memory"
+	"cryptoBot/pkg/repository/postgres"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+const MEMORY_STORAGE_SCAN_RESULTS_LIMIT = 1000
+
+// MemoryStorage holds market data loaded once, every backtest gets its own isolated repositories from it,
+// so backtests run in parallel without postgres and don't see transactions of each other
+type MemoryStorage struct {
+	Coin   *memory.Coin
+	Klines *memory.KlineStore
+	/* Copied to funding rate repository of every backtest, payments are saved there */
+	FundingRates []*domains.FundingRate
+	/* Results of the last scan for pair arbitrage */
+	PairScanResult *memory.PairScanResult
+	/* Shared by all backtests of the storage */
+	BacktestRun *memory.BacktestRun
+}
+
+// NewMemoryStorage copies coins, funding rates and the last pair scan from postgres, klines are loaded from
+// postgres on the first query of the coin and interval or from csv files. Nil postgresDb means only csv files are used.
+func NewMemoryStorage(postgresDb *sqlx.DB, from time.Time, to time.Time, klinesCsvPaths ...string) (*MemoryStorage, error) {
+	storage := &MemoryStorage{
+		Coin:           memory.NewCoin(),
+		PairScanResult: memory.NewPairScanResult(),
+		BacktestRun:    memory.NewBacktestRun(),
+	}
+	var klineSource memory.KlineSource
+
+	if postgresDb != nil {
+		coins, err := postgres.NewCoin(postgresDb).FindAll()
+		if err != nil {
+			return nil, fmt.Errorf("Error during loading coins: %s", err.Error())
+		}
+		fundingRateRepo := postgres.NewFundingRate(postgresDb)
+		for _, coin := range coins {
+			if err := storage.Coin.SaveCoin(coin); err != nil {
+				return nil, err
+			}
+			rates, err := fundingRateRepo.FindAllByCoinIdAndFundingTimeInRange(coin.Id, time.Time{}, time.Now())
+			if err != nil {
+				return nil, fmt.Errorf("Error during loading funding rates: %s", err.Error())
+			}
+			storage.FundingRates = append(storage.FundingRates, rates...)
+		}
+
+		scanResults, err := postgres.NewPairScanResult(postgresDb).FindAllByLastScan(MEMORY_STORAGE_SCAN_RESULTS_LIMIT)
+		if err != nil {
+			return nil, fmt.Errorf("Error during loading pair scan results: %s", err.Error())
+		}
+		for _, scanResult := range scanResults {
+			if err := storage.PairScanResult.SavePairScanResult(scanResult); err != nil {
+				return nil, err
+			}
+		}
+		klineSource = postgres.NewKline(postgresDb)
+	}
+
+	storage.Klines = memory.NewKlineStore(klineSource, from, to)
+	for _, path := range klinesCsvPaths {
+		if err := storage.Klines.LoadCsv(path, storage.Coin); err != nil {
+			return nil, err
+		}
+	}
+	return storage, nil
+}
+
+func (s *MemoryStorage) NewRepositories() *Repository {
+	return s.newRepositories(memory.NewTransaction())
+}
+
+// NewRunRepositories saves transactions of the backtest run with its id
+func (s *MemoryStorage) NewRunRepositories(runId int64) *Repository {
+	return s.newRepositories(memory.NewRunTransaction(runId))
+}
+
+func (s *MemoryStorage) newRepositories(transactionRepo *memory.Transaction) *Repository {
+	fundingRateRepo := memory.NewFundingRate(transactionRepo)
+	fundingRateRepo.LoadFundingRates(s.FundingRates)
+
+	return &Repository{
+		Coin:             s.Coin,
+		Transaction:      transactionRepo,
+		PriceChange:      memory.NewPriceChange(),
+		Kline:            memory.NewKline(s.Klines),
+		ConditionalOrder: memory.NewConditionalOrder(),
+		SyntheticKline:   memory.NewSyntheticKline(s.Klines),
+		PairScanResult:   s.PairScanResult,
+		GridLevel:        memory.NewGridLevel(),
+		FundingRate:      fundingRateRepo,
+		Signal:           memory.NewSignal(),
+		StrategyState:    memory.NewStrategyState(),
+		BacktestRun:      s.BacktestRun,
+	}
+}
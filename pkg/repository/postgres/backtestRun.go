@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"strings"
+)
+
+func NewBacktestRun(db *sqlx.DB) *BacktestRun {
+	return &BacktestRun{db: db}
+}
+
+type BacktestRun struct {
+	db *sqlx.DB
+}
+
+func (r *BacktestRun) FindById(id int64) (*domains.BacktestRun, error) {
+	var run domains.BacktestRun
+	if err := r.db.Get(&run, "SELECT * FROM backtest_run WHERE id = $1", id); err != nil {
+		if strings.Contains(err.Error(), "no rows in result set") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// FindAll returns the latest runs first
+func (r *BacktestRun) FindAll(limit int) ([]*domains.BacktestRun, error) {
+	var runs []domains.BacktestRun
+	if err := r.db.Select(&runs, "SELECT * FROM backtest_run ORDER BY id DESC LIMIT $1", limit); err != nil {
+		return nil, fmt.Errorf("Error during select domain: %s", err.Error())
+	}
+
+	result := make([]*domains.BacktestRun, 0, len(runs))
+	for i := range runs {
+		result = append(result, &runs[i])
+	}
+	return result, nil
+}
+
+func (r *BacktestRun) SaveBacktestRun(domain *domains.BacktestRun) error {
+	if domain.Id == 0 {
+		id := int64(0)
+		err := r.db.QueryRow("INSERT INTO backtest_run (name, strategy, trading_strategy, coins, interval, params, from_time, to_time, git_revision, initial_capital, status, error, net_pnl, return_percent, sharpe, max_drawdown_percent, trades, win_rate, profit_factor, report, created_at, finished_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22) RETURNING id",
+			domain.Name, domain.Strategy, domain.TradingStrategy, domain.Coins, domain.Interval, domain.Params, domain.FromTime, domain.ToTime, domain.GitRevision, domain.InitialCapital, domain.Status, domain.Error,
+			domain.NetPnl, domain.ReturnPercent, domain.Sharpe, domain.MaxDrawdownPercent, domain.Trades, domain.WinRate, domain.ProfitFactor, domain.Report, domain.CreatedAt, domain.FinishedAt,
+		).Scan(&id)
+		if err != nil {
+			zap.S().Errorf("Invalid try to save Domain on proxy side: %s. "+
+				"Error: %s", domain.String(), err.Error())
+			return err
+		}
+		domain.Id = id
+		return nil
+	}
+
+	_, err := r.db.Exec("UPDATE backtest_run SET status = $2, error = $3, net_pnl = $4, return_percent = $5, sharpe = $6, max_drawdown_percent = $7, trades = $8, win_rate = $9, profit_factor = $10, report = $11, finished_at = $12 WHERE id = $1",
+		domain.Id, domain.Status, domain.Error, domain.NetPnl, domain.ReturnPercent, domain.Sharpe, domain.MaxDrawdownPercent, domain.Trades, domain.WinRate, domain.ProfitFactor, domain.Report, domain.FinishedAt)
+	if err != nil {
+		zap.S().Errorf("Invalid try to update domain on proxy side: %s. "+
+			"Error: %s", domain.String(), err.Error())
+	}
+	return err
+}
+
+// DeleteById deletes the run with its transactions and rows linked to them, signals and grid levels lose the link
+func (r *BacktestRun) DeleteById(id int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	queries := []string{
+		"DELETE FROM funding_payment WHERE transaction_id IN (SELECT id FROM transaction_table WHERE run_id = $1)",
+		"DELETE FROM price_change WHERE transaction_id IN (SELECT id FROM transaction_table WHERE run_id = $1)",
+		"DELETE FROM conditional_order WHERE related_transaction_id IN (SELECT id FROM transaction_table WHERE run_id = $1)",
+		"UPDATE signal SET transaction_id = null WHERE transaction_id IN (SELECT id FROM transaction_table WHERE run_id = $1)",
+		"UPDATE grid_level SET transaction_id = null WHERE transaction_id IN (SELECT id FROM transaction_table WHERE run_id = $1)",
+		"DELETE FROM transaction_table WHERE run_id = $1",
+		"DELETE FROM backtest_run WHERE id = $1",
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, id); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("Error during deleting backtest run %d: %s", id, err.Error())
+		}
+	}
+	return tx.Commit()
+}
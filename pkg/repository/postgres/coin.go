@@ -35,3 +35,16 @@ func (r *Coin) FindById(id int64) (*domains.Coin, error) {
 	}
 	return &c, nil
 }
+
+func (r *Coin) FindAll() ([]*domains.Coin, error) {
+	var coins []domains.Coin
+	if err := r.db.Select(&coins, "SELECT * FROM coin ORDER BY id"); err != nil {
+		return nil, err
+	}
+
+	result := make([]*domains.Coin, 0, len(coins))
+	for i := range coins {
+		result = append(result, &coins[i])
+	}
+	return result, nil
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+func NewFundingRate(db *sqlx.DB) *FundingRate {
+	return &FundingRate{db: db}
+}
+
+// NewRunFundingRate sums funding income only of transactions of the backtest run
+func NewRunFundingRate(db *sqlx.DB, runId int64) *FundingRate {
+	return &FundingRate{db: db, runId: sql.NullInt64{Int64: runId, Valid: true}}
+}
+
+type FundingRate struct {
+	db    *sqlx.DB
+	runId sql.NullInt64
+}
+
+// SaveFundingRate ignores already saved rate
+func (r *FundingRate) SaveFundingRate(domain *domains.FundingRate) error {
+	_, err := r.db.Exec("INSERT INTO funding_rate (coin_id, funding_time, rate) values ($1, $2, $3) ON CONFLICT (coin_id, funding_time) DO NOTHING",
+		domain.CoinId, domain.FundingTime, domain.Rate)
+	if err != nil {
+		zap.S().Errorf("Invalid try to save Domain on proxy side: %s. "+
+			"Error: %s", domain.String(), err.Error())
+	}
+	return err
+}
+
+func (r *FundingRate) FindLastByCoinIdAndFundingTimeLessOrEqual(coinId int64, fundingTime time.Time) (*domains.FundingRate, error) {
+	var domain domains.FundingRate
+	if err := r.db.Get(&domain, "SELECT * FROM funding_rate WHERE coin_id = $1 AND funding_time <= $2 ORDER BY funding_time DESC LIMIT 1", coinId, fundingTime); err != nil {
+		if strings.Contains(err.Error(), "no rows in result set") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// FindAllByCoinIdAndFundingTimeInRange returns rates in (from, to] ordered by time
+func (r *FundingRate) FindAllByCoinIdAndFundingTimeInRange(coinId int64, from time.Time, to time.Time) ([]*domains.FundingRate, error) {
+	var rates []domains.FundingRate
+	err := r.db.Select(&rates, "SELECT * FROM funding_rate WHERE coin_id = $1 AND funding_time > $2 AND funding_time <= $3 ORDER BY funding_time ASC", coinId, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Error during select domain: %s", err.Error())
+	}
+
+	result := make([]*domains.FundingRate, 0, len(rates))
+	for i := range rates {
+		result = append(result, &rates[i])
+	}
+	return result, nil
+}
+
+func (r *FundingRate) SaveFundingPayment(domain *domains.FundingPayment) error {
+	id := int64(0)
+	err := r.db.QueryRow("INSERT INTO funding_payment (transaction_id, coin_id, funding_time, rate, income) values ($1, $2, $3, $4, $5) RETURNING id",
+		domain.TransactionId, domain.CoinId, domain.FundingTime, domain.Rate, domain.Income,
+	).Scan(&id)
+	if err != nil {
+		zap.S().Errorf("Invalid try to save Domain on proxy side: %s. "+
+			"Error: %s", domain.String(), err.Error())
+		return err
+	}
+	domain.Id = id
+	return nil
+}
+
+func (r *FundingRate) FindLastPaymentTimeByTransactionId(transactionId int64) (*time.Time, error) {
+	var fundingTime sql.NullTime
+	if err := r.db.Get(&fundingTime, "SELECT max(funding_time) FROM funding_payment WHERE transaction_id = $1", transactionId); err != nil {
+		return nil, err
+	}
+	if !fundingTime.Valid {
+		return nil, nil
+	}
+	return &fundingTime.Time, nil
+}
+
+// CalculateSumOfFundingIncome returns income in cents of all transactions of the trading strategy with the trading key prefix
+func (r *FundingRate) CalculateSumOfFundingIncome(tradingStrategy int, tradingKeyPrefix string) (int64, error) {
+	var income sql.NullInt64
+	err := r.db.Get(&income, "SELECT sum(p.income) FROM funding_payment p JOIN transaction_table t ON t.id = p.transaction_id WHERE t.trading_strategy = $1 AND t.trading_key LIKE $2"+scopeByRun("t.run_id", r.runId),
+		tradingStrategy, tradingKeyPrefix+"%")
+	return income.Int64, err
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+func NewGridLevel(db *sqlx.DB) *GridLevel {
+	return &GridLevel{db: db}
+}
+
+type GridLevel struct {
+	db *sqlx.DB
+}
+
+func (r *GridLevel) FindAllByGridName(gridName string) ([]*domains.GridLevel, error) {
+	var levels []domains.GridLevel
+	err := r.db.Select(&levels, "SELECT * FROM grid_level WHERE grid_name = $1 ORDER BY level_index ASC", gridName)
+	if err != nil {
+		return nil, fmt.Errorf("Error during select domain: %s", err.Error())
+	}
+
+	result := make([]*domains.GridLevel, 0, len(levels))
+	for i := range levels {
+		result = append(result, &levels[i])
+	}
+	return result, nil
+}
+
+func (r *GridLevel) SaveGridLevel(level *domains.GridLevel) error {
+	if level.Id == 0 {
+		id := int64(0)
+		err := r.db.QueryRow("INSERT INTO grid_level (grid_name, coin_id, level_index, open_price, close_price, quantity, state, transaction_id, updated_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
+			level.GridName, level.CoinId, level.LevelIndex, level.OpenPrice, level.ClosePrice, level.Quantity, level.State, level.TransactionId, level.UpdatedAt,
+		).Scan(&id)
+		if err != nil {
+			zap.S().Errorf("Invalid try to save Domain on proxy side: %s. "+
+				"Error: %s", level.String(), err.Error())
+			return err
+		}
+		level.Id = id
+		return nil
+	}
+
+	_, err := r.db.Exec("UPDATE grid_level SET open_price = $2, close_price = $3, quantity = $4, state = $5, transaction_id = $6, updated_at = $7 WHERE id = $1",
+		level.Id, level.OpenPrice, level.ClosePrice, level.Quantity, level.State, level.TransactionId, level.UpdatedAt)
+	if err != nil {
+		zap.S().Errorf("Invalid try to update domain on proxy side: %s. "+
+			"Error: %s", level.String(), err.Error())
+	}
+	return err
+}
+
+func (r *GridLevel) DeleteAllByGridName(gridName string) error {
+	_, err := r.db.Exec("DELETE FROM grid_level WHERE grid_name = $1", gridName)
+	return err
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+func NewPairScanResult(db *sqlx.DB) *PairScanResult {
+	return &PairScanResult{db: db}
+}
+
+type PairScanResult struct {
+	db *sqlx.DB
+}
+
+func (r *PairScanResult) SavePairScanResult(domain *domains.PairScanResult) error {
+	id := int64(0)
+	err := r.db.QueryRow("INSERT INTO pair_scan_result (scanned_at, coin_id_1, coin_id_2, duration, from_time, to_time, window_size, correlation, hedge_ratio, adf_statistic, half_life, cointegrated_windows, total_windows, score, rank) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id",
+		domain.ScannedAt, domain.CoinId1, domain.CoinId2, domain.Interval, domain.FromTime, domain.ToTime, domain.WindowSize,
+		domain.Correlation, domain.HedgeRatio, domain.AdfStatistic, domain.HalfLife, domain.CointegratedWindows, domain.TotalWindows, domain.Score, domain.Rank,
+	).Scan(&id)
+	if err != nil {
+		zap.S().Errorf("Invalid try to save Domain on proxy side: %s. "+
+			"Error: %s", domain.String(), err.Error())
+		return err
+	}
+	domain.Id = id
+	return nil
+}
+
+// FindAllByLastScan returns results of the latest scan ordered by rank, all results when limit is 0
+func (r *PairScanResult) FindAllByLastScan(limit int) ([]*domains.PairScanResult, error) {
+	query := "SELECT * FROM pair_scan_result WHERE scanned_at = (SELECT max(scanned_at) FROM pair_scan_result) ORDER BY rank ASC"
+	args := make([]interface{}, 0, 1)
+	if limit > 0 {
+		query += " LIMIT $1"
+		args = append(args, limit)
+	}
+
+	var results []domains.PairScanResult
+	err := r.db.Select(&results, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Error during select domain: %s", err.Error())
+	}
+
+	pointers := make([]*domains.PairScanResult, 0, len(results))
+	for i := range results {
+		pointers = append(pointers, &results[i])
+	}
+	return pointers, nil
+}
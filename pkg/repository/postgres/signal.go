@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/data/domains"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"strings"
+)
+
+func NewSignal(db *sqlx.DB) *Signal {
+	return &Signal{db: db}
+}
+
+type Signal struct {
+	db *sqlx.DB
+}
+
+func (r *Signal) SaveSignal(domain *domains.Signal) error {
+	id := int64(0)
+	err := r.db.QueryRow("INSERT INTO signal (trading_strategy, trading_key, coin_id, signal_type, futures_type, reason, price, cost, stop_loss_price, take_profit_price, indicators, related_signal_id, transaction_id, created_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id",
+		domain.TradingStrategy, domain.TradingKey, domain.CoinId, domain.SignalType, domain.FuturesType, domain.Reason, domain.Price, domain.Cost,
+		domain.StopLossPrice, domain.TakeProfitPrice, domain.Indicators, domain.RelatedSignalId, domain.TransactionId, domain.CreatedAt,
+	).Scan(&id)
+	if err != nil {
+		zap.S().Errorf("Invalid try to save Domain on proxy side: %s. "+
+			"Error: %s", domain.String(), err.Error())
+		return err
+	}
+	domain.Id = id
+	return nil
+}
+
+// FindOpenedSignalByCoinAndTradingKey returns the last open signal without close signal
+func (r *Signal) FindOpenedSignalByCoinAndTradingKey(tradingStrategy constants.TradingStrategy, coinId int64, tradingKey string) (*domains.Signal, error) {
+	var domain domains.Signal
+	err := r.db.Get(&domain, "SELECT * FROM signal s WHERE s.trading_strategy = $1 AND s.coin_id = $2 AND s.trading_key = $3 AND s.signal_type <> $4 "+
+		"AND NOT EXISTS (SELECT 1 FROM signal c WHERE c.related_signal_id = s.id) ORDER BY s.created_at DESC LIMIT 1",
+		tradingStrategy, coinId, tradingKey, constants.SIGNAL_CLOSE)
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows in result set") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// FindAllOpenedSignalsByCoinAndTradingKey returns open signals without close signal, the newest first
+func (r *Signal) FindAllOpenedSignalsByCoinAndTradingKey(tradingStrategy constants.TradingStrategy, coinId int64, tradingKey string) ([]*domains.Signal, error) {
+	var signals []domains.Signal
+	err := r.db.Select(&signals, "SELECT * FROM signal s WHERE s.trading_strategy = $1 AND s.coin_id = $2 AND s.trading_key = $3 AND s.signal_type <> $4 "+
+		"AND NOT EXISTS (SELECT 1 FROM signal c WHERE c.related_signal_id = s.id) ORDER BY s.created_at DESC",
+		tradingStrategy, coinId, tradingKey, constants.SIGNAL_CLOSE)
+	if err != nil {
+		return nil, fmt.Errorf("Error during select domain: %s", err.Error())
+	}
+
+	result := make([]*domains.Signal, 0, len(signals))
+	for i := range signals {
+		result = append(result, &signals[i])
+	}
+	return result, nil
+}
+
+func (r *Signal) FindAllByTradingStrategy(tradingStrategy constants.TradingStrategy, limit int) ([]*domains.Signal, error) {
+	var signals []domains.Signal
+	err := r.db.Select(&signals, "SELECT * FROM signal WHERE trading_strategy = $1 ORDER BY created_at DESC LIMIT $2", tradingStrategy, limit)
+	if err != nil {
+		return nil, fmt.Errorf("Error during select domain: %s", err.Error())
+	}
+
+	result := make([]*domains.Signal, 0, len(signals))
+	for i := range signals {
+		result = append(result, &signals[i])
+	}
+	return result, nil
+}
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+func NewStrategyState(db *sqlx.DB) *StrategyState {
+	return &StrategyState{db: db}
+}
+
+type StrategyState struct {
+	db *sqlx.DB
+}
+
+func (r *StrategyState) FindAllByStrategyKey(strategyKey string) ([]*domains.StrategyState, error) {
+	return r.findAll("SELECT * FROM strategy_state WHERE strategy_key = $1 ORDER BY state_key", strategyKey)
+}
+
+func (r *StrategyState) FindAllByStrategyKeyPrefix(strategyKeyPrefix string) ([]*domains.StrategyState, error) {
+	return r.findAll("SELECT * FROM strategy_state WHERE strategy_key LIKE $1 ORDER BY strategy_key, state_key", strategyKeyPrefix+"%")
+}
+
+func (r *StrategyState) findAll(query string, args ...interface{}) ([]*domains.StrategyState, error) {
+	var states []domains.StrategyState
+	if err := r.db.Select(&states, query, args...); err != nil {
+		return nil, fmt.Errorf("Error during select domain: %s", err.Error())
+	}
+
+	result := make([]*domains.StrategyState, 0, len(states))
+	for i := range states {
+		result = append(result, &states[i])
+	}
+	return result, nil
+}
+
+// SaveStrategyState inserts the first version or updates the value when saved version is Version-1
+func (r *StrategyState) SaveStrategyState(domain *domains.StrategyState) error {
+	if domain.Id == 0 {
+		id := int64(0)
+		err := r.db.QueryRow("INSERT INTO strategy_state (strategy_key, state_key, value, version, updated_at) values ($1, $2, $3, $4, $5) RETURNING id",
+			domain.StrategyKey, domain.StateKey, domain.Value, domain.Version, domain.UpdatedAt,
+		).Scan(&id)
+		if err != nil {
+			zap.S().Errorf("Invalid try to save Domain on proxy side: %s. "+
+				"Error: %s", domain.String(), err.Error())
+			return err
+		}
+		domain.Id = id
+		return nil
+	}
+
+	result, err := r.db.Exec("UPDATE strategy_state SET value = $2, version = $3, updated_at = $4 WHERE id = $1 AND version = $5",
+		domain.Id, domain.Value, domain.Version, domain.UpdatedAt, domain.Version-1)
+	if err != nil {
+		zap.S().Errorf("Invalid try to update domain on proxy side: %s. "+
+			"Error: %s", domain.String(), err.Error())
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("state %s is changed by another instance", domain.String())
+	}
+	return nil
+}
@@ -16,8 +16,27 @@ func NewTransaction(db *sqlx.DB) *Transaction {
 	return &Transaction{db: db}
 }
 
+// NewRunTransaction saves transactions with the backtest run id and finds only transactions of the run
+func NewRunTransaction(db *sqlx.DB, runId int64) *Transaction {
+	return &Transaction{db: db, runId: sql.NullInt64{Int64: runId, Valid: true}}
+}
+
 type Transaction struct {
 	db *sqlx.DB
+	/* Null for live trading, it doesn't see transactions of backtests */
+	runId sql.NullInt64
+}
+
+// scope restricts a query to transactions of the backtest run or to live transactions
+func (r *Transaction) scope() string {
+	return scopeByRun("run_id", r.runId)
+}
+
+func scopeByRun(column string, runId sql.NullInt64) string {
+	if runId.Valid {
+		return fmt.Sprintf(" AND %s = %d", column, runId.Int64)
+	}
+	return fmt.Sprintf(" AND %s IS NULL", column)
 }
 
 func (r *Transaction) find(query string, args ...interface{}) (*domains.Transaction, error) {
@@ -33,7 +52,7 @@ func (r *Transaction) find(query string, args ...interface{}) (*domains.Transact
 
 func (r *Transaction) FindOpenedTransaction(tradingStrategy constants.TradingStrategy) (*domains.Transaction, error) {
 	var transaction domains.Transaction
-	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE related_transaction_id is null AND trading_strategy=$1 order by created_at desc limit 1", tradingStrategy); err != nil {
+	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE related_transaction_id is null AND trading_strategy=$1"+r.scope()+" order by created_at desc limit 1", tradingStrategy); err != nil {
 		if strings.Contains(err.Error(), "no rows in result set") {
 			return nil, nil
 		}
@@ -44,7 +63,7 @@ func (r *Transaction) FindOpenedTransaction(tradingStrategy constants.TradingStr
 
 func (r *Transaction) FindOpenedTransactionByCoin(tradingStrategy constants.TradingStrategy, coinId int64) (*domains.Transaction, error) {
 	var transaction domains.Transaction
-	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE related_transaction_id is null AND trading_strategy=$1 AND coin_id=$2 order by created_at desc limit 1", tradingStrategy, coinId); err != nil {
+	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE related_transaction_id is null AND trading_strategy=$1"+r.scope()+" AND coin_id=$2 order by created_at desc limit 1", tradingStrategy, coinId); err != nil {
 		if strings.Contains(err.Error(), "no rows in result set") {
 			return nil, nil
 		}
@@ -55,7 +74,7 @@ func (r *Transaction) FindOpenedTransactionByCoin(tradingStrategy constants.Trad
 
 func (r *Transaction) FindOpenedTransactionByCoinAndTradingKey(tradingStrategy constants.TradingStrategy, coinId int64, tradingKey string) (*domains.Transaction, error) {
 	var transaction domains.Transaction
-	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE related_transaction_id is null AND trading_strategy=$1 AND coin_id=$2 AND trading_key = $3 order by created_at desc limit 1", tradingStrategy, coinId, tradingKey); err != nil {
+	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE related_transaction_id is null AND trading_strategy=$1"+r.scope()+" AND coin_id=$2 AND trading_key = $3 order by created_at desc limit 1", tradingStrategy, coinId, tradingKey); err != nil {
 		if strings.Contains(err.Error(), "no rows in result set") {
 			return nil, nil
 		}
@@ -66,7 +85,7 @@ func (r *Transaction) FindOpenedTransactionByCoinAndTradingKey(tradingStrategy c
 
 func (r *Transaction) FindAllOpenedTransactions(tradingStrategy constants.TradingStrategy) ([]*domains.Transaction, error) {
 	var klines []domains.Transaction
-	err := r.db.Select(&klines, "SELECT * FROM transaction_table WHERE related_transaction_id is null AND trading_strategy=$1 order by created_at desc",
+	err := r.db.Select(&klines, "SELECT * FROM transaction_table WHERE related_transaction_id is null AND trading_strategy=$1"+r.scope()+" order by created_at desc",
 		tradingStrategy)
 
 	if err != nil {
@@ -76,9 +95,35 @@ func (r *Transaction) FindAllOpenedTransactions(tradingStrategy constants.Tradin
 	return r.listRelationsToListRelationsPointers(klines), nil
 }
 
+// FindAllByTradingStrategyAndCreatedAtInRange returns open and close transactions sorted by created_at and id ascending, so the open transaction precedes its close one
+func (r *Transaction) FindAllByTradingStrategyAndCreatedAtInRange(tradingStrategy constants.TradingStrategy, from time.Time, to time.Time) ([]*domains.Transaction, error) {
+	var transactions []domains.Transaction
+	err := r.db.Select(&transactions, "SELECT * FROM transaction_table WHERE trading_strategy=$1"+r.scope()+" AND created_at >= $2 AND created_at <= $3 order by created_at asc, id asc",
+		tradingStrategy, from, to)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error during select domain: %s", err.Error())
+	}
+
+	return r.listRelationsToListRelationsPointers(transactions), nil
+}
+
 func (r *Transaction) FindAllProfitPercents(tradingStrategy int) ([]transaction.TransactionProfitPercentsDto, error) {
 	var profitPercents []transaction.TransactionProfitPercentsDto
-	err := r.db.Select(&profitPercents, "select created_at, sum(percent_profit) profit_percent from transaction_table where trading_strategy = $1 and profit is not null group by created_at order by created_at asc;",
+	err := r.db.Select(&profitPercents, "select created_at, sum(percent_profit) profit_percent from transaction_table where trading_strategy = $1"+r.scope()+" and profit is not null group by created_at order by created_at asc;",
+		tradingStrategy)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error during select domain: %s", err.Error())
+	}
+
+	return profitPercents, nil
+}
+
+// FindAllRealProfitPercents skips fake transactions, they are shadow trades without exchange orders
+func (r *Transaction) FindAllRealProfitPercents(tradingStrategy int) ([]transaction.TransactionProfitPercentsDto, error) {
+	var profitPercents []transaction.TransactionProfitPercentsDto
+	err := r.db.Select(&profitPercents, "select created_at, sum(percent_profit) profit_percent from transaction_table where trading_strategy = $1"+r.scope()+" and profit is not null and fake = false group by created_at order by created_at asc;",
 		tradingStrategy)
 
 	if err != nil {
@@ -91,7 +136,7 @@ func (r *Transaction) FindAllProfitPercents(tradingStrategy int) ([]transaction.
 func (r *Transaction) FetchStatisticByDays(tradingStrategy int, coinIds []int64) ([]transaction.PairTransactionProfitPercentsDto, error) {
 	var profitPercents []transaction.PairTransactionProfitPercentsDto
 
-	selectQuery := "select to_char(created_at, 'YYYY-MM-DD') created_date, sum(percent_profit) profit_percent_of_paired_order, sum(profit) profit_sum, count(1) / 2 orders_size from transaction_table where trading_strategy = ?  and profit is not null    and coin_id in (?) group by to_char(created_at, 'YYYY-MM-DD') order by to_char(created_at, 'YYYY-MM-DD') desc limit 5;"
+	selectQuery := "select to_char(created_at, 'YYYY-MM-DD') created_date, sum(percent_profit) profit_percent_of_paired_order, sum(profit) profit_sum, count(1) / 2 orders_size from transaction_table where trading_strategy = ?" + r.scope() + "  and profit is not null    and coin_id in (?) group by to_char(created_at, 'YYYY-MM-DD') order by to_char(created_at, 'YYYY-MM-DD') desc limit 5;"
 	preparedQuery, preparedParameters, _ := sqlx.In(selectQuery, tradingStrategy, coinIds)
 	err := r.db.Select(&profitPercents, r.db.Rebind(preparedQuery), preparedParameters...)
 
@@ -104,7 +149,7 @@ func (r *Transaction) FetchStatisticByDays(tradingStrategy int, coinIds []int64)
 
 func (r *Transaction) FindAllCoinIds(tradingStrategy int) ([]int64, error) {
 	var results []int64
-	err := r.db.Select(&results, "select distinct coin_id from transaction_table where trading_strategy = $1;",
+	err := r.db.Select(&results, "select distinct coin_id from transaction_table where trading_strategy = $1"+r.scope()+";",
 		tradingStrategy)
 
 	if err != nil {
@@ -135,7 +180,7 @@ func (r *Transaction) FindById(id int64) (*domains.Transaction, error) {
 
 func (r *Transaction) FindLastByCoinId(coinId int64, tradingStrategy constants.TradingStrategy) (*domains.Transaction, error) {
 	var transaction domains.Transaction
-	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE coin_id=$1 AND trading_strategy=$2 order by created_at desc limit 1", coinId, tradingStrategy); err != nil {
+	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE coin_id=$1 AND trading_strategy=$2"+r.scope()+" order by created_at desc limit 1", coinId, tradingStrategy); err != nil {
 		if strings.Contains(err.Error(), "no rows in result set") {
 			return nil, nil
 		}
@@ -146,7 +191,7 @@ func (r *Transaction) FindLastByCoinId(coinId int64, tradingStrategy constants.T
 
 func (r *Transaction) FindLastByCoinIdAndType(coinId int64, transactionType constants.TransactionType, tradingStrategy constants.TradingStrategy) (*domains.Transaction, error) {
 	var transaction domains.Transaction
-	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE coin_id=$1 and transaction_type=$2 AND trading_strategy=$3 order by created_at desc limit 1", coinId, transactionType, tradingStrategy); err != nil {
+	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE coin_id=$1 and transaction_type=$2 AND trading_strategy=$3"+r.scope()+" order by created_at desc limit 1", coinId, transactionType, tradingStrategy); err != nil {
 		if strings.Contains(err.Error(), "no rows in result set") {
 			return nil, nil
 		}
@@ -157,7 +202,7 @@ func (r *Transaction) FindLastByCoinIdAndType(coinId int64, transactionType cons
 
 func (r *Transaction) FindLastBoughtNotSold(coinId int64, tradingStrategy constants.TradingStrategy) (*domains.Transaction, error) {
 	var transaction domains.Transaction
-	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE coin_id=$1 and transaction_type=$2 and related_transaction_id is null AND trading_strategy=$3 order by created_at desc limit 1", int64(coinId), constants.BUY, tradingStrategy); err != nil {
+	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE coin_id=$1 and transaction_type=$2 and related_transaction_id is null AND trading_strategy=$3"+r.scope()+" order by created_at desc limit 1", int64(coinId), constants.BUY, tradingStrategy); err != nil {
 		if strings.Contains(err.Error(), "no rows in result set") {
 			return nil, nil
 		}
@@ -168,7 +213,7 @@ func (r *Transaction) FindLastBoughtNotSold(coinId int64, tradingStrategy consta
 
 func (r *Transaction) FindLastBoughtNotSoldAndDate(date time.Time, tradingStrategy constants.TradingStrategy) (*domains.Transaction, error) {
 	var transaction domains.Transaction
-	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE transaction_type=$1 and related_transaction_id is null and date_trunc('day', created_at) = $2 AND trading_strategy=$3 order by created_at desc limit 1", constants.BUY, date, tradingStrategy); err != nil {
+	if err := r.db.Get(&transaction, "SELECT * FROM transaction_table WHERE transaction_type=$1 and related_transaction_id is null and date_trunc('day', created_at) = $2 AND trading_strategy=$3"+r.scope()+" order by created_at desc limit 1", constants.BUY, date, tradingStrategy); err != nil {
 		if strings.Contains(err.Error(), "no rows in result set") {
 			return nil, nil
 		}
@@ -179,55 +224,61 @@ func (r *Transaction) FindLastBoughtNotSoldAndDate(date time.Time, tradingStrate
 
 func (r *Transaction) CalculateSumOfProfit(tradingStrategy constants.TradingStrategy) (int64, error) {
 	var sumOfProfit int64
-	err := r.db.Get(&sumOfProfit, "select sum(profit) from transaction_table where profit is not null AND trading_strategy=$1", tradingStrategy)
+	err := r.db.Get(&sumOfProfit, "select sum(profit) from transaction_table where profit is not null AND trading_strategy=$1"+r.scope(), tradingStrategy)
 	return sumOfProfit, err
 }
 
 func (r *Transaction) CalculateSumOfProfitByCoin(coinId int64, tradingStrategy constants.TradingStrategy) (int64, error) {
 	var sumOfProfit int64
-	err := r.db.Get(&sumOfProfit, "select sum(profit) from transaction_table where profit is not null AND coin_id=$1 AND trading_strategy=$2 AND fake = false", coinId, tradingStrategy)
+	err := r.db.Get(&sumOfProfit, "select sum(profit) from transaction_table where profit is not null AND coin_id=$1 AND trading_strategy=$2"+r.scope()+" AND fake = false", coinId, tradingStrategy)
 	return sumOfProfit, err
 }
 
 func (r *Transaction) CalculateSumOfProfitByCoinAndTradingKey(coinId int64, tradingStrategy constants.TradingStrategy, tradingKey string) (int64, error) {
-	var sumOfProfit int64
-	err := r.db.Get(&sumOfProfit, "select sum(profit) from transaction_table where profit is not null AND coin_id=$1 AND trading_strategy=$2 AND fake = false trading_key = $3 AND ", coinId, tradingStrategy, tradingKey)
-	return sumOfProfit, err
+	var sumOfProfit sql.NullInt64
+	err := r.db.Get(&sumOfProfit, "select sum(profit) from transaction_table where profit is not null AND coin_id=$1 AND trading_strategy=$2"+r.scope()+" AND fake = false AND trading_key = $3", coinId, tradingStrategy, tradingKey)
+	return sumOfProfit.Int64, err
+}
+
+func (r *Transaction) CalculateSumOfProfitByTradingKeyPrefix(tradingStrategy constants.TradingStrategy, tradingKeyPrefix string) (int64, error) {
+	var sumOfProfit sql.NullInt64
+	err := r.db.Get(&sumOfProfit, "select sum(profit) from transaction_table where profit is not null AND trading_strategy=$1"+r.scope()+" AND fake = false AND trading_key LIKE $2", tradingStrategy, tradingKeyPrefix+"%")
+	return sumOfProfit.Int64, err
 }
 
 func (r *Transaction) CalculateSumOfSpentTransactions(tradingStrategy constants.TradingStrategy) (int64, error) {
 	var sumOfSpent int64
-	err := r.db.Get(&sumOfSpent, "select sum(total_cost) from transaction_table where related_transaction_id is null AND trading_strategy=$1", tradingStrategy)
+	err := r.db.Get(&sumOfSpent, "select sum(total_cost) from transaction_table where related_transaction_id is null AND trading_strategy=$1"+r.scope(), tradingStrategy)
 	return sumOfSpent, err
 }
 
 func (r *Transaction) CalculateSumOfSpentTransactionsAndCreatedAfter(date time.Time, tradingStrategy constants.TradingStrategy) (int64, error) {
 	var sumOfSpent sql.NullInt64
-	err := r.db.Get(&sumOfSpent, "select sum(total_cost) from transaction_table where related_transaction_id is null and created_at > $1 AND trading_strategy=$2", date, tradingStrategy)
+	err := r.db.Get(&sumOfSpent, "select sum(total_cost) from transaction_table where related_transaction_id is null and created_at > $1 AND trading_strategy=$2"+r.scope(), date, tradingStrategy)
 	return sumOfSpent.Int64, err
 }
 
 func (r *Transaction) CalculateSumOfProfitByDate(date time.Time, tradingStrategy constants.TradingStrategy) (int64, error) {
 	var sumOfProfit int64
-	err := r.db.Get(&sumOfProfit, "select sum(profit) from transaction_table where profit is not null and date_trunc('day', created_at) = $1 AND trading_strategy=$2", date, tradingStrategy)
+	err := r.db.Get(&sumOfProfit, "select sum(profit) from transaction_table where profit is not null and date_trunc('day', created_at) = $1 AND trading_strategy=$2"+r.scope(), date, tradingStrategy)
 	return sumOfProfit, err
 }
 
 func (r *Transaction) FindMinPriceByDate(date time.Time, tradingStrategy constants.TradingStrategy) (int64, error) {
 	var sumOfSpent int64
-	err := r.db.Get(&sumOfSpent, "select min(price) from transaction_table where date_trunc('day', created_at) = $1 AND trading_strategy=$2", date, tradingStrategy)
+	err := r.db.Get(&sumOfSpent, "select min(price) from transaction_table where date_trunc('day', created_at) = $1 AND trading_strategy=$2"+r.scope(), date, tradingStrategy)
 	return sumOfSpent, err
 }
 
 func (r *Transaction) CalculateSumOfSpentTransactionsByDate(date time.Time, tradingStrategy constants.TradingStrategy) (int64, error) {
 	var sumOfSpent int64
-	err := r.db.Get(&sumOfSpent, "select sum(total_cost) from transaction_table where related_transaction_id is null and date_trunc('day', created_at) = $1 AND trading_strategy=$2", date, tradingStrategy)
+	err := r.db.Get(&sumOfSpent, "select sum(total_cost) from transaction_table where related_transaction_id is null and date_trunc('day', created_at) = $1 AND trading_strategy=$2"+r.scope(), date, tradingStrategy)
 	return sumOfSpent, err
 }
 
 func (r *Transaction) CalculateSumOfTransactionsByDateAndType(date time.Time, transType constants.TransactionType, tradingStrategy constants.TradingStrategy) (int64, error) {
 	var sumOfSpent int64
-	err := r.db.Get(&sumOfSpent, "select sum(total_cost) from transaction_table where date_trunc('day', created_at) = $1 and transaction_type = $2 AND trading_strategy=$3", date, transType, tradingStrategy)
+	err := r.db.Get(&sumOfSpent, "select sum(total_cost) from transaction_table where date_trunc('day', created_at) = $1 and transaction_type = $2 AND trading_strategy=$3"+r.scope(), date, transType, tradingStrategy)
 	return sumOfSpent, err
 }
 
@@ -238,9 +289,10 @@ func (r *Transaction) SaveTransaction(trnsctn *domains.Transaction) error {
 	}
 
 	if trnsctn.Id == 0 {
+		trnsctn.RunId = r.runId
 		transactionId := int64(0)
-		err := tx.QueryRow("INSERT INTO transaction_table (coin_id, transaction_type, amount, price, total_cost, created_at, client_order_id, api_error, related_transaction_id, profit, percent_profit, commission, trading_strategy, futures_type, stop_loss_price, take_profit_price, fake, trading_key) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18) RETURNING id",
-			trnsctn.CoinId, trnsctn.TransactionType, trnsctn.Amount, trnsctn.Price, trnsctn.TotalCost, trnsctn.CreatedAt, trnsctn.ClientOrderId, trnsctn.ApiError, trnsctn.RelatedTransactionId, trnsctn.Profit, trnsctn.PercentProfit, trnsctn.Commission, trnsctn.TradingStrategy, trnsctn.FuturesType, trnsctn.StopLossPrice, trnsctn.TakeProfitPrice, trnsctn.IsFake, trnsctn.TradingKey,
+		err := tx.QueryRow("INSERT INTO transaction_table (coin_id, transaction_type, amount, price, total_cost, created_at, client_order_id, api_error, related_transaction_id, profit, percent_profit, commission, trading_strategy, futures_type, stop_loss_price, take_profit_price, fake, trading_key, close_reason, run_id) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20) RETURNING id",
+			trnsctn.CoinId, trnsctn.TransactionType, trnsctn.Amount, trnsctn.Price, trnsctn.TotalCost, trnsctn.CreatedAt, trnsctn.ClientOrderId, trnsctn.ApiError, trnsctn.RelatedTransactionId, trnsctn.Profit, trnsctn.PercentProfit, trnsctn.Commission, trnsctn.TradingStrategy, trnsctn.FuturesType, trnsctn.StopLossPrice, trnsctn.TakeProfitPrice, trnsctn.IsFake, trnsctn.TradingKey, trnsctn.CloseReason, r.runId,
 		).Scan(&transactionId)
 		if err != nil {
 			_ = tx.Rollback()
@@ -13,20 +13,14 @@ import (
 	"strings"
 )
 
-var analyserServiceImpl *AnalyserService
-
 func NewAnalyserService(transactionRepo repository.Transaction, priceChangeRepo repository.PriceChange,
 	exchangeApi api.ExchangeApi, tradingService *trading.HolderStrategyTradingService) *AnalyserService {
-	if analyserServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	analyserServiceImpl = &AnalyserService{
+	return &AnalyserService{
 		transactionRepo: transactionRepo,
 		priceChangeRepo: priceChangeRepo,
 		exchangeApi:     exchangeApi,
 		tradingService:  tradingService,
 	}
-	return analyserServiceImpl
 }
 
 type AnalyserService struct {
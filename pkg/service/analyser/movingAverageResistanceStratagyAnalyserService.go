@@ -10,22 +10,16 @@ import (
 	"time"
 )
 
-var movingAverageResistanceStratagyAnalyserServiceImpl *MovingAverageResistanceStratagyAnalyserService
-
 func NewMovingAverageResistanceStratagyAnalyserService(transactionRepo repository.Transaction, priceChangeRepo repository.PriceChange,
 	exchangeApi api.ExchangeApi, tradingService *trading.MovingAverageResistanceStrategyTradingService,
 	klineRepo repository.Kline) *MovingAverageResistanceStratagyAnalyserService {
-	if movingAverageResistanceStratagyAnalyserServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	movingAverageResistanceStratagyAnalyserServiceImpl = &MovingAverageResistanceStratagyAnalyserService{
+	return &MovingAverageResistanceStratagyAnalyserService{
 		klineRepo:       klineRepo,
 		transactionRepo: transactionRepo,
 		priceChangeRepo: priceChangeRepo,
 		exchangeApi:     exchangeApi,
 		tradingService:  tradingService,
 	}
-	return movingAverageResistanceStratagyAnalyserServiceImpl
 }
 
 type MovingAverageResistanceStratagyAnalyserService struct {
@@ -41,11 +35,14 @@ func (s *MovingAverageResistanceStratagyAnalyserService) AnalyseCoin(coin *domai
 	timeIterator, _ := time.Parse(constants.DATE_FORMAT, from)
 	timeIterator = timeIterator.Add(time.Second * 2).Add(time.Hour)
 
+	// own clock of the run, services are moved by it instead of the clock passed to constructors
+	clockMock := date.NewClockMock(timeIterator)
+	s.tradingService.Clock = clockMock
+	s.tradingService.ExchangeDataService.Clock = clockMock
+	s.tradingService.MovingAverageService.Clock = clockMock
+
 	for ; timeIterator.Before(timeMax); timeIterator = timeIterator.Add(time.Minute * 1) {
-		clockMock := date.NewClockMock(timeIterator)
-		s.tradingService.Clock = clockMock
-		s.tradingService.ExchangeDataService.Clock = clockMock
-		s.tradingService.MovingAverageService.Clock = clockMock
+		clockMock.SetTime(timeIterator)
 
 		s.tradingService.BotSingleAction(coin)
 	}
@@ -10,22 +10,16 @@ import (
 	"time"
 )
 
-var movingAverageStrategyAnalyserServiceImpl *MovingAverageStrategyAnalyserService
-
 func NewMovingAverageStrategyAnalyserService(transactionRepo repository.Transaction, priceChangeRepo repository.PriceChange,
 	exchangeApi api.ExchangeApi, tradingService *trading.MovingAverageStrategyTradingService,
 	klineRepo repository.Kline) *MovingAverageStrategyAnalyserService {
-	if movingAverageStrategyAnalyserServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	movingAverageStrategyAnalyserServiceImpl = &MovingAverageStrategyAnalyserService{
+	return &MovingAverageStrategyAnalyserService{
 		klineRepo:       klineRepo,
 		transactionRepo: transactionRepo,
 		priceChangeRepo: priceChangeRepo,
 		exchangeApi:     exchangeApi,
 		tradingService:  tradingService,
 	}
-	return movingAverageStrategyAnalyserServiceImpl
 }
 
 type MovingAverageStrategyAnalyserService struct {
@@ -41,12 +35,15 @@ func (s *MovingAverageStrategyAnalyserService) AnalyseCoin(coin *domains.Coin, f
 	timeIterator, _ := time.Parse(constants.DATE_FORMAT, from)
 	timeIterator = timeIterator.Add(time.Second * 2).Add(time.Hour)
 
+	// own clock of the run, services are moved by it instead of the clock passed to constructors
+	clockMock := date.NewClockMock(timeIterator)
+	s.tradingService.Clock = clockMock
+	s.tradingService.ExchangeDataService.Clock = clockMock
+	s.tradingService.MovingAverageService.Clock = clockMock
+	s.tradingService.StandardDeviationService.Clock = clockMock
+
 	for ; timeIterator.Before(timeMax); timeIterator = timeIterator.Add(time.Minute * 15) {
-		clockMock := date.NewClockMock(timeIterator)
-		s.tradingService.Clock = clockMock
-		s.tradingService.ExchangeDataService.Clock = clockMock
-		s.tradingService.MovingAverageService.Clock = clockMock
-		s.tradingService.StandardDeviationService.Clock = clockMock
+		clockMock.SetTime(timeIterator)
 
 		s.tradingService.BotSingleAction(coin)
 	}
@@ -8,16 +8,10 @@ import (
 	"time"
 )
 
-var trendMeterStratagyAnalyserServiceImpl *TrendMeterStratagyAnalyserService
-
 func NewTrendMeterStratagyAnalyserService(tradingService *trading.TrendMeterStrategyTradingService) *TrendMeterStratagyAnalyserService {
-	if trendMeterStratagyAnalyserServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	trendMeterStratagyAnalyserServiceImpl = &TrendMeterStratagyAnalyserService{
+	return &TrendMeterStratagyAnalyserService{
 		tradingService: tradingService,
 	}
-	return trendMeterStratagyAnalyserServiceImpl
 }
 
 type TrendMeterStratagyAnalyserService struct {
@@ -29,16 +23,18 @@ func (s *TrendMeterStratagyAnalyserService) AnalyseCoin(coin *domains.Coin, from
 	timeIterator, _ := time.Parse(constants.DATE_FORMAT, from)
 	timeIterator = timeIterator.Add(time.Second * 2).Add(time.Hour)
 
-	for ; timeIterator.Before(timeMax); timeIterator = timeIterator.Add(time.Minute * 15) {
-		clockMock := date.NewClockMock(timeIterator)
+	// own clock of the run, services are moved by it instead of the clock passed to constructors
+	clockMock := date.NewClockMock(timeIterator)
+	s.tradingService.Clock = clockMock
+	s.tradingService.StandardDeviationService.TechanConvertorService.Clock = clockMock
+	s.tradingService.ExchangeDataService.Clock = clockMock
+	s.tradingService.StandardDeviationService.Clock = clockMock
+	s.tradingService.OrderManagerService.ProfitLossFinderService.Clock = clockMock
+	s.tradingService.OrderManagerService.Clock = clockMock
+	s.tradingService.KlinesFetcherService.Clock = clockMock
 
-		s.tradingService.Clock = clockMock
-		s.tradingService.StandardDeviationService.TechanConvertorService.Clock = clockMock
-		s.tradingService.ExchangeDataService.Clock = clockMock
-		s.tradingService.StandardDeviationService.Clock = clockMock
-		s.tradingService.OrderManagerService.ProfitLossFinderService.Clock = clockMock
-		s.tradingService.OrderManagerService.Clock = clockMock
-		s.tradingService.KlinesFetcherService.Clock = clockMock
+	for ; timeIterator.Before(timeMax); timeIterator = timeIterator.Add(time.Minute * 15) {
+		clockMock.SetTime(timeIterator)
 
 		s.tradingService.BotActionBuyMoreIfNeeded(coin)
 		s.tradingService.BotActionCloseOrderIfNeeded(coin)
@@ -0,0 +1,157 @@
+package chart
+
+import (
+	"cryptoBot/pkg/service/report"
+	"fmt"
+	"github.com/wcharczuk/go-chart"
+	"math"
+)
+
+const MONTE_CARLO_HISTOGRAM_BINS = 20
+
+func NewChartMonteCarloService(namePrefix string) *ChartMonteCarloService {
+	return &ChartMonteCarloService{NamePrefix: namePrefix}
+}
+
+// ChartMonteCarloService draws equity percentiles and distribution of final equity of Monte Carlo simulations
+type ChartMonteCarloService struct {
+	/* Added to chart names, e.g. run id */
+	NamePrefix string
+}
+
+// ChartEquityPercentiles draws percentiles of equity after every trade with the actual equity of the backtest
+func (s *ChartMonteCarloService) ChartEquityPercentiles(result *report.MonteCarloResult, actualEquity []float64) {
+	if len(result.Paths) == 0 {
+		return
+	}
+	chartName := fmt.Sprintf("%sMonte Carlo %s equity chart", s.NamePrefix, result.Method)
+
+	percentiles := []struct {
+		name  string
+		value float64
+		style chart.Style
+	}{
+		{"P5", 5, chart.Style{Show: true, StrokeColor: chart.ColorRed, StrokeDashArray: []float64{5.0, 5.0}}},
+		{"P25", 25, chart.Style{Show: true, StrokeColor: chart.ColorOrange}},
+		{"P50", 50, chart.Style{Show: true, StrokeColor: chart.ColorBlue, StrokeWidth: 2}},
+		{"P75", 75, chart.Style{Show: true, StrokeColor: chart.ColorCyan}},
+		{"P95", 95, chart.Style{Show: true, StrokeColor: chart.ColorGreen, StrokeDashArray: []float64{5.0, 5.0}}},
+	}
+
+	series := make([]chart.Series, 0, len(percentiles)+1)
+	for _, percentile := range percentiles {
+		yvalues := result.EquityPercentile(percentile.value)
+		series = append(series, chart.ContinuousSeries{
+			Name:    percentile.name,
+			Style:   percentile.style,
+			XValues: tradeNumbers(len(yvalues)),
+			YValues: yvalues,
+		})
+	}
+	if len(actualEquity) > 0 {
+		series = append(series, chart.ContinuousSeries{
+			Name:    "Actual",
+			Style:   chart.Style{Show: true, StrokeColor: chart.ColorBlack},
+			XValues: tradeNumbers(len(actualEquity)),
+			YValues: actualEquity,
+		})
+	}
+
+	graph := chart.Chart{
+		Width:  1500,
+		Height: 500,
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top: 50,
+			},
+		},
+		Series: series,
+		XAxis: chart.XAxis{
+			Name: "Trade",
+			Style: chart.Style{
+				Show: true,
+			},
+			ValueFormatter: func(v interface{}) string {
+				return fmt.Sprintf("%.0f", v.(float64))
+			},
+		},
+		YAxis: chart.YAxis{
+			Style: chart.Style{
+				Show: true,
+			},
+		},
+	}
+
+	graph.Elements = []chart.Renderable{chart.LegendThin(&graph)}
+
+	renderToFile(graph, chartName)
+}
+
+// ChartFinalEquityDistribution draws histogram of final equity of the simulations
+func (s *ChartMonteCarloService) ChartFinalEquityDistribution(result *report.MonteCarloResult) {
+	if len(result.Paths) == 0 {
+		return
+	}
+	chartName := fmt.Sprintf("%sMonte Carlo %s final equity chart", s.NamePrefix, result.Method)
+
+	minEquity, maxEquity := math.MaxFloat64, -math.MaxFloat64
+	for _, path := range result.Paths {
+		minEquity = math.Min(minEquity, path[len(path)-1])
+		maxEquity = math.Max(maxEquity, path[len(path)-1])
+	}
+	binWidth := (maxEquity - minEquity) / MONTE_CARLO_HISTOGRAM_BINS
+	if binWidth == 0 {
+		binWidth = 1
+	}
+
+	counts := make([]float64, MONTE_CARLO_HISTOGRAM_BINS)
+	for _, path := range result.Paths {
+		bin := int((path[len(path)-1] - minEquity) / binWidth)
+		if bin >= MONTE_CARLO_HISTOGRAM_BINS {
+			bin = MONTE_CARLO_HISTOGRAM_BINS - 1
+		}
+		counts[bin]++
+	}
+
+	bars := make([]chart.Value, 0, MONTE_CARLO_HISTOGRAM_BINS)
+	for i, count := range counts {
+		binStart := minEquity + binWidth*float64(i)
+		color := chart.ColorBlue
+		if binStart+binWidth <= result.InitialCapital {
+			color = chart.ColorRed
+		}
+		bars = append(bars, chart.Value{
+			Label: fmt.Sprintf("%.0f", binStart),
+			Value: count,
+			Style: chart.Style{Show: true, FillColor: color, StrokeColor: color},
+		})
+	}
+
+	graph := chart.BarChart{
+		Title:      chartName,
+		TitleStyle: chart.StyleShow(),
+		Width:      1500,
+		Height:     500,
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top: 50,
+			},
+		},
+		XAxis: chart.StyleShow(),
+		YAxis: chart.YAxis{
+			Style: chart.StyleShow(),
+		},
+		BarWidth: 60,
+		Bars:     bars,
+	}
+
+	renderToFile(graph, chartName)
+}
+
+func tradeNumbers(count int) []float64 {
+	numbers := make([]float64, count)
+	for i := range numbers {
+		numbers[i] = float64(i)
+	}
+	return numbers
+}
@@ -2,30 +2,32 @@ package chart
 
 import (
 	"bytes"
+	"cryptoBot/pkg/constants"
 	"cryptoBot/pkg/data/dto/postgres/transaction"
 	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/report"
 	moneyUtil "cryptoBot/pkg/util"
 	"fmt"
 	"github.com/wcharczuk/go-chart"
+	"github.com/wcharczuk/go-chart/drawing"
 	"github.com/wcharczuk/go-chart/util"
 	"go.uber.org/zap"
+	"io"
 	"io/ioutil"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
-var chartTradingStrategyServiceImpl *ChartTradingStrategyService
-
 func NewChartTradingStrategyService(transactionRepo repository.Transaction, coinRepo repository.Coin) *ChartTradingStrategyService {
-	if chartTradingStrategyServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	chartTradingStrategyServiceImpl = &ChartTradingStrategyService{
+	return &ChartTradingStrategyService{
 		transactionRepo:   transactionRepo,
 		coinRepo:          coinRepo,
 		InitialWalletCost: 20000,
 	}
-	return chartTradingStrategyServiceImpl
 }
 
 type ChartTradingStrategyService struct {
@@ -33,6 +35,11 @@ type ChartTradingStrategyService struct {
 	coinRepo          repository.Coin
 	transactionRepo   repository.Transaction
 	InitialWalletCost int64
+	/* Added to chart names, e.g. run id, so charts of runs with the same trading strategy don't overwrite each other */
+	NamePrefix string
+	/* Buy-and-hold curves of the same period and initial wallet drawn on the wallet chart, nil disables them */
+	Benchmark         *report.BenchmarkService
+	BenchmarkInterval string
 }
 
 func (s *ChartTradingStrategyService) ChartWalletTradingStrategy(tradingStrategy int) {
@@ -60,6 +67,9 @@ func (s *ChartTradingStrategyService) ChartWalletTradingStrategy(tradingStrategy
 		YValues: yvalues,
 	}
 
+	series := []chart.Series{mainSeries}
+	series = append(series, s.collectBenchmarkSeries(tradingStrategy, xvalues[0], xvalues[len(xvalues)-1])...)
+
 	graph := chart.Chart{
 		Width:  1500,
 		Height: 500,
@@ -68,9 +78,7 @@ func (s *ChartTradingStrategyService) ChartWalletTradingStrategy(tradingStrategy
 				Top: 50,
 			},
 		},
-		Series: []chart.Series{
-			mainSeries,
-		},
+		Series: series,
 		XAxis: chart.XAxis{
 			Style: chart.Style{
 				Show: true,
@@ -94,13 +102,142 @@ func (s *ChartTradingStrategyService) ChartWalletTradingStrategy(tradingStrategy
 	s.saveToFile(graph, chartName)
 }
 
+// collectBenchmarkSeries returns buy-and-hold curves of traded coins, BTC and basket starting with the initial wallet
+func (s *ChartTradingStrategyService) collectBenchmarkSeries(tradingStrategy int, from time.Time, to time.Time) []chart.Series {
+	if s.Benchmark == nil {
+		return nil
+	}
+
+	colors := []drawing.Color{chart.ColorAlternateGray, chart.ColorOrange, chart.ColorGreen, chart.ColorCyan}
+	curves := s.Benchmark.BuildCurves(s.findSymbols(tradingStrategy), s.BenchmarkInterval, from, to, moneyUtil.GetDollarsByCents(s.InitialWalletCost))
+	series := make([]chart.Series, 0, len(curves))
+	for i, curve := range curves {
+		var xvalues []time.Time
+		var yvalues []float64
+		for _, point := range curve.Equity {
+			xvalues = append(xvalues, point.Time)
+			yvalues = append(yvalues, point.Equity)
+		}
+		series = append(series, chart.TimeSeries{
+			Name: curve.Name,
+			Style: chart.Style{
+				Show:            true,
+				StrokeColor:     colors[i%len(colors)],
+				StrokeDashArray: []float64{5.0, 5.0},
+			},
+			XValues: xvalues,
+			YValues: yvalues,
+		})
+	}
+	return series
+}
+
 func (s *ChartTradingStrategyService) buildChartName(tradingStrategy int, chartType string) string {
+	chartName := s.NamePrefix + strings.Join(s.findSymbols(tradingStrategy), " - ") + " " + strconv.Itoa(tradingStrategy) + chartType
+	return chartName
+}
+
+func (s *ChartTradingStrategyService) findSymbols(tradingStrategy int) []string {
 	coinIds, _ := s.transactionRepo.FindAllCoinIds(tradingStrategy)
-	coin1, _ := s.coinRepo.FindById(coinIds[0])
-	coin2, _ := s.coinRepo.FindById(coinIds[1])
+	symbols := make([]string, 0, len(coinIds))
+	for _, coinId := range coinIds {
+		if coin, _ := s.coinRepo.FindById(coinId); coin != nil {
+			symbols = append(symbols, coin.Symbol)
+		}
+	}
+	return symbols
+}
 
-	chartName := coin1.Symbol + " - " + coin2.Symbol + " " + strconv.Itoa(tradingStrategy) + chartType
-	return chartName
+// ChartEquityCurvesTradingStrategy draws equity of all trades (shadow) and of trades placed on the exchange (real)
+func (s *ChartTradingStrategyService) ChartEquityCurvesTradingStrategy(tradingStrategy int) {
+	equityCurveFilterService := orders.NewEquityCurveFilterService(s.transactionRepo, date.GetClock(), 0, 0)
+	shadowCurve, err := equityCurveFilterService.BuildShadowCurve(constants.TradingStrategy(tradingStrategy))
+	if err != nil {
+		zap.S().Errorf("Error during search profit %s", err.Error())
+		return
+	}
+	realCurve, err := equityCurveFilterService.BuildRealCurve(constants.TradingStrategy(tradingStrategy))
+	if err != nil {
+		zap.S().Errorf("Error during search profit %s", err.Error())
+		return
+	}
+	// curves are the same without fake trades
+	if len(shadowCurve) == 0 || len(shadowCurve) == len(realCurve) {
+		return
+	}
+
+	chartName := s.buildChartName(tradingStrategy, " Equity curves chart")
+
+	shadowXValues, shadowYValues := s.collectEquityData(shadowCurve)
+	realXValues, realYValues := s.collectEquityData(realCurve)
+
+	shadowSeries := chart.TimeSeries{
+		Name: "Shadow",
+		Style: chart.Style{
+			Show:            true,
+			StrokeColor:     chart.ColorAlternateGray,
+			StrokeDashArray: []float64{5.0, 5.0},
+		},
+		XValues: shadowXValues,
+		YValues: shadowYValues,
+	}
+	realSeries := chart.TimeSeries{
+		Name: "Real",
+		Style: chart.Style{
+			Show:        true,
+			StrokeColor: chart.ColorBlue,
+		},
+		XValues: realXValues,
+		YValues: realYValues,
+	}
+
+	series := []chart.Series{shadowSeries}
+	if len(realCurve) > 0 {
+		series = append(series, realSeries)
+	}
+
+	graph := chart.Chart{
+		Width:  1500,
+		Height: 500,
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top: 50,
+			},
+		},
+		Series: series,
+		XAxis: chart.XAxis{
+			Style: chart.Style{
+				Show: true,
+			},
+			TickPosition: chart.TickPositionBetweenTicks,
+			ValueFormatter: func(v interface{}) string {
+				typed := v.(float64)
+				typedDate := util.Time.FromFloat64(typed)
+				return fmt.Sprintf("%d.%d.%d", typedDate.Day(), typedDate.Month(), typedDate.Year())
+			},
+		},
+		YAxis: chart.YAxis{
+			Style: chart.Style{
+				Show: true,
+			},
+		},
+	}
+
+	graph.Elements = []chart.Renderable{chart.LegendThin(&graph)}
+
+	s.saveToFile(graph, chartName)
+}
+
+func (s *ChartTradingStrategyService) collectEquityData(curve []orders.EquityPoint) ([]time.Time, []float64) {
+	var xvalues []time.Time
+	var yvalues []float64
+
+	for _, point := range curve {
+		xvalues = append(xvalues, point.Time)
+		yvalues = append(yvalues, point.Equity)
+	}
+
+	return xvalues, yvalues
 }
 
 func (s *ChartTradingStrategyService) ChartTransactionsTradingStrategy(tradingStrategy int) {
@@ -201,6 +338,15 @@ func (s *ChartTradingStrategyService) collectWalletData(transactionProfitPercent
 }
 
 func (s *ChartTradingStrategyService) saveToFile(graph chart.Chart, fileName string) {
+	renderToFile(graph, fileName)
+}
+
+type renderable interface {
+	Render(rp chart.RendererProvider, w io.Writer) error
+}
+
+// renderToFile saves png of the chart or the bar chart into charts directory
+func renderToFile(graph renderable, fileName string) {
 	buffer := bytes.NewBuffer([]byte{})
 	err := graph.Render(chart.PNG, buffer)
 
@@ -208,5 +354,11 @@ func (s *ChartTradingStrategyService) saveToFile(graph chart.Chart, fileName str
 		fmt.Println(err)
 	}
 
-	ioutil.WriteFile("charts/"+fileName+".png", buffer.Bytes(), 0644)
+	if err := os.MkdirAll("charts", 0755); err != nil {
+		zap.S().Errorf("Error during creating charts directory: %s", err.Error())
+		return
+	}
+	if err := ioutil.WriteFile("charts/"+fileName+".png", buffer.Bytes(), 0644); err != nil {
+		zap.S().Errorf("Error during saving chart %s: %s", fileName, err.Error())
+	}
 }
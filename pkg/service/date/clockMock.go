@@ -6,14 +6,12 @@ type ClockMock struct {
 	mockTime time.Time
 }
 
-var clockMockImpl Clock
-
+// NewClockMock returns own clock of a backtest run, it's passed to all services of the run,
+// so several backtests with own clocks can run in one process
 func NewClockMock(nowMock time.Time) Clock {
-	clockMockImpl = &ClockMock{
+	return &ClockMock{
 		mockTime: nowMock,
 	}
-
-	return clockMockImpl
 }
 
 func (c *ClockMock) NowTime() time.Time {
@@ -23,17 +21,3 @@ func (c *ClockMock) NowTime() time.Time {
 func (c *ClockMock) SetTime(nowMockTime time.Time) {
 	c.mockTime = nowMockTime
 }
-
-func GetClockMock() Clock {
-	if clockMockImpl != nil {
-		return clockMockImpl
-	}
-
-	clockMockImpl = &ClockMock{}
-
-	return clockMockImpl
-}
-
-func SetMockTime(nowMockTime time.Time) {
-	clockMockImpl.SetTime(nowMockTime)
-}
@@ -11,21 +11,15 @@ import (
 	"strconv"
 )
 
-var exchangeDataServiceImpl *DataService
-
 func NewExchangeDataService(transactionRepo repository.Transaction, coinRepo repository.Coin, exchangeApi api.ExchangeApi,
 	clock date.Clock, klineRepo repository.Kline) *DataService {
-	if exchangeDataServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	exchangeDataServiceImpl = &DataService{
+	return &DataService{
 		transactionRepo: transactionRepo,
 		coinRepo:        coinRepo,
 		ExchangeApi:     exchangeApi,
 		Clock:           clock,
 		klineRepo:       klineRepo,
 	}
-	return exchangeDataServiceImpl
 }
 
 type DataService struct {
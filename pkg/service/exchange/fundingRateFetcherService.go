@@ -0,0 +1,78 @@
+package exchange
+
+import (
+	"cryptoBot/pkg/api"
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Bybit settles funding every 8 hours and returns at most 200 rates per request
+const FUNDING_RATES_PAGE = 200 * 8 * time.Hour
+
+func NewFundingRateFetcherService(exchangeApi api.ExchangeApi, fundingRateRepo repository.FundingRate, clock date.Clock) *FundingRateFetcherService {
+	return &FundingRateFetcherService{
+		fundingRateRepo: fundingRateRepo,
+		exchangeApi:     exchangeApi,
+		Clock:           clock,
+	}
+}
+
+type FundingRateFetcherService struct {
+	fundingRateRepo repository.FundingRate
+	exchangeApi     api.ExchangeApi
+	Clock           date.Clock
+}
+
+// FetchActualFundingRates saves rates settled since the last saved one, in backtest rates must be fetched before the run
+func (s *FundingRateFetcherService) FetchActualFundingRates(coin *domains.Coin) {
+	now := s.Clock.NowTime()
+	lastRate, err := s.fundingRateRepo.FindLastByCoinIdAndFundingTimeLessOrEqual(coin.Id, now)
+	if err != nil {
+		zap.S().Errorf("Error FindLast funding rate %s", err.Error())
+		return
+	}
+
+	fetchFrom := now.Add(-FUNDING_RATES_PAGE)
+	if lastRate != nil {
+		fetchFrom = lastRate.FundingTime.Add(time.Millisecond)
+		if now.Sub(lastRate.FundingTime) < 8*time.Hour {
+			return
+		}
+	}
+
+	if err := s.FetchFundingRatesForPeriod(coin, fetchFrom, now); err != nil {
+		zap.S().Errorf("Error during FetchFundingRatesForPeriod %s", err.Error())
+	}
+}
+
+func (s *FundingRateFetcherService) FetchFundingRatesForPeriod(coin *domains.Coin, timeFrom time.Time, timeTo time.Time) error {
+	for pageFrom := timeFrom; pageFrom.Before(timeTo); pageFrom = pageFrom.Add(FUNDING_RATES_PAGE) {
+		pageTo := pageFrom.Add(FUNDING_RATES_PAGE)
+		if pageTo.After(timeTo) {
+			pageTo = timeTo
+		}
+
+		rates, err := s.exchangeApi.GetFundingRateHistory(coin, pageFrom, pageTo)
+		if err != nil {
+			zap.S().Errorf("Error on fetch funding rates of %s from %s: %s", coin.Symbol, pageFrom.Format(constants.DATE_TIME_FORMAT), err)
+			return err
+		}
+
+		for _, dto := range rates {
+			err := s.fundingRateRepo.SaveFundingRate(&domains.FundingRate{
+				CoinId:      coin.Id,
+				FundingTime: dto.GetFundingTime(),
+				Rate:        dto.GetFundingRate(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
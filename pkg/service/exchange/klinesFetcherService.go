@@ -13,15 +13,12 @@ import (
 	"time"
 )
 
-var klinesFetcherServiceImpl *KlinesFetcherService
-
 func NewKlinesFetcherService(exchangeApi api.ExchangeApi, klineRepo repository.Kline, clock date.Clock) *KlinesFetcherService {
-	klinesFetcherServiceImpl = &KlinesFetcherService{
+	return &KlinesFetcherService{
 		klineRepo:   klineRepo,
 		exchangeApi: exchangeApi,
 		Clock:       clock,
 	}
-	return klinesFetcherServiceImpl
 }
 
 type KlinesFetcherService struct {
@@ -0,0 +1,251 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+)
+
+// MacKinnon critical values of Engle-Granger test for two variables with constant
+const (
+	ENGLE_GRANGER_CRITICAL_VALUE_1  = -3.90
+	ENGLE_GRANGER_CRITICAL_VALUE_5  = -3.34
+	ENGLE_GRANGER_CRITICAL_VALUE_10 = -3.04
+)
+
+type EngleGrangerResult struct {
+	Alpha        float64
+	Beta         float64
+	AdfStatistic float64
+	Residuals    []float64
+}
+
+// IsCointegrated compares ADF statistic of residuals with 5% critical value
+func (r EngleGrangerResult) IsCointegrated() bool {
+	return r.AdfStatistic < ENGLE_GRANGER_CRITICAL_VALUE_5
+}
+
+// IsCointegratedAt checks p-value of the test is below significance, only 0.01, 0.05 and 0.10 levels are tabulated,
+// other values are rounded to the nearest stricter level
+func (r EngleGrangerResult) IsCointegratedAt(significance float64) bool {
+	criticalValue := ENGLE_GRANGER_CRITICAL_VALUE_1
+	if significance >= 0.10 {
+		criticalValue = ENGLE_GRANGER_CRITICAL_VALUE_10
+	} else if significance >= 0.05 {
+		criticalValue = ENGLE_GRANGER_CRITICAL_VALUE_5
+	}
+	return r.AdfStatistic < criticalValue
+}
+
+func NewCointegrationService(adfLags int) *CointegrationService {
+	return &CointegrationService{
+		adfLags: adfLags,
+	}
+}
+
+// CointegrationService contains statistical tests used to find pairs for pair arbitrage
+type CointegrationService struct {
+	/* Count of lagged differences in ADF regression */
+	adfLags int
+}
+
+func (s *CointegrationService) Correlation(x []float64, y []float64) (float64, error) {
+	if len(x) != len(y) || len(x) < 2 {
+		return 0, fmt.Errorf("expected two series with the same length, got %d and %d", len(x), len(y))
+	}
+
+	n := float64(len(x))
+	meanX, meanY := float64(0), float64(0)
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	covariance, varianceX, varianceY := float64(0), float64(0), float64(0)
+	for i := range x {
+		covariance += (x[i] - meanX) * (y[i] - meanY)
+		varianceX += (x[i] - meanX) * (x[i] - meanX)
+		varianceY += (y[i] - meanY) * (y[i] - meanY)
+	}
+	if varianceX == 0 || varianceY == 0 {
+		return 0, fmt.Errorf("variance is zero")
+	}
+
+	return covariance / math.Sqrt(varianceX*varianceY), nil
+}
+
+// EngleGranger regresses y on x and runs ADF test on residuals
+func (s *CointegrationService) EngleGranger(y []float64, x []float64) (EngleGrangerResult, error) {
+	alpha, beta, err := OrdinaryLeastSquares(x, y)
+	if err != nil {
+		return EngleGrangerResult{}, err
+	}
+
+	residuals := make([]float64, len(y))
+	for i := range y {
+		residuals[i] = y[i] - alpha - beta*x[i]
+	}
+
+	adfStatistic, err := s.AugmentedDickeyFuller(residuals, false)
+	if err != nil {
+		return EngleGrangerResult{}, err
+	}
+
+	return EngleGrangerResult{Alpha: alpha, Beta: beta, AdfStatistic: adfStatistic, Residuals: residuals}, nil
+}
+
+// AugmentedDickeyFuller returns t-statistic of gamma in regression
+// diff(e[t]) = [c +] gamma*e[t-1] + sum(phi[i]*diff(e[t-i])), negative value means mean reverting series
+func (s *CointegrationService) AugmentedDickeyFuller(series []float64, withConstant bool) (float64, error) {
+	lags := s.adfLags
+	if len(series) < lags+10 {
+		return 0, fmt.Errorf("series is too short for ADF test: %d", len(series))
+	}
+
+	rows := make([][]float64, 0, len(series))
+	target := make([]float64, 0, len(series))
+	for t := lags + 1; t < len(series); t++ {
+		row := make([]float64, 0, lags+2)
+		row = append(row, series[t-1])
+		for i := 1; i <= lags; i++ {
+			row = append(row, series[t-i]-series[t-i-1])
+		}
+		if withConstant {
+			row = append(row, 1)
+		}
+		rows = append(rows, row)
+		target = append(target, series[t]-series[t-1])
+	}
+
+	coefficients, standardErrors, err := multipleRegression(rows, target)
+	if err != nil {
+		return 0, err
+	}
+	if standardErrors[0] == 0 {
+		return 0, fmt.Errorf("standard error of gamma is zero")
+	}
+
+	return coefficients[0] / standardErrors[0], nil
+}
+
+// HalfLife of mean reversion in klines by Ornstein-Uhlenbeck regression diff(s[t]) = c + lambda*s[t-1],
+// returns +Inf when series isn't mean reverting
+func (s *CointegrationService) HalfLife(series []float64) (float64, error) {
+	if len(series) < 3 {
+		return 0, fmt.Errorf("series is too short for half-life: %d", len(series))
+	}
+
+	lagged := make([]float64, 0, len(series)-1)
+	diffs := make([]float64, 0, len(series)-1)
+	for t := 1; t < len(series); t++ {
+		lagged = append(lagged, series[t-1])
+		diffs = append(diffs, series[t]-series[t-1])
+	}
+
+	_, lambda, err := OrdinaryLeastSquares(lagged, diffs)
+	if err != nil {
+		return 0, err
+	}
+	if lambda >= 0 {
+		return math.Inf(1), nil
+	}
+
+	return -math.Ln2 / lambda, nil
+}
+
+// multipleRegression solves ordinary least squares by normal equations, returns coefficients and their standard errors
+func multipleRegression(rows [][]float64, target []float64) ([]float64, []float64, error) {
+	n := len(rows)
+	k := len(rows[0])
+	if n <= k {
+		return nil, nil, fmt.Errorf("not enough observations %d for %d coefficients", n, k)
+	}
+
+	xtx := make([][]float64, k)
+	xty := make([]float64, k)
+	for i := 0; i < k; i++ {
+		xtx[i] = make([]float64, k)
+	}
+	for r, row := range rows {
+		for i := 0; i < k; i++ {
+			xty[i] += row[i] * target[r]
+			for j := 0; j < k; j++ {
+				xtx[i][j] += row[i] * row[j]
+			}
+		}
+	}
+
+	inverse, err := invertMatrix(xtx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coefficients := make([]float64, k)
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			coefficients[i] += inverse[i][j] * xty[j]
+		}
+	}
+
+	sumOfSquaredResiduals := float64(0)
+	for r, row := range rows {
+		predicted := float64(0)
+		for i := 0; i < k; i++ {
+			predicted += row[i] * coefficients[i]
+		}
+		sumOfSquaredResiduals += (target[r] - predicted) * (target[r] - predicted)
+	}
+	residualVariance := sumOfSquaredResiduals / float64(n-k)
+
+	standardErrors := make([]float64, k)
+	for i := 0; i < k; i++ {
+		standardErrors[i] = math.Sqrt(residualVariance * inverse[i][i])
+	}
+
+	return coefficients, standardErrors, nil
+}
+
+// invertMatrix by Gauss-Jordan elimination with partial pivoting
+func invertMatrix(matrix [][]float64) ([][]float64, error) {
+	k := len(matrix)
+	augmented := make([][]float64, k)
+	for i := range matrix {
+		augmented[i] = make([]float64, 2*k)
+		copy(augmented[i], matrix[i])
+		augmented[i][k+i] = 1
+	}
+
+	for column := 0; column < k; column++ {
+		pivot := column
+		for row := column + 1; row < k; row++ {
+			if math.Abs(augmented[row][column]) > math.Abs(augmented[pivot][column]) {
+				pivot = row
+			}
+		}
+		if math.Abs(augmented[pivot][column]) < 1e-12 {
+			return nil, fmt.Errorf("matrix is singular")
+		}
+		augmented[column], augmented[pivot] = augmented[pivot], augmented[column]
+
+		pivotValue := augmented[column][column]
+		for j := range augmented[column] {
+			augmented[column][j] /= pivotValue
+		}
+		for row := 0; row < k; row++ {
+			if row == column {
+				continue
+			}
+			factor := augmented[row][column]
+			for j := range augmented[row] {
+				augmented[row][j] -= factor * augmented[column][j]
+			}
+		}
+	}
+
+	inverse := make([][]float64, k)
+	for i := range augmented {
+		inverse[i] = augmented[i][k:]
+	}
+	return inverse, nil
+}
@@ -9,16 +9,10 @@ import (
 	"time"
 )
 
-var exponentialMovingAverageServiceImpl *ExponentialMovingAverageService
-
 func NewExponentialMovingAverageService(techanConvertorService *techanLib.TechanConvertorService) *ExponentialMovingAverageService {
-	if exponentialMovingAverageServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	exponentialMovingAverageServiceImpl = &ExponentialMovingAverageService{
+	return &ExponentialMovingAverageService{
 		TechanConvertorService: techanConvertorService,
 	}
-	return exponentialMovingAverageServiceImpl
 }
 
 type ExponentialMovingAverageService struct {
@@ -0,0 +1,231 @@
+package expression
+
+import (
+	"fmt"
+	"github.com/sdcoffey/techan"
+)
+
+// functionDefinition describes indicator available in expressions.
+// Integer arguments (windows) must be constants, the optional expression argument is a source, close by default.
+type functionDefinition struct {
+	minIntArguments int
+	maxIntArguments int
+	hasSource       bool
+	/* Calculates candles required by the indicator from its integer arguments */
+	window  func(values []float64) int
+	builder func(ctx *evaluationContext, values []float64, source techan.Indicator) techan.Indicator
+}
+
+var functions = map[string]functionDefinition{
+	"close": candleFunction(func(series *techan.TimeSeries) techan.Indicator {
+		return techan.NewClosePriceIndicator(series)
+	}),
+	"open": candleFunction(func(series *techan.TimeSeries) techan.Indicator {
+		return techan.NewOpenPriceIndicator(series)
+	}),
+	"high": candleFunction(func(series *techan.TimeSeries) techan.Indicator {
+		return techan.NewHighPriceIndicator(series)
+	}),
+	"low": candleFunction(func(series *techan.TimeSeries) techan.Indicator {
+		return techan.NewLowPriceIndicator(series)
+	}),
+	"volume": candleFunction(func(series *techan.TimeSeries) techan.Indicator {
+		return techan.NewVolumeIndicator(series)
+	}),
+	"sma": windowFunction(func(source techan.Indicator, window int) techan.Indicator {
+		return techan.NewSimpleMovingAverage(source, window)
+	}),
+	"ema": windowFunction(func(source techan.Indicator, window int) techan.Indicator {
+		return techan.NewEMAIndicator(source, window)
+	}),
+	"rsi": windowFunction(func(source techan.Indicator, window int) techan.Indicator {
+		return techan.NewRelativeStrengthIndexIndicator(source, window)
+	}),
+	"std": windowFunction(func(source techan.Indicator, window int) techan.Indicator {
+		return techan.NewWindowedStandardDeviationIndicator(source, window)
+	}),
+	"highest": windowFunction(func(source techan.Indicator, window int) techan.Indicator {
+		return techan.NewMaximumValueIndicator(source, window)
+	}),
+	"lowest": windowFunction(func(source techan.Indicator, window int) techan.Indicator {
+		return techan.NewMinimumValueIndicator(source, window)
+	}),
+	"prev": {
+		minIntArguments: 0,
+		maxIntArguments: 1,
+		hasSource:       true,
+		window: func(values []float64) int {
+			return intArgument(values, 0, 1)
+		},
+		builder: func(ctx *evaluationContext, values []float64, source techan.Indicator) techan.Indicator {
+			return offsetIndicator{indicator: source, offset: intArgument(values, 0, 1)}
+		},
+	},
+	"macd": {
+		minIntArguments: 2,
+		maxIntArguments: 2,
+		hasSource:       true,
+		window: func(values []float64) int {
+			return int(values[1])
+		},
+		builder: func(ctx *evaluationContext, values []float64, source techan.Indicator) techan.Indicator {
+			return techan.NewMACDIndicator(source, int(values[0]), int(values[1]))
+		},
+	},
+	"macd_signal": {
+		minIntArguments: 3,
+		maxIntArguments: 3,
+		hasSource:       true,
+		window: func(values []float64) int {
+			return int(values[1] + values[2])
+		},
+		builder: func(ctx *evaluationContext, values []float64, source techan.Indicator) techan.Indicator {
+			return techan.NewEMAIndicator(techan.NewMACDIndicator(source, int(values[0]), int(values[1])), int(values[2]))
+		},
+	},
+	"macd_hist": {
+		minIntArguments: 3,
+		maxIntArguments: 3,
+		hasSource:       true,
+		window: func(values []float64) int {
+			return int(values[1] + values[2])
+		},
+		builder: func(ctx *evaluationContext, values []float64, source techan.Indicator) techan.Indicator {
+			return techan.NewMACDHistogramIndicator(techan.NewMACDIndicator(source, int(values[0]), int(values[1])), int(values[2]))
+		},
+	},
+	"stoch_k": {
+		minIntArguments: 1,
+		maxIntArguments: 1,
+		window: func(values []float64) int {
+			return int(values[0])
+		},
+		builder: func(ctx *evaluationContext, values []float64, source techan.Indicator) techan.Indicator {
+			return techan.NewFastStochasticIndicator(ctx.series, int(values[0]))
+		},
+	},
+	"stoch_d": {
+		minIntArguments: 2,
+		maxIntArguments: 2,
+		window: func(values []float64) int {
+			return int(values[0] + values[1])
+		},
+		builder: func(ctx *evaluationContext, values []float64, source techan.Indicator) techan.Indicator {
+			return techan.NewSlowStochasticIndicator(techan.NewFastStochasticIndicator(ctx.series, int(values[0])), int(values[1]))
+		},
+	},
+	"atr": {
+		minIntArguments: 1,
+		maxIntArguments: 1,
+		window: func(values []float64) int {
+			return int(values[0]) + 1
+		},
+		builder: func(ctx *evaluationContext, values []float64, source techan.Indicator) techan.Indicator {
+			return techan.NewAverageTrueRangeIndicator(ctx.series, int(values[0]))
+		},
+	},
+	"bb_upper": {
+		minIntArguments: 2,
+		maxIntArguments: 2,
+		hasSource:       true,
+		window: func(values []float64) int {
+			return int(values[0])
+		},
+		builder: func(ctx *evaluationContext, values []float64, source techan.Indicator) techan.Indicator {
+			return techan.NewBollingerUpperBandIndicator(source, int(values[0]), values[1])
+		},
+	},
+	"bb_lower": {
+		minIntArguments: 2,
+		maxIntArguments: 2,
+		hasSource:       true,
+		window: func(values []float64) int {
+			return int(values[0])
+		},
+		builder: func(ctx *evaluationContext, values []float64, source techan.Indicator) techan.Indicator {
+			return techan.NewBollingerLowerBandIndicator(source, int(values[0]), values[1])
+		},
+	},
+}
+
+func candleFunction(indicator func(series *techan.TimeSeries) techan.Indicator) functionDefinition {
+	return functionDefinition{
+		window: func(values []float64) int {
+			return 0
+		},
+		builder: func(ctx *evaluationContext, values []float64, source techan.Indicator) techan.Indicator {
+			return indicator(ctx.series)
+		},
+	}
+}
+
+func windowFunction(indicator func(source techan.Indicator, window int) techan.Indicator) functionDefinition {
+	return functionDefinition{
+		minIntArguments: 1,
+		maxIntArguments: 1,
+		hasSource:       true,
+		window: func(values []float64) int {
+			return int(values[0])
+		},
+		builder: func(ctx *evaluationContext, values []float64, source techan.Indicator) techan.Indicator {
+			return indicator(source, int(values[0]))
+		},
+	}
+}
+
+func intArgument(values []float64, index int, defaultValue int) int {
+	if index < len(values) {
+		return int(values[index])
+	}
+	return defaultValue
+}
+
+// newFunctionNode validates arguments of the function call: constants for windows and optional source expression
+func newFunctionNode(name string, arguments []node) (*functionNode, error) {
+	definition, ok := functions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function '%s'", name)
+	}
+
+	values := make([]float64, 0, len(arguments))
+	var source numberNode
+	for i, argument := range arguments {
+		if constant, isConstant := argument.(*constantNode); isConstant && len(values) < definition.maxIntArguments {
+			values = append(values, constant.value)
+			continue
+		}
+
+		number, isNumber := argument.(numberNode)
+		if !isNumber || !definition.hasSource || source != nil {
+			return nil, fmt.Errorf("unexpected argument %d of function '%s'", i+1, name)
+		}
+		source = number
+	}
+
+	if len(values) < definition.minIntArguments {
+		return nil, fmt.Errorf("function '%s' expects at least %d numeric arguments", name, definition.minIntArguments)
+	}
+	for _, value := range values {
+		if value <= 0 {
+			return nil, fmt.Errorf("function '%s' expects positive arguments", name)
+		}
+	}
+
+	sourceArguments := make([]node, 0, 1)
+	if source != nil {
+		sourceArguments = append(sourceArguments, source)
+	}
+
+	return &functionNode{
+		name:      name,
+		arguments: sourceArguments,
+		window:    definition.window(values),
+		builder: func(ctx *evaluationContext, arguments []node) techan.Indicator {
+			var sourceIndicator techan.Indicator = techan.NewClosePriceIndicator(ctx.series)
+			if len(arguments) > 0 {
+				sourceIndicator = ctx.indicator(arguments[0].(numberNode))
+			}
+			return definition.builder(ctx, values, sourceIndicator)
+		},
+	}, nil
+}
@@ -0,0 +1,92 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenType int8
+
+const (
+	tokenEOF tokenType = iota
+	tokenNumber
+	tokenIdentifier
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+type token struct {
+	tokenType tokenType
+	value     string
+	position  int
+}
+
+// keywords are converted to operators, so `a and b` is the same as `a && b`
+var keywordOperators = map[string]string{
+	"and":           "&&",
+	"or":            "||",
+	"not":           "!",
+	"crosses_above": "crosses_above",
+	"crosses_below": "crosses_below",
+}
+
+var symbolOperators = []string{"&&", "||", ">=", "<=", "==", "!=", ">", "<", "!", "+", "-", "*", "/"}
+
+func tokenize(source string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenType: tokenLeftParen, value: "(", position: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenType: tokenRightParen, value: ")", position: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokenType: tokenComma, value: ",", position: i})
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenType: tokenNumber, value: string(runes[start:i]), position: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			word := strings.ToLower(string(runes[start:i]))
+			if operator, ok := keywordOperators[word]; ok {
+				tokens = append(tokens, token{tokenType: tokenOperator, value: operator, position: start})
+			} else {
+				tokens = append(tokens, token{tokenType: tokenIdentifier, value: word, position: start})
+			}
+		default:
+			matched := false
+			for _, operator := range symbolOperators {
+				if strings.HasPrefix(string(runes[i:]), operator) {
+					tokens = append(tokens, token{tokenType: tokenOperator, value: operator, position: i})
+					i += len([]rune(operator))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected symbol '%c' at position %d", r, i)
+			}
+		}
+	}
+
+	tokens = append(tokens, token{tokenType: tokenEOF, position: len(runes)})
+	return tokens, nil
+}
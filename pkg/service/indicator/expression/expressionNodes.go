@@ -0,0 +1,245 @@
+package expression
+
+import (
+	"github.com/sdcoffey/big"
+	"github.com/sdcoffey/techan"
+)
+
+// evaluationContext keeps indicators built for one series, so the same sub-expression is calculated once
+type evaluationContext struct {
+	series     *techan.TimeSeries
+	indicators map[numberNode]techan.Indicator
+}
+
+func newEvaluationContext(series *techan.TimeSeries) *evaluationContext {
+	return &evaluationContext{
+		series:     series,
+		indicators: make(map[numberNode]techan.Indicator),
+	}
+}
+
+func (ctx *evaluationContext) indicator(n numberNode) techan.Indicator {
+	if indicator, ok := ctx.indicators[n]; ok {
+		return indicator
+	}
+	indicator := n.build(ctx)
+	ctx.indicators[n] = indicator
+	return indicator
+}
+
+type node interface {
+	/* Count of candles before the evaluated one the node needs to be calculated */
+	lookback() int
+}
+
+type numberNode interface {
+	node
+	build(ctx *evaluationContext) techan.Indicator
+}
+
+type boolNode interface {
+	node
+	evaluate(ctx *evaluationContext, index int) bool
+}
+
+type constantNode struct {
+	value float64
+}
+
+func (n *constantNode) lookback() int {
+	return 0
+}
+
+func (n *constantNode) build(ctx *evaluationContext) techan.Indicator {
+	return techan.NewConstantIndicator(n.value)
+}
+
+// functionNode is an indicator like ema(50) or a candle value like close
+type functionNode struct {
+	name      string
+	arguments []node
+	window    int
+	builder   func(ctx *evaluationContext, arguments []node) techan.Indicator
+}
+
+func (n *functionNode) lookback() int {
+	result := n.window
+	for _, argument := range n.arguments {
+		if argumentLookback := argument.lookback(); result < n.window+argumentLookback {
+			result = n.window + argumentLookback
+		}
+	}
+	return result
+}
+
+func (n *functionNode) build(ctx *evaluationContext) techan.Indicator {
+	return n.builder(ctx, n.arguments)
+}
+
+type arithmeticNode struct {
+	operator string
+	left     numberNode
+	right    numberNode
+}
+
+func (n *arithmeticNode) lookback() int {
+	return maxLookback(n.left, n.right)
+}
+
+func (n *arithmeticNode) build(ctx *evaluationContext) techan.Indicator {
+	return arithmeticIndicator{
+		operator: n.operator,
+		left:     ctx.indicator(n.left),
+		right:    ctx.indicator(n.right),
+	}
+}
+
+type arithmeticIndicator struct {
+	operator string
+	left     techan.Indicator
+	right    techan.Indicator
+}
+
+func (i arithmeticIndicator) Calculate(index int) big.Decimal {
+	left := i.left.Calculate(index)
+	right := i.right.Calculate(index)
+
+	switch i.operator {
+	case "+":
+		return left.Add(right)
+	case "-":
+		return left.Sub(right)
+	case "*":
+		return left.Mul(right)
+	case "/":
+		if right.IsZero() {
+			return big.ZERO
+		}
+		return left.Div(right)
+	}
+	return big.ZERO
+}
+
+type negativeNode struct {
+	value numberNode
+}
+
+func (n *negativeNode) lookback() int {
+	return n.value.lookback()
+}
+
+func (n *negativeNode) build(ctx *evaluationContext) techan.Indicator {
+	return arithmeticIndicator{
+		operator: "-",
+		left:     techan.NewConstantIndicator(0),
+		right:    ctx.indicator(n.value),
+	}
+}
+
+// offsetIndicator returns value of the indicator some candles ago, used by prev(close, 1)
+type offsetIndicator struct {
+	indicator techan.Indicator
+	offset    int
+}
+
+func (i offsetIndicator) Calculate(index int) big.Decimal {
+	if index-i.offset < 0 {
+		return big.ZERO
+	}
+	return i.indicator.Calculate(index - i.offset)
+}
+
+type comparisonNode struct {
+	operator string
+	left     numberNode
+	right    numberNode
+}
+
+func (n *comparisonNode) lookback() int {
+	if n.operator == "crosses_above" || n.operator == "crosses_below" {
+		return maxLookback(n.left, n.right) + 1
+	}
+	return maxLookback(n.left, n.right)
+}
+
+func (n *comparisonNode) evaluate(ctx *evaluationContext, index int) bool {
+	left := ctx.indicator(n.left)
+	right := ctx.indicator(n.right)
+
+	switch n.operator {
+	case ">":
+		return left.Calculate(index).GT(right.Calculate(index))
+	case "<":
+		return left.Calculate(index).LT(right.Calculate(index))
+	case ">=":
+		return left.Calculate(index).GTE(right.Calculate(index))
+	case "<=":
+		return left.Calculate(index).LTE(right.Calculate(index))
+	case "==":
+		return left.Calculate(index).EQ(right.Calculate(index))
+	case "!=":
+		return !left.Calculate(index).EQ(right.Calculate(index))
+	case "crosses_above":
+		if index < 1 {
+			return false
+		}
+		return left.Calculate(index-1).LTE(right.Calculate(index-1)) && left.Calculate(index).GT(right.Calculate(index))
+	case "crosses_below":
+		if index < 1 {
+			return false
+		}
+		return left.Calculate(index-1).GTE(right.Calculate(index-1)) && left.Calculate(index).LT(right.Calculate(index))
+	}
+	return false
+}
+
+type logicalNode struct {
+	operator string
+	left     boolNode
+	right    boolNode
+}
+
+func (n *logicalNode) lookback() int {
+	return maxLookback(n.left, n.right)
+}
+
+func (n *logicalNode) evaluate(ctx *evaluationContext, index int) bool {
+	if n.operator == "&&" {
+		return n.left.evaluate(ctx, index) && n.right.evaluate(ctx, index)
+	}
+	return n.left.evaluate(ctx, index) || n.right.evaluate(ctx, index)
+}
+
+type notNode struct {
+	value boolNode
+}
+
+func (n *notNode) lookback() int {
+	return n.value.lookback()
+}
+
+func (n *notNode) evaluate(ctx *evaluationContext, index int) bool {
+	return !n.value.evaluate(ctx, index)
+}
+
+type boolConstantNode struct {
+	value bool
+}
+
+func (n *boolConstantNode) lookback() int {
+	return 0
+}
+
+func (n *boolConstantNode) evaluate(ctx *evaluationContext, index int) bool {
+	return n.value
+}
+
+func maxLookback(nodes ...node) int {
+	result := 0
+	for _, n := range nodes {
+		if n.lookback() > result {
+			result = n.lookback()
+		}
+	}
+	return result
+}
@@ -0,0 +1,315 @@
+package expression
+
+import (
+	"fmt"
+	"github.com/sdcoffey/techan"
+	"strconv"
+)
+
+// Expression is a parsed condition over indicators of techan series, e.g. `ema(50) > ema(200) && rsi(13) crosses_above 50`.
+//
+// Supported:
+//   - candle values: close, open, high, low, volume
+//   - indicators: sma(n), ema(n), rsi(n), std(n), highest(n), lowest(n), prev(source, n), macd(fast, slow),
+//     macd_signal(fast, slow, signal), macd_hist(fast, slow, signal), stoch_k(n), stoch_d(n, smooth), atr(n),
+//     bb_upper(n, sigma), bb_lower(n, sigma); optional expression argument is a source, e.g. ema(20, high)
+//   - arithmetic: + - * / and unary minus
+//   - comparison: > < >= <= == != crosses_above crosses_below
+//   - logic: && || ! (and, or, not), true, false
+type Expression struct {
+	source string
+	root   boolNode
+}
+
+func Parse(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression [%s]: %s", source, err.Error())
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.current().tokenType != tokenEOF {
+		err = fmt.Errorf("unexpected '%s' at position %d", p.current().value, p.current().position)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression [%s]: %s", source, err.Error())
+	}
+
+	condition, ok := root.(boolNode)
+	if !ok {
+		return nil, fmt.Errorf("invalid expression [%s]: result is not a condition", source)
+	}
+
+	return &Expression{source: source, root: condition}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Lookback returns count of candles required before the evaluated one
+func (e *Expression) Lookback() int {
+	return e.root.lookback()
+}
+
+// Evaluate checks the condition on the last candle of the series
+func (e *Expression) Evaluate(series *techan.TimeSeries) bool {
+	if series == nil {
+		return false
+	}
+	return e.EvaluateAt(series, series.LastIndex())
+}
+
+// EvaluateAt checks the condition on the candle with index, false when the series is too short
+func (e *Expression) EvaluateAt(series *techan.TimeSeries, index int) bool {
+	if series == nil || index < e.Lookback() || index > series.LastIndex() {
+		return false
+	}
+	return e.root.evaluate(newEvaluationContext(series), index)
+}
+
+type parser struct {
+	tokens   []token
+	position int
+}
+
+func (p *parser) current() token {
+	return p.tokens[p.position]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.position]
+	if t.tokenType != tokenEOF {
+		p.position++
+	}
+	return t
+}
+
+func (p *parser) isOperator(operators ...string) bool {
+	if p.current().tokenType != tokenOperator {
+		return false
+	}
+	for _, operator := range operators {
+		if p.current().value == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newLogicalNode("||", left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newLogicalNode("&&", left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isOperator("!") {
+		operator := p.next()
+		value, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		condition, ok := value.(boolNode)
+		if !ok {
+			return nil, fmt.Errorf("operator '!' at position %d expects a condition", operator.position)
+		}
+		return &notNode{value: condition}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOperator(">", "<", ">=", "<=", "==", "!=", "crosses_above", "crosses_below") {
+		return left, nil
+	}
+
+	operator := p.next()
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	leftNumber, isLeftNumber := left.(numberNode)
+	rightNumber, isRightNumber := right.(numberNode)
+	if !isLeftNumber || !isRightNumber {
+		return nil, fmt.Errorf("operator '%s' at position %d expects numbers", operator.value, operator.position)
+	}
+	return &comparisonNode{operator: operator.value, left: leftNumber, right: rightNumber}, nil
+}
+
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+", "-") {
+		operator := p.next()
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newArithmeticNode(operator, left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseProduct() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*", "/") {
+		operator := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newArithmeticNode(operator, left, right); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOperator("-") {
+		operator := p.next()
+		value, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		number, ok := value.(numberNode)
+		if !ok {
+			return nil, fmt.Errorf("operator '-' at position %d expects a number", operator.position)
+		}
+		if constant, isConstant := number.(*constantNode); isConstant {
+			return &constantNode{value: -constant.value}, nil
+		}
+		return &negativeNode{value: number}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.tokenType {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s' at position %d", t.value, t.position)
+		}
+		return &constantNode{value: value}, nil
+	case tokenLeftParen:
+		value, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.tokenType != tokenRightParen {
+			return nil, fmt.Errorf("expected ')' at position %d", closing.position)
+		}
+		return value, nil
+	case tokenIdentifier:
+		if t.value == "true" || t.value == "false" {
+			return &boolConstantNode{value: t.value == "true"}, nil
+		}
+		arguments, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+		function, err := newFunctionNode(t.value, arguments)
+		if err != nil {
+			return nil, fmt.Errorf("%s at position %d", err.Error(), t.position)
+		}
+		return function, nil
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected '%s' at position %d", t.value, t.position)
+}
+
+// parseArguments parses optional list of function arguments, `close` is the same as `close()`
+func (p *parser) parseArguments() ([]node, error) {
+	arguments := make([]node, 0)
+	if p.current().tokenType != tokenLeftParen {
+		return arguments, nil
+	}
+	p.next()
+
+	if p.current().tokenType == tokenRightParen {
+		p.next()
+		return arguments, nil
+	}
+
+	for {
+		argument, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, argument)
+
+		t := p.next()
+		if t.tokenType == tokenRightParen {
+			return arguments, nil
+		}
+		if t.tokenType != tokenComma {
+			return nil, fmt.Errorf("expected ',' or ')' at position %d", t.position)
+		}
+	}
+}
+
+func newLogicalNode(operator string, left node, right node) (node, error) {
+	leftCondition, isLeftCondition := left.(boolNode)
+	rightCondition, isRightCondition := right.(boolNode)
+	if !isLeftCondition || !isRightCondition {
+		return nil, fmt.Errorf("operator '%s' expects conditions", operator)
+	}
+	return &logicalNode{operator: operator, left: leftCondition, right: rightCondition}, nil
+}
+
+func newArithmeticNode(operator token, left node, right node) (node, error) {
+	leftNumber, isLeftNumber := left.(numberNode)
+	rightNumber, isRightNumber := right.(numberNode)
+	if !isLeftNumber || !isRightNumber {
+		return nil, fmt.Errorf("operator '%s' at position %d expects numbers", operator.value, operator.position)
+	}
+	return &arithmeticNode{operator: operator.value, left: leftNumber, right: rightNumber}, nil
+}
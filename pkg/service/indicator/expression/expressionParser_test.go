@@ -0,0 +1,139 @@
+package expression
+
+import (
+	"github.com/sdcoffey/big"
+	"github.com/sdcoffey/techan"
+	"strings"
+	"testing"
+	"time"
+)
+
+// buildSeries makes hourly candles with open, high, low and close equal to the price
+func buildSeries(prices ...float64) *techan.TimeSeries {
+	series := techan.NewTimeSeries()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, price := range prices {
+		candle := techan.NewCandle(techan.NewTimePeriod(start.Add(time.Duration(i)*time.Hour), time.Hour))
+		candle.OpenPrice = big.NewDecimal(price)
+		candle.MaxPrice = big.NewDecimal(price)
+		candle.MinPrice = big.NewDecimal(price)
+		candle.ClosePrice = big.NewDecimal(price)
+		candle.Volume = big.NewDecimal(1)
+		series.AddCandle(candle)
+	}
+	return series
+}
+
+func TestParsePrecedence(t *testing.T) {
+	series := buildSeries(10)
+	tests := []struct {
+		source   string
+		expected bool
+	}{
+		{"1 + 2 * 3 == 7", true},
+		{"(1 + 2) * 3 == 9", true},
+		{"10 - 4 - 3 == 3", true},
+		{"12 / 3 / 2 == 2", true},
+		{"-2 * 3 == -6", true},
+		{"close - -1 == 11", true},
+		{"true || false && false", true},
+		{"(true || false) && false", false},
+		{"!false && false", false},
+		{"!(false && false)", true},
+		{"not close > 20 or close < 5", true},
+		{"close > 5 and close < 20", true},
+		{"CLOSE >= 10 && close <= 10 && close != 11", true},
+	}
+
+	for _, test := range tests {
+		expression, err := Parse(test.source)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %s", test.source, err.Error())
+		}
+		if actual := expression.Evaluate(series); actual != test.expected {
+			t.Errorf("Parse(%q) evaluates to %v, expected %v", test.source, actual, test.expected)
+		}
+	}
+}
+
+func TestParseCrosses(t *testing.T) {
+	series := buildSeries(1, 3, 3, 1, 2, 2)
+	tests := []struct {
+		source   string
+		expected []bool
+	}{
+		{"close crosses_above 2", []bool{false, true, false, false, false, false}},
+		{"close crosses_below 2", []bool{false, false, false, true, false, false}},
+		// equal value is touch, it crosses on the next candle only when the line is left
+		{"close crosses_above 1", []bool{false, true, false, false, true, false}},
+		{"close crosses_above prev(close)", []bool{false, false, false, false, true, false}},
+	}
+
+	for _, test := range tests {
+		expression, err := Parse(test.source)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %s", test.source, err.Error())
+		}
+		for index, expected := range test.expected {
+			if actual := expression.EvaluateAt(series, index); actual != expected {
+				t.Errorf("Parse(%q) evaluates to %v at %d, expected %v", test.source, actual, index, expected)
+			}
+		}
+	}
+}
+
+func TestParseLookback(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected int
+	}{
+		{"close > 1", 0},
+		{"ema(50) > ema(200)", 200},
+		{"rsi(13) crosses_above 50", 14},
+		{"prev(sma(20), 2) < close", 22},
+		{"close > 1 || sma(10) > 1", 10},
+	}
+
+	for _, test := range tests {
+		expression, err := Parse(test.source)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %s", test.source, err.Error())
+		}
+		if actual := expression.Lookback(); actual != test.expected {
+			t.Errorf("Lookback of %q is %d, expected %d", test.source, actual, test.expected)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"close > 1)", "unexpected ')' at position 9"},
+		{"close > 1 close", "unexpected 'close' at position 10"},
+		{"(close > 1", "expected ')' at position 10"},
+		{"close > ", "unexpected end of expression"},
+		{"close $ 1", "unexpected symbol '$' at position 6"},
+		{"close > foo(3)", "unknown function 'foo' at position 8"},
+		{"sma(0) > 1", "function 'sma' expects positive arguments at position 0"},
+		{"close > rsi(-14)", "function 'rsi' expects positive arguments at position 8"},
+		{"sma() > 1", "function 'sma' expects at least 1 numeric arguments at position 0"},
+		{"sma(20, close, open) > 1", "unexpected argument 3 of function 'sma' at position 0"},
+		{"ema(20, high) > 1 && close", "operator '&&' expects conditions"},
+		{"!close", "operator '!' at position 0 expects a condition"},
+		{"close > (1 > 2)", "operator '>' at position 6 expects numbers"},
+		{"close + 1", "result is not a condition"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.source)
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded, expected error %q", test.source, test.expected)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("Parse(%q) failed with %q, expected %q", test.source, err.Error(), test.expected)
+		}
+	}
+}
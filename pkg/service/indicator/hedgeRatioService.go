@@ -0,0 +1,153 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+)
+
+type HedgeRatioMethod string
+
+const (
+	/* Spread is p1/p2, legs have equal cost */
+	HEDGE_RATIO_PRICE_RATIO HedgeRatioMethod = "ratio"
+	/* Spread is p1 - beta*p2, beta is OLS regression of p1 on p2 over the window */
+	HEDGE_RATIO_ROLLING_OLS HedgeRatioMethod = "ols"
+	/* Spread is p1 - beta*p2, beta is estimated by Kalman filter on every kline */
+	HEDGE_RATIO_KALMAN HedgeRatioMethod = "kalman"
+)
+
+func ParseHedgeRatioMethod(method string) (HedgeRatioMethod, error) {
+	switch HedgeRatioMethod(method) {
+	case HEDGE_RATIO_PRICE_RATIO, HEDGE_RATIO_ROLLING_OLS, HEDGE_RATIO_KALMAN:
+		return HedgeRatioMethod(method), nil
+	case "":
+		return HEDGE_RATIO_PRICE_RATIO, nil
+	}
+	return "", fmt.Errorf("unknown hedge ratio method [%s], expected ratio, ols or kalman", method)
+}
+
+// HedgeRatio is the spread of two legs and beta for the last price
+type HedgeRatio struct {
+	Spreads []float64
+	Beta    float64
+}
+
+func NewHedgeRatioService(kalmanDelta float64, kalmanObservationVariance float64) *HedgeRatioService {
+	return &HedgeRatioService{
+		kalmanDelta:               kalmanDelta,
+		kalmanObservationVariance: kalmanObservationVariance,
+	}
+}
+
+// HedgeRatioService estimates beta of pair p1 = alpha + beta*p2 used for the spread and the sizing of legs
+// https://www.quantstart.com/articles/Dynamic-Hedge-Ratio-Between-ETF-Pairs-Using-the-Kalman-Filter/
+type HedgeRatioService struct {
+	/* Speed of beta changes, bigger value - faster reaction */
+	kalmanDelta float64
+	/* Measurement noise of the price */
+	kalmanObservationVariance float64
+}
+
+// Calculate returns spread for every price, prices are ordered by time and have the same length
+func (s *HedgeRatioService) Calculate(method HedgeRatioMethod, prices1 []float64, prices2 []float64) (HedgeRatio, error) {
+	if len(prices1) != len(prices2) || len(prices1) < 2 {
+		return HedgeRatio{}, fmt.Errorf("expected two price series with the same length, got %d and %d", len(prices1), len(prices2))
+	}
+
+	switch method {
+	case HEDGE_RATIO_ROLLING_OLS:
+		return s.calculateByOls(prices1, prices2)
+	case HEDGE_RATIO_KALMAN:
+		return s.calculateByKalman(prices1, prices2), nil
+	}
+	return s.calculateByRatio(prices1, prices2), nil
+}
+
+func (s *HedgeRatioService) calculateByRatio(prices1 []float64, prices2 []float64) HedgeRatio {
+	spreads := make([]float64, len(prices1))
+	for i := range prices1 {
+		spreads[i] = prices1[i] / prices2[i]
+	}
+
+	// equal cost of legs
+	last := len(prices1) - 1
+	return HedgeRatio{Spreads: spreads, Beta: prices1[last] / prices2[last]}
+}
+
+func (s *HedgeRatioService) calculateByOls(prices1 []float64, prices2 []float64) (HedgeRatio, error) {
+	_, beta, err := OrdinaryLeastSquares(prices2, prices1)
+	if err != nil {
+		return HedgeRatio{}, err
+	}
+
+	spreads := make([]float64, len(prices1))
+	for i := range prices1 {
+		spreads[i] = prices1[i] - beta*prices2[i]
+	}
+
+	return HedgeRatio{Spreads: spreads, Beta: beta}, nil
+}
+
+// calculateByKalman runs filter with state [beta, alpha] through all prices, spread uses beta known at the moment of the price
+func (s *HedgeRatioService) calculateByKalman(prices1 []float64, prices2 []float64) HedgeRatio {
+	stateCovarianceNoise := s.kalmanDelta / (1 - s.kalmanDelta)
+
+	beta, alpha := prices1[0]/prices2[0], float64(0)
+	// covariance matrix of the state, big initial uncertainty lets the filter find alpha quickly
+	p00, p01, p10, p11 := float64(1), float64(0), float64(0), float64(1)
+
+	spreads := make([]float64, len(prices1))
+	for i := range prices1 {
+		x := prices2[i]
+
+		// prediction step, state is random walk
+		r00, r01, r10, r11 := p00+stateCovarianceNoise, p01, p10, p11+stateCovarianceNoise
+
+		spreads[i] = prices1[i] - beta*x
+
+		forecastError := prices1[i] - (beta*x + alpha)
+		forecastVariance := x*x*r00 + x*(r01+r10) + r11 + s.kalmanObservationVariance
+
+		gain0 := (r00*x + r01) / forecastVariance
+		gain1 := (r10*x + r11) / forecastVariance
+
+		beta += gain0 * forecastError
+		alpha += gain1 * forecastError
+
+		p00 = r00 - gain0*(x*r00+r10)
+		p01 = r01 - gain0*(x*r01+r11)
+		p10 = r10 - gain1*(x*r00+r10)
+		p11 = r11 - gain1*(x*r01+r11)
+	}
+
+	return HedgeRatio{Spreads: spreads, Beta: beta}
+}
+
+// OrdinaryLeastSquares fits y = alpha + beta*x
+func OrdinaryLeastSquares(x []float64, y []float64) (alpha float64, beta float64, err error) {
+	if len(x) != len(y) || len(x) < 2 {
+		return 0, 0, fmt.Errorf("expected two series with the same length, got %d and %d", len(x), len(y))
+	}
+
+	n := float64(len(x))
+	meanX, meanY := float64(0), float64(0)
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	covariance, varianceX := float64(0), float64(0)
+	for i := range x {
+		covariance += (x[i] - meanX) * (y[i] - meanY)
+		varianceX += (x[i] - meanX) * (x[i] - meanX)
+	}
+	if varianceX == 0 || math.IsNaN(varianceX) {
+		return 0, 0, fmt.Errorf("variance of x is zero")
+	}
+
+	beta = covariance / varianceX
+	alpha = meanY - beta*meanX
+	return alpha, beta, nil
+}
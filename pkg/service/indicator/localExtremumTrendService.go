@@ -12,17 +12,11 @@ import (
 	"time"
 )
 
-var localExtremumTrendServiceImpl *LocalExtremumTrendService
-
 func NewLocalExtremumTrendService(clock date.Clock, klineRepo repository.Kline) *LocalExtremumTrendService {
-	if localExtremumTrendServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	localExtremumTrendServiceImpl = &LocalExtremumTrendService{
+	return &LocalExtremumTrendService{
 		klineRepo: klineRepo,
 		Clock:     clock,
 	}
-	return localExtremumTrendServiceImpl
 }
 
 type LocalExtremumTrendService struct {
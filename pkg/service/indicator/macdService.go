@@ -7,16 +7,10 @@ import (
 	"github.com/sdcoffey/techan"
 )
 
-var macdServiceImpl *MACDService
-
 func NewMACDService(techanConvertorService *techanLib.TechanConvertorService) *MACDService {
-	if relativeStrengthIndexServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	macdServiceImpl = &MACDService{
+	return &MACDService{
 		TechanConvertorService: techanConvertorService,
 	}
-	return macdServiceImpl
 }
 
 //Moving average convergence divergence
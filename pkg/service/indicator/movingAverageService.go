@@ -9,17 +9,11 @@ import (
 	"go.uber.org/zap"
 )
 
-var movingAverageServiceImpl *MovingAverageService
-
 func NewMovingAverageService(clock date.Clock, klineRepo repository.Kline) *MovingAverageService {
-	if movingAverageServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	movingAverageServiceImpl = &MovingAverageService{
+	return &MovingAverageService{
 		klineRepo: klineRepo,
 		Clock:     clock,
 	}
-	return movingAverageServiceImpl
 }
 
 type MovingAverageService struct {
@@ -7,16 +7,10 @@ import (
 	"github.com/sdcoffey/techan"
 )
 
-var relativeStrengthIndexServiceImpl *RelativeStrengthIndexService
-
 func NewRelativeStrengthIndexService(techanConvertorService *techanLib.TechanConvertorService) *RelativeStrengthIndexService {
-	if relativeStrengthIndexServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	relativeStrengthIndexServiceImpl = &RelativeStrengthIndexService{
+	return &RelativeStrengthIndexService{
 		TechanConvertorService: techanConvertorService,
 	}
-	return relativeStrengthIndexServiceImpl
 }
 
 type RelativeStrengthIndexService struct {
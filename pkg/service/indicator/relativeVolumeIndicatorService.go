@@ -4,17 +4,11 @@ import (
 	"github.com/sdcoffey/techan"
 )
 
-var relativeVolumeIndicatorServiceImpl *RelativeVolumeIndicatorService
-
 func NewRelativeVolumeIndicatorService() *RelativeVolumeIndicatorService {
-	if relativeVolumeIndicatorServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	relativeVolumeIndicatorServiceImpl = &RelativeVolumeIndicatorService{
+	return &RelativeVolumeIndicatorService{
 		length: 13,
 		thresh: 11, //, 'Relative Volume Strength Threshold', minval=0)
 	}
-	return relativeVolumeIndicatorServiceImpl
 }
 
 //implemented by for tradingview indicator https://www.tradingview.com/pine/?id=PUB%3BFvJqumctetdFjTc3kvAdgJXrU6fkzHPt
@@ -2,35 +2,266 @@ package indicator
 
 import (
 	"cryptoBot/pkg/service/date"
+	"encoding/csv"
+	"fmt"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"os"
+	"strings"
+	"time"
+	// time zones of sessions don't depend on tzdata of the host
+	_ "time/tzdata"
 )
 
-var sessionsServiceImpl *SessionsService
+var sessionWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Session is trading hours in local time of the exchange, so daylight-saving time is applied by the time zone
+type Session struct {
+	Name     string
+	Location *time.Location
+	/* Time since local midnight, session ends on the next day when End <= Start */
+	Start    time.Duration
+	End      time.Duration
+	Weekdays map[time.Weekday]bool
+}
+
+// Contains checks the moment is in the session, weekday is the weekday of the session start
+func (s *Session) Contains(moment time.Time) bool {
+	local := moment.In(s.Location)
+	// wall clock time, elapsed time since midnight differs by an hour on days of daylight-saving time change
+	sinceMidnight := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+
+	if s.Start < s.End {
+		return s.Weekdays[local.Weekday()] && sinceMidnight >= s.Start && sinceMidnight < s.End
+	}
+	if sinceMidnight >= s.Start {
+		return s.Weekdays[local.Weekday()]
+	}
+	return sinceMidnight < s.End && s.Weekdays[local.AddDate(0, 0, -1).Weekday()]
+}
+
+// Blackout is a window without new positions, e.g. FOMC or CPI release
+type Blackout struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+func (b *Blackout) Contains(moment time.Time) bool {
+	return !moment.Before(b.From) && moment.Before(b.To)
+}
+
+// NewDefaultSessions returns London and New York sessions from 8:00 to 17:00 local time on weekdays
+func NewDefaultSessions() []*Session {
+	weekdays := map[time.Weekday]bool{time.Monday: true, time.Tuesday: true, time.Wednesday: true, time.Thursday: true, time.Friday: true}
+	london, _ := time.LoadLocation("Europe/London")
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	return []*Session{
+		{Name: "london", Location: london, Start: 8 * time.Hour, End: 17 * time.Hour, Weekdays: weekdays},
+		{Name: "newYork", Location: newYork, Start: 8 * time.Hour, End: 17 * time.Hour, Weekdays: weekdays},
+	}
+}
+
+// LoadSessions reads sessions.calendar from config.yml, default sessions are used when it's empty
+func LoadSessions() ([]*Session, error) {
+	items := cast.ToSlice(viper.Get("sessions.calendar"))
+	if len(items) == 0 {
+		return NewDefaultSessions(), nil
+	}
+
+	sessions := make([]*Session, 0, len(items))
+	for i, item := range items {
+		values := cast.ToStringMap(item)
+		session, err := newSession(values)
+		if err != nil {
+			return nil, fmt.Errorf("sessions.calendar[%d]: %s", i, err.Error())
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func newSession(values map[string]interface{}) (*Session, error) {
+	name := cast.ToString(values["name"])
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	location, err := time.LoadLocation(cast.ToString(values["timezone"]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone of %s: %s", name, err.Error())
+	}
+	start, err := parseTimeOfDay(cast.ToString(values["start"]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid start of %s: %s", name, err.Error())
+	}
+	end, err := parseTimeOfDay(cast.ToString(values["end"]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid end of %s: %s", name, err.Error())
+	}
+
+	weekdays := make(map[time.Weekday]bool, 7)
+	names := cast.ToStringSlice(values["weekdays"])
+	if len(names) == 0 {
+		names = []string{"mon", "tue", "wed", "thu", "fri"}
+	}
+	for _, weekdayName := range names {
+		weekday, ok := sessionWeekdays[strings.ToLower(weekdayName)[:min(3, len(weekdayName))]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %s of %s", weekdayName, name)
+		}
+		weekdays[weekday] = true
+	}
+
+	return &Session{Name: name, Location: location, Start: start, End: end, Weekdays: weekdays}, nil
+}
 
-func NewSessionsService(clock date.Clock) *SessionsService {
-	if sessionsServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
+// parseTimeOfDay parses 15:04 into time since midnight, 24:00 is the end of the day
+func parseTimeOfDay(value string) (time.Duration, error) {
+	if value == "24:00" {
+		return 24 * time.Hour, nil
 	}
-	sessionsServiceImpl = &SessionsService{
-		Clock: clock,
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
 	}
-	return sessionsServiceImpl
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
 }
 
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// LoadBlackouts reads csv file with name,from,to in RFC3339, lines starting with # are skipped
+func LoadBlackouts(path string) ([]*Blackout, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid blackout file %s: %s", path, err.Error())
+	}
+
+	blackouts := make([]*Blackout, 0, len(records))
+	for i, record := range records {
+		if i == 0 && record[0] == "name" {
+			continue
+		}
+		from, err := time.Parse(time.RFC3339, record[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid from of blackout %s: %s", record[0], err.Error())
+		}
+		to, err := time.Parse(time.RFC3339, record[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid to of blackout %s: %s", record[0], err.Error())
+		}
+		if !to.After(from) {
+			return nil, fmt.Errorf("blackout %s ends before it starts", record[0])
+		}
+		blackouts = append(blackouts, &Blackout{Name: record[0], From: from, To: to})
+	}
+	return blackouts, nil
+}
+
+// NewSessionsServiceFromConfig loads the calendar and the blackout file, sessionNames restricts sessions of the strategy
+func NewSessionsServiceFromConfig(clock date.Clock, sessionNames []string) (*SessionsService, error) {
+	sessions, err := LoadSessions()
+	if err != nil {
+		return nil, err
+	}
+	blackouts, err := LoadBlackouts(viper.GetString("sessions.blackoutFile"))
+	if err != nil {
+		return nil, err
+	}
+
+	service := NewSessionsService(clock, sessions, blackouts)
+	if len(sessionNames) > 0 {
+		return service.WithSessions(sessionNames)
+	}
+	return service, nil
+}
+
+func NewSessionsService(clock date.Clock, sessions []*Session, blackouts []*Blackout) *SessionsService {
+	return &SessionsService{
+		Clock:     clock,
+		sessions:  sessions,
+		blackouts: blackouts,
+	}
+}
+
+// SessionsService is trading calendar, trading is allowed in any of sessions outside of blackouts
 type SessionsService struct {
 	Clock date.Clock
+	/* Trading is allowed at any time when there are no sessions */
+	sessions  []*Session
+	blackouts []*Blackout
 }
 
-const LONDON_SESSION_START = 8
-const LONDON_SESSION_END = 17
+// WithSessions returns calendar with the named sessions only and the same blackouts
+func (s *SessionsService) WithSessions(names []string) (*SessionsService, error) {
+	sessions := make([]*Session, 0, len(names))
+	for _, name := range names {
+		session := s.findSession(name)
+		if session == nil {
+			return nil, fmt.Errorf("session %s isn't found in sessions.calendar", name)
+		}
+		sessions = append(sessions, session)
+	}
+	return NewSessionsService(s.Clock, sessions, s.blackouts), nil
+}
 
-const NEW_YORK_SESSION_START = 13
-const NEW_YORK_SESSION_END = 22
+func (s *SessionsService) findSession(name string) *Session {
+	for _, session := range s.sessions {
+		if strings.EqualFold(session.Name, name) {
+			return session
+		}
+	}
+	return nil
+}
 
-func (s *SessionsService) IsSuitableSessionNow() bool {
-	currentHour := s.Clock.NowTime().Hour()
+func (s *SessionsService) IsTradingAllowedNow() bool {
+	return s.IsTradingAllowedAt(s.Clock.NowTime())
+}
 
-	isLondonSession := currentHour >= LONDON_SESSION_START && currentHour < LONDON_SESSION_END
-	isNewYorkSession := currentHour >= NEW_YORK_SESSION_START && currentHour < NEW_YORK_SESSION_END
+func (s *SessionsService) IsTradingAllowedAt(moment time.Time) bool {
+	if s.FindBlackout(moment) != nil {
+		return false
+	}
+	if len(s.sessions) == 0 {
+		return true
+	}
+	for _, session := range s.sessions {
+		if session.Contains(moment) {
+			return true
+		}
+	}
+	return false
+}
 
-	return isLondonSession || isNewYorkSession
+// FindBlackout returns the blackout containing the moment or nil
+func (s *SessionsService) FindBlackout(moment time.Time) *Blackout {
+	for _, blackout := range s.blackouts {
+		if blackout.Contains(moment) {
+			return blackout
+		}
+	}
+	return nil
 }
@@ -7,17 +7,11 @@ import (
 	"github.com/sdcoffey/techan"
 )
 
-var smaTubeServiceImpl *SmaTubeService
-
 func NewSmaTubeService(clock date.Clock, klineRepo repository.Kline) *SmaTubeService {
-	if smaTubeServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	smaTubeServiceImpl = &SmaTubeService{
+	return &SmaTubeService{
 		klineRepo: klineRepo,
 		Clock:     clock,
 	}
-	return smaTubeServiceImpl
 }
 
 type SmaTubeService struct {
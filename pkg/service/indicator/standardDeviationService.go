@@ -13,18 +13,12 @@ import (
 	"go.uber.org/zap"
 )
 
-var standardDeviationServiceImpl *StandardDeviationService
-
 func NewStandardDeviationService(clock date.Clock, klineRepo repository.Kline, techanConvertorService *techanLib.TechanConvertorService) *StandardDeviationService {
-	if standardDeviationServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	standardDeviationServiceImpl = &StandardDeviationService{
+	return &StandardDeviationService{
 		klineRepo:              klineRepo,
 		Clock:                  clock,
 		TechanConvertorService: techanConvertorService,
 	}
-	return standardDeviationServiceImpl
 }
 
 type StandardDeviationService struct {
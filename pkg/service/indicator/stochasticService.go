@@ -11,18 +11,12 @@ import (
 	"github.com/sdcoffey/techan"
 )
 
-var stochasticServiceImpl *StochasticService
-
 func NewStochasticService(clock date.Clock, klineRepo repository.Kline, techanConvertorService *techanLib.TechanConvertorService) *StochasticService {
-	if stochasticServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	stochasticServiceImpl = &StochasticService{
+	return &StochasticService{
 		klineRepo:              klineRepo,
 		Clock:                  clock,
 		TechanConvertorService: techanConvertorService,
 	}
-	return stochasticServiceImpl
 }
 
 type StochasticService struct {
@@ -11,17 +11,11 @@ import (
 	"time"
 )
 
-var techanConvertorServiceImpl *TechanConvertorService
-
 func NewTechanConvertorService(clock date.Clock, klineRepo repository.Kline) *TechanConvertorService {
-	if techanConvertorServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	techanConvertorServiceImpl = &TechanConvertorService{
+	return &TechanConvertorService{
 		klineRepo: klineRepo,
 		Clock:     clock,
 	}
-	return techanConvertorServiceImpl
 }
 
 type TechanConvertorService struct {
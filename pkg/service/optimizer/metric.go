@@ -0,0 +1,41 @@
+package optimizer
+
+import (
+	"cryptoBot/pkg/service/report"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Metric scores a backtest, greater score is better
+type Metric func(backtestReport *report.BacktestReport) float64
+
+var metrics = map[string]Metric{
+	"netPnl":        func(r *report.BacktestReport) float64 { return r.NetPnl },
+	"return":        func(r *report.BacktestReport) float64 { return r.ReturnPercent },
+	"cagr":          func(r *report.BacktestReport) float64 { return r.Cagr },
+	"sharpe":        func(r *report.BacktestReport) float64 { return r.Sharpe },
+	"sortino":       func(r *report.BacktestReport) float64 { return r.Sortino },
+	"calmar":        func(r *report.BacktestReport) float64 { return r.Calmar },
+	"profitFactor":  func(r *report.BacktestReport) float64 { return r.ProfitFactor },
+	"winRate":       func(r *report.BacktestReport) float64 { return r.WinRate },
+	"expectancy":    func(r *report.BacktestReport) float64 { return r.Expectancy },
+	"maxDrawdown":   func(r *report.BacktestReport) float64 { return -r.MaxDrawdownPercent },
+	"drawdownHours": func(r *report.BacktestReport) float64 { return -r.MaxDrawdownHours },
+}
+
+// ParseMetric finds the metric ignoring case, drawdowns are negated so the smallest drawdown is ranked first
+func ParseMetric(name string) (Metric, error) {
+	for metricName, metric := range metrics {
+		if strings.EqualFold(metricName, name) {
+			return metric, nil
+		}
+	}
+
+	names := make([]string, 0, len(metrics))
+	for metricName := range metrics {
+		names = append(names, metricName)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("Unknown metric [%s], expected one of %s", name, strings.Join(names, ", "))
+}
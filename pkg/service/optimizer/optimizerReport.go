@@ -0,0 +1,89 @@
+package optimizer
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/service/report"
+	"fmt"
+	"strings"
+)
+
+// WalkForwardSummary compares in-sample and out-of-sample results of all windows
+type WalkForwardSummary struct {
+	Windows           int
+	ProfitableWindows int
+	InSampleScore     float64
+	OutOfSampleScore  float64
+	OutOfSampleNetPnl float64
+	/* Annualized out-of-sample return to annualized in-sample return, values far below 1 point to overfitting */
+	Efficiency float64
+}
+
+func NewWalkForwardSummary(windows []*WalkForwardWindow) *WalkForwardSummary {
+	summary := &WalkForwardSummary{Windows: len(windows)}
+	if len(windows) == 0 {
+		return summary
+	}
+
+	inSampleReturn, outOfSampleReturn := 0.0, 0.0
+	for _, window := range windows {
+		summary.InSampleScore += window.InSampleScore
+		summary.OutOfSampleScore += window.OutOfSampleScore
+		summary.OutOfSampleNetPnl += window.OutOfSample.NetPnl
+		if window.OutOfSample.NetPnl > 0 {
+			summary.ProfitableWindows++
+		}
+		inSampleReturn += annualizedReturn(window.InSample)
+		outOfSampleReturn += annualizedReturn(window.OutOfSample)
+	}
+	summary.InSampleScore /= float64(len(windows))
+	summary.OutOfSampleScore /= float64(len(windows))
+	if inSampleReturn != 0 {
+		summary.Efficiency = outOfSampleReturn / inSampleReturn
+	}
+	return summary
+}
+
+// annualizedReturn is simple, not compounded, short windows make compounded return explode
+func annualizedReturn(backtestReport *report.BacktestReport) float64 {
+	days := backtestReport.To.Sub(backtestReport.From).Hours() / 24
+	if days <= 0 {
+		return 0
+	}
+	return backtestReport.ReturnPercent * report.DAYS_IN_YEAR / days
+}
+
+// FormatResults prints top results of the grid search for the console
+func FormatResults(results []*OptimizationResult, ranges []*ParameterRange, metricName string, top int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "| Rank | %12s |    Net PnL | Return  | Sharpe | Max DD  | Trades | Win rate | Params\n", metricName)
+	fmt.Fprintf(&b, "|------|--------------|------------|---------|--------|---------|--------|----------|-------\n")
+	for i, result := range results {
+		if top > 0 && i >= top {
+			break
+		}
+		r := result.Report
+		fmt.Fprintf(&b, "| %4d | %12.4f | %10.2f | %6.2f%% | %6.2f | %6.2f%% | %6d | %7.2f%% | %s\n",
+			i+1, result.Score, r.NetPnl, r.ReturnPercent, r.Sharpe, r.MaxDrawdownPercent, r.Trades, r.WinRate, FormatParams(ranges, result.Params))
+	}
+	return b.String()
+}
+
+// FormatWalkForward prints every window and the summary for the console
+func FormatWalkForward(windows []*WalkForwardWindow, ranges []*ParameterRange, metricName string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "| In-sample               | Out-of-sample           | IS %-9s | OOS %-8s | IS net PnL | OOS net PnL | Params\n", metricName, metricName)
+	fmt.Fprintf(&b, "|-------------------------|-------------------------|--------------|--------------|------------|-------------|-------\n")
+	for _, window := range windows {
+		fmt.Fprintf(&b, "| %s - %s | %s - %s | %12.4f | %12.4f | %10.2f | %11.2f | %s\n",
+			window.InSampleFrom.Format(constants.DATE_FORMAT), window.InSampleTo.Format(constants.DATE_FORMAT),
+			window.OutOfSampleFrom.Format(constants.DATE_FORMAT), window.OutOfSampleTo.Format(constants.DATE_FORMAT),
+			window.InSampleScore, window.OutOfSampleScore, window.InSample.NetPnl, window.OutOfSample.NetPnl, FormatParams(ranges, window.Params))
+	}
+
+	summary := NewWalkForwardSummary(windows)
+	fmt.Fprintf(&b, "\nWindows: %d, profitable out-of-sample: %d\n", summary.Windows, summary.ProfitableWindows)
+	fmt.Fprintf(&b, "Average %s in-sample: %.4f, out-of-sample: %.4f\n", metricName, summary.InSampleScore, summary.OutOfSampleScore)
+	fmt.Fprintf(&b, "Out-of-sample net PnL: %.2f\n", summary.OutOfSampleNetPnl)
+	fmt.Fprintf(&b, "Walk-forward efficiency: %.2f\n", summary.Efficiency)
+	return b.String()
+}
@@ -0,0 +1,173 @@
+package optimizer
+
+import (
+	"cryptoBot/pkg/service/report"
+	"cryptoBot/pkg/service/trading"
+	"fmt"
+	"go.uber.org/zap"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Backtester runs the strategy in the period and builds its report, every call must use own repositories, clock and exchange
+type Backtester func(config *trading.StrategyConfig, from time.Time, to time.Time) (*report.BacktestReport, error)
+
+// OptimizationResult is one combination of the grid
+type OptimizationResult struct {
+	Params map[string]interface{}
+	Report *report.BacktestReport
+	Score  float64
+	Err    error
+}
+
+// WalkForwardWindow keeps the best in-sample params and their result on the following out-of-sample period
+type WalkForwardWindow struct {
+	InSampleFrom     time.Time
+	InSampleTo       time.Time
+	OutOfSampleFrom  time.Time
+	OutOfSampleTo    time.Time
+	Params           map[string]interface{}
+	InSample         *report.BacktestReport
+	OutOfSample      *report.BacktestReport
+	InSampleScore    float64
+	OutOfSampleScore float64
+}
+
+func NewOptimizerService(backtester Backtester, metric Metric, workers int) *OptimizerService {
+	if workers < 1 {
+		workers = 1
+	}
+	return &OptimizerService{
+		backtester: backtester,
+		metric:     metric,
+		workers:    workers,
+	}
+}
+
+// OptimizerService runs backtests of the parameter grid in parallel workers and ranks them by the metric
+type OptimizerService struct {
+	backtester Backtester
+	metric     Metric
+	workers    int
+}
+
+// Optimize returns successful results sorted by score, the best is the first. Failed combinations are logged and skipped
+func (s *OptimizerService) Optimize(config *trading.StrategyConfig, ranges []*ParameterRange, from time.Time, to time.Time) ([]*OptimizationResult, error) {
+	grid := BuildGrid(ranges)
+	results := make([]*OptimizationResult, len(grid))
+
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < s.workers && i < len(grid); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results[index] = s.run(config, grid[index], from, to)
+			}
+		}()
+	}
+
+	start := time.Now()
+	for i := range grid {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	zap.S().Infof("Optimized %s on %d combinations in %s", config.Key(), len(grid), time.Since(start))
+
+	succeeded := make([]*OptimizationResult, 0, len(results))
+	for _, result := range results {
+		if result.Err != nil {
+			zap.S().Errorf("Backtest %s [%s] failed: %s", config.Key(), FormatParams(ranges, result.Params), result.Err.Error())
+			continue
+		}
+		succeeded = append(succeeded, result)
+	}
+	if len(succeeded) == 0 {
+		return nil, fmt.Errorf("All %d backtests of %s failed", len(grid), config.Key())
+	}
+
+	// stable sort keeps the grid order of equal scores, so the same input gives the same ranking
+	sort.SliceStable(succeeded, func(i, j int) bool {
+		return succeeded[i].Score > succeeded[j].Score
+	})
+	return succeeded, nil
+}
+
+// WalkForward optimizes params on inSample period and validates the best of them on the next outOfSample period,
+// then both periods are moved by outOfSample. The last out-of-sample period is cut by to
+func (s *OptimizerService) WalkForward(config *trading.StrategyConfig, ranges []*ParameterRange, from time.Time, to time.Time, inSample time.Duration, outOfSample time.Duration) ([]*WalkForwardWindow, error) {
+	if inSample <= 0 || outOfSample <= 0 {
+		return nil, fmt.Errorf("In-sample and out-of-sample periods must be positive")
+	}
+
+	windows := make([]*WalkForwardWindow, 0)
+	for inSampleFrom := from; inSampleFrom.Add(inSample).Before(to); inSampleFrom = inSampleFrom.Add(outOfSample) {
+		window := &WalkForwardWindow{
+			InSampleFrom:    inSampleFrom,
+			InSampleTo:      inSampleFrom.Add(inSample),
+			OutOfSampleFrom: inSampleFrom.Add(inSample),
+			OutOfSampleTo:   inSampleFrom.Add(inSample).Add(outOfSample),
+		}
+		if window.OutOfSampleTo.After(to) {
+			window.OutOfSampleTo = to
+		}
+
+		results, err := s.Optimize(config, ranges, window.InSampleFrom, window.InSampleTo)
+		if err != nil {
+			return nil, err
+		}
+		best := results[0]
+		window.Params = best.Params
+		window.InSample = best.Report
+		window.InSampleScore = best.Score
+
+		validation := s.run(config, best.Params, window.OutOfSampleFrom, window.OutOfSampleTo)
+		if validation.Err != nil {
+			return nil, fmt.Errorf("Validation of %s [%s] failed: %s", config.Key(), FormatParams(ranges, best.Params), validation.Err.Error())
+		}
+		window.OutOfSample = validation.Report
+		window.OutOfSampleScore = validation.Score
+
+		windows = append(windows, window)
+	}
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("Period %s - %s is shorter than in-sample period %s", from, to, inSample)
+	}
+	return windows, nil
+}
+
+func (s *OptimizerService) run(config *trading.StrategyConfig, params map[string]interface{}, from time.Time, to time.Time) (result *OptimizationResult) {
+	result = &OptimizationResult{Params: params}
+	defer func() {
+		if r := recover(); r != nil {
+			zap.S().Errorf("Panic during backtest of %s: %v\n%s", config.Key(), r, debug.Stack())
+			result.Err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	backtestReport, err := s.backtester(WithParams(config, params), from, to)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Report = backtestReport
+	result.Score = s.metric(backtestReport)
+	return result
+}
+
+// WithParams copies the config, the params override params of the config
+func WithParams(config *trading.StrategyConfig, params map[string]interface{}) *trading.StrategyConfig {
+	copied := *config
+	copied.Params = make(map[string]interface{}, len(config.Params)+len(params))
+	for name, value := range config.Params {
+		copied.Params[name] = value
+	}
+	for name, value := range params {
+		copied.Params[name] = value
+	}
+	return &copied
+}
@@ -0,0 +1,120 @@
+package optimizer
+
+import (
+	"fmt"
+	"github.com/spf13/cast"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParameterRange keeps values of one strategy param tried by the grid search
+type ParameterRange struct {
+	Name   string
+	Values []interface{}
+}
+
+// NewParameterRanges parses optimize.ranges from config.yml, ranges are sorted by name so the grid is built in the same order every run
+func NewParameterRanges(items map[string]interface{}) ([]*ParameterRange, error) {
+	ranges := make([]*ParameterRange, 0, len(items))
+	for name, value := range items {
+		parameterRange, err := ParseParameterRange(name, value)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, parameterRange)
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Name < ranges[j].Name
+	})
+	return ranges, nil
+}
+
+// ParseParameterRange accepts 'start:end:step' for numbers, a list of values or a single value.
+// Values of the numeric range are integers when start, end and step are integers
+func ParseParameterRange(name string, value interface{}) (*ParameterRange, error) {
+	switch typedValue := value.(type) {
+	case []interface{}:
+		if len(typedValue) == 0 {
+			return nil, fmt.Errorf("Range of %s is empty", name)
+		}
+		return &ParameterRange{Name: name, Values: typedValue}, nil
+	case string:
+		if strings.Count(typedValue, ":") == 2 {
+			values, err := parseNumericRange(typedValue)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid range of %s [%s]: %s", name, typedValue, err.Error())
+			}
+			return &ParameterRange{Name: name, Values: values}, nil
+		}
+	}
+	return &ParameterRange{Name: name, Values: []interface{}{value}}, nil
+}
+
+func parseNumericRange(value string) ([]interface{}, error) {
+	parts := strings.Split(value, ":")
+	isInteger := true
+	numbers := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		number, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := strconv.Atoi(part); err != nil {
+			isInteger = false
+		}
+		numbers = append(numbers, number)
+	}
+
+	start, end, step := numbers[0], numbers[1], numbers[2]
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+	if end < start {
+		return nil, fmt.Errorf("end is less than start")
+	}
+
+	// the small epsilon keeps the end when (end - start) / step is not exact in floats, e.g. 0.1:0.3:0.1
+	count := int(math.Floor((end-start)/step+1e-9)) + 1
+	values := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		number := start + float64(i)*step
+		if isInteger {
+			values = append(values, int(math.Round(number)))
+		} else {
+			values = append(values, math.Round(number*1e8)/1e8)
+		}
+	}
+	return values, nil
+}
+
+// BuildGrid returns every combination of the range values
+func BuildGrid(ranges []*ParameterRange) []map[string]interface{} {
+	grid := []map[string]interface{}{{}}
+	for _, parameterRange := range ranges {
+		combinations := make([]map[string]interface{}, 0, len(grid)*len(parameterRange.Values))
+		for _, params := range grid {
+			for _, value := range parameterRange.Values {
+				combination := make(map[string]interface{}, len(params)+1)
+				for name, paramValue := range params {
+					combination[name] = paramValue
+				}
+				combination[parameterRange.Name] = value
+				combinations = append(combinations, combination)
+			}
+		}
+		grid = combinations
+	}
+	return grid
+}
+
+// FormatParams prints params in the order of the ranges, e.g. entryLength=20 exitLength=10
+func FormatParams(ranges []*ParameterRange, params map[string]interface{}) string {
+	parts := make([]string, 0, len(ranges))
+	for _, parameterRange := range ranges {
+		parts = append(parts, fmt.Sprintf("%s=%s", parameterRange.Name, cast.ToString(params[parameterRange.Name])))
+	}
+	return strings.Join(parts, " ")
+}
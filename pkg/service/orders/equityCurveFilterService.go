@@ -0,0 +1,189 @@
+package orders
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/data/dto/postgres/transaction"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/portfolio"
+	"go.uber.org/zap"
+	"time"
+)
+
+const EQUITY_CURVE_START = 100
+
+// EquityPoint is compounded equity after the closed trade, the curve starts from EQUITY_CURVE_START
+type EquityPoint struct {
+	Time   time.Time
+	Equity float64
+}
+
+// Equity curve filter places real orders while the shadow curve of all trades is healthy,
+// otherwise the strategy keeps trading by fake orders which aren't sent to the exchange
+func NewEquityCurveFilterService(transactionRepo repository.Transaction, clock date.Clock, maLength int, maxDrawdownPercent float64) *EquityCurveFilterService {
+	return &EquityCurveFilterService{
+		TransactionRepo:    transactionRepo,
+		Clock:              clock,
+		maLength:           maLength,
+		maxDrawdownPercent: maxDrawdownPercent,
+	}
+}
+
+type EquityCurveFilterService struct {
+	TransactionRepo repository.Transaction
+	Clock           date.Clock
+	/* Real orders are placed while the shadow equity is not below SMA of the last maLength points, 0 disables it */
+	maLength int
+	/* Real orders are placed while drawdown of the shadow equity from its peak is less, 0 disables it */
+	maxDrawdownPercent float64
+	/* Trades of the strategy instance, all trades of the trading strategy are used when it's nil */
+	scope *portfolio.TransactionScope
+}
+
+// SetScope builds the shadow curve by trades of one strategy instance, e.g. one pair of pair arbitrage
+func (s *EquityCurveFilterService) SetScope(scope portfolio.TransactionScope) {
+	s.scope = &scope
+}
+
+// AllowsRealOrders checks the shadow curve, real orders are allowed until the curve has maLength points
+func (s *EquityCurveFilterService) AllowsRealOrders(tradingStrategy constants.TradingStrategy) bool {
+	shadowCurve, err := s.buildFilterCurve(tradingStrategy)
+	if err != nil {
+		zap.S().Errorf("Error during BuildShadowCurve at %v: %s", s.Clock.NowTime(), err.Error())
+		return true
+	}
+
+	if s.maLength > 0 && len(shadowCurve) >= s.maLength {
+		if CalculateLastEquity(shadowCurve) < CalculateEquityMovingAverage(shadowCurve, s.maLength) {
+			zap.S().Infof("Shadow equity of %d is below its SMA(%d) at %v", tradingStrategy, s.maLength, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+			return false
+		}
+	}
+	if s.maxDrawdownPercent > 0 {
+		if drawdown := CalculateCurrentDrawdownPercent(shadowCurve); drawdown >= s.maxDrawdownPercent {
+			zap.S().Infof("Shadow drawdown %.2f%% of %d exceeds %.2f%% at %v", drawdown, tradingStrategy, s.maxDrawdownPercent, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+			return false
+		}
+	}
+	return true
+}
+
+func (s *EquityCurveFilterService) buildFilterCurve(tradingStrategy constants.TradingStrategy) ([]EquityPoint, error) {
+	if s.scope == nil {
+		return s.BuildShadowCurve(tradingStrategy)
+	}
+	now := s.Clock.NowTime()
+	profitPercents, err := s.scope.FindAllProfitPercents(s.TransactionRepo, time.Time{}, now, false)
+	if err != nil {
+		return nil, err
+	}
+	return BuildEquityCurve(profitPercents, now), nil
+}
+
+// BuildShadowCurve returns equity of all trades of the strategy, real and fake
+func (s *EquityCurveFilterService) BuildShadowCurve(tradingStrategy constants.TradingStrategy) ([]EquityPoint, error) {
+	profitPercents, err := s.TransactionRepo.FindAllProfitPercents(int(tradingStrategy))
+	if err != nil {
+		return nil, err
+	}
+	return BuildEquityCurve(profitPercents, s.Clock.NowTime()), nil
+}
+
+// BuildRealCurve returns equity of trades placed on the exchange
+func (s *EquityCurveFilterService) BuildRealCurve(tradingStrategy constants.TradingStrategy) ([]EquityPoint, error) {
+	profitPercents, err := s.TransactionRepo.FindAllRealProfitPercents(int(tradingStrategy))
+	if err != nil {
+		return nil, err
+	}
+	return BuildEquityCurve(profitPercents, s.Clock.NowTime()), nil
+}
+
+// BuildEquityCurve compounds profit percents of trades closed before now
+func BuildEquityCurve(profitPercents []transaction.TransactionProfitPercentsDto, now time.Time) []EquityPoint {
+	curve := make([]EquityPoint, 0, len(profitPercents))
+	equity := float64(EQUITY_CURVE_START)
+
+	for _, profitPercent := range profitPercents {
+		if profitPercent.CreatedAt.After(now) {
+			break
+		}
+		equity += equity * profitPercent.ProfitPercent / 100
+		curve = append(curve, EquityPoint{Time: profitPercent.CreatedAt, Equity: equity})
+	}
+	return curve
+}
+
+func CalculateLastEquity(curve []EquityPoint) float64 {
+	if len(curve) == 0 {
+		return EQUITY_CURVE_START
+	}
+	return curve[len(curve)-1].Equity
+}
+
+func CalculateEquityMovingAverage(curve []EquityPoint, length int) float64 {
+	if length > len(curve) {
+		length = len(curve)
+	}
+	if length == 0 {
+		return EQUITY_CURVE_START
+	}
+
+	sum := float64(0)
+	for _, point := range curve[len(curve)-length:] {
+		sum += point.Equity
+	}
+	return sum / float64(length)
+}
+
+// CalculateCurrentDrawdownPercent returns drawdown of the last point from the peak of the curve
+func CalculateCurrentDrawdownPercent(curve []EquityPoint) float64 {
+	peak := float64(EQUITY_CURVE_START)
+	for _, point := range curve {
+		if point.Equity > peak {
+			peak = point.Equity
+		}
+	}
+	return (peak - CalculateLastEquity(curve)) / peak * 100
+}
+
+// CalculateMaxDrawdownPercent returns the largest drawdown of the curve
+func CalculateMaxDrawdownPercent(curve []EquityPoint) float64 {
+	peak := float64(EQUITY_CURVE_START)
+	maxDrawdown := float64(0)
+	for _, point := range curve {
+		if point.Equity > peak {
+			peak = point.Equity
+		}
+		if drawdown := (peak - point.Equity) / peak * 100; drawdown > maxDrawdown {
+			maxDrawdown = drawdown
+		}
+	}
+	return maxDrawdown
+}
+
+// shadowOrderDto is fill of the fake order by the current price, commission is the same as of the exchange mock
+type shadowOrderDto struct {
+	price  float64
+	amount float64
+}
+
+func (d *shadowOrderDto) CalculateAvgPrice() float64 {
+	return d.price
+}
+
+func (d *shadowOrderDto) CalculateTotalCost() float64 {
+	return d.price * d.amount
+}
+
+func (d *shadowOrderDto) CalculateCommissionInUsd() float64 {
+	return d.CalculateTotalCost() * 0.00055
+}
+
+func (d *shadowOrderDto) GetAmount() float64 {
+	return d.amount
+}
+
+// GetCreatedAt returns nil, so transactions are created at the time of the clock
+func (d *shadowOrderDto) GetCreatedAt() *time.Time {
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"cryptoBot/pkg/repository"
 	"cryptoBot/pkg/service/date"
 	"cryptoBot/pkg/service/exchange"
+	"cryptoBot/pkg/service/portfolio"
 	"cryptoBot/pkg/util"
 	"database/sql"
 	"fmt"
@@ -17,18 +18,13 @@ import (
 	"time"
 )
 
-var orderManagerServiceImpl *OrderManagerService
-
 func NewOrderManagerService(transactionRepo repository.Transaction, exchangeApi api.ExchangeApi, clock date.Clock,
 	exchangeDataService *exchange.DataService, klineRepo repository.Kline, tradingStrategy constants.TradingStrategy,
 	priceChangeTrackingService *PriceChangeTrackingService,
 	profitLossFinderService *ProfitLossFinderService,
 	leverage int64, minProfitForBreakEven float64, closeToEntryForBreakEven float64,
 	minTrailingTakeProfitPercent float64, trailingTakeProfitPercent float64) *OrderManagerService {
-	if orderManagerServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	orderManagerServiceImpl = &OrderManagerService{
+	return &OrderManagerService{
 		klineRepo:                    klineRepo,
 		transactionRepo:              transactionRepo,
 		exchangeApi:                  exchangeApi,
@@ -43,7 +39,6 @@ func NewOrderManagerService(transactionRepo repository.Transaction, exchangeApi
 		trailingTakeProfitPercent:    trailingTakeProfitPercent,
 		ProfitLossFinderService:      profitLossFinderService,
 	}
-	return orderManagerServiceImpl
 }
 
 type OrderManagerService struct {
@@ -60,6 +55,23 @@ type OrderManagerService struct {
 	closeToEntryForBreakEven     float64
 	minTrailingTakeProfitPercent float64
 	trailingTakeProfitPercent    float64
+
+	/* Opened positions reserve capital of allocationKey, nil when portfolio allocation is disabled */
+	capitalAllocator *portfolio.CapitalAllocatorService
+	allocationKey    string
+	/* Orders are fake while the shadow equity curve is unhealthy, nil when the filter is disabled */
+	equityCurveFilter *EquityCurveFilterService
+}
+
+// SetEquityCurveFilter makes every order a shadow trade, only orders allowed by the filter are sent to the exchange
+func (s *OrderManagerService) SetEquityCurveFilter(equityCurveFilter *EquityCurveFilterService) {
+	s.equityCurveFilter = equityCurveFilter
+}
+
+// SetCapitalAllocator limits opened positions by capital allocated to the strategy instance
+func (s *OrderManagerService) SetCapitalAllocator(capitalAllocator *portfolio.CapitalAllocatorService, allocationKey string) {
+	s.capitalAllocator = capitalAllocator
+	s.allocationKey = allocationKey
 }
 
 func (s *OrderManagerService) SetFuturesLeverage(coin *domains.Coin, leverage int) error {
@@ -119,12 +131,18 @@ func (s *OrderManagerService) OpenFuturesOrderWithCostAndFixedStopLoss(coin *dom
 	s.openOrderWithCostAndFixedStopLossAndTakeProfit(coin, tradingKey, futuresType, stopLossPrice, 0, cost, constants.FUTURES, false)
 }
 
-func (s *OrderManagerService) OpenOrderWithCost(coin *domains.Coin, tradingKey string, futuresType futureType.FuturesType, cost float64, tradingType constants.TradingType) {
-	s.openOrderWithCostAndFixedStopLossAndTakeProfit(coin, tradingKey, futuresType, 0, 0, cost, tradingType, false)
+// OpenOrderWithCost returns saved open transaction or nil on error
+func (s *OrderManagerService) OpenOrderWithCost(coin *domains.Coin, tradingKey string, futuresType futureType.FuturesType, cost float64, tradingType constants.TradingType) *domains.Transaction {
+	return s.openOrderWithCostAndFixedStopLossAndTakeProfit(coin, tradingKey, futuresType, 0, 0, cost, tradingType, false)
+}
+
+func (s *OrderManagerService) OpenOrderWithCostAndFixedStopLossAndTakeProfit(coin *domains.Coin, tradingKey string, futuresType futureType.FuturesType,
+	cost float64, stopLossPrice float64, takeProfitPrice float64, tradingType constants.TradingType) *domains.Transaction {
+	return s.openOrderWithCostAndFixedStopLossAndTakeProfit(coin, tradingKey, futuresType, stopLossPrice, takeProfitPrice, cost, tradingType, false)
 }
 
 func (s *OrderManagerService) openOrderWithCostAndFixedStopLossAndTakeProfit(coin *domains.Coin, tradingKey string, futuresType futureType.FuturesType,
-	stopLossPrice float64, takeProfitPrice float64, cost float64, tradingType constants.TradingType, isFake bool) {
+	stopLossPrice float64, takeProfitPrice float64, cost float64, tradingType constants.TradingType, isFake bool) *domains.Transaction {
 	if stopLossPrice > 0 {
 		zap.S().Debugf("stopLossPrice %.2f  [%v]", stopLossPrice, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
 	}
@@ -135,12 +153,25 @@ func (s *OrderManagerService) openOrderWithCostAndFixedStopLossAndTakeProfit(coi
 	currentPrice, err := s.ExchangeDataService.GetCurrentPrice(coin)
 	if err != nil {
 		zap.S().Errorf("Error during GetCurrentCoinPrice at %v: %s", s.Clock.NowTime(), err.Error())
-		return
+		return nil
+	}
+
+	if s.equityCurveFilter != nil && !isFake {
+		isFake = !s.equityCurveFilter.AllowsRealOrders(s.tradingStrategy)
+	}
+	if s.capitalAllocator != nil && !isFake {
+		if err := s.capitalAllocator.CheckAvailable(s.allocationKey, cost); err != nil {
+			zap.S().Warnf("Order of %s is rejected at %v: %s", coin.Symbol, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT), err.Error())
+			return nil
+		}
 	}
 
 	amountTransaction := util.CalculateAmountByPriceAndCost(currentPrice, cost)
 	var orderDto api.OrderResponseDto
-	if tradingType == constants.FUTURES {
+	if isFake {
+		// shadow trade isn't sent to the exchange
+		orderDto = &shadowOrderDto{price: currentPrice, amount: amountTransaction}
+	} else if tradingType == constants.FUTURES {
 		orderDto, err = s.exchangeApi.OpenFuturesOrder(coin, amountTransaction, currentPrice, futuresType, stopLossPrice)
 	} else if tradingType == constants.SPOT {
 		orderDto, err = s.exchangeApi.BuyCoinByMarket(coin, amountTransaction, currentPrice)
@@ -148,17 +179,22 @@ func (s *OrderManagerService) openOrderWithCostAndFixedStopLossAndTakeProfit(coi
 	if err != nil {
 		zap.S().Errorf("Error during OpenFuturesOrder: %s", err.Error())
 		telegramApi.SendTextToTelegramChat(fmt.Sprintf("Error during OpenFuturesOrder: %s", err.Error()))
-		return
+		return nil
 	}
 
 	transaction := s.createOpenTransactionByOrderResponseDto(coin, tradingKey, futuresType, orderDto, stopLossPrice, takeProfitPrice, isFake)
 	if err3 := s.transactionRepo.SaveTransaction(&transaction); err3 != nil {
 		zap.S().Errorf("Error during SaveTransaction: %s", err3.Error())
-		return
+		return nil
+	}
+	if s.capitalAllocator != nil && !isFake {
+		s.capitalAllocator.Reserve(s.allocationKey, transaction.Id, transaction.TotalCost)
 	}
 
 	zap.S().Infof("at %s Order opened [%s] with price %v and type [%v] (0-L, 1-S)", s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT), coin.Symbol, currentPrice, futuresType)
 	//telegramApi.SendTextToTelegramChat(coin.Symbol + " " + transaction.String())
+
+	return &transaction
 }
 
 func (s *OrderManagerService) CloseCombinedOrder(openTransaction []*domains.Transaction, coin *domains.Coin, price float64, tradingType constants.TradingType) {
@@ -168,14 +204,27 @@ func (s *OrderManagerService) CloseCombinedOrder(openTransaction []*domains.Tran
 }
 
 func (s *OrderManagerService) CloseFuturesOrderWithCurrentPrice(coin *domains.Coin, openTransaction *domains.Transaction) *domains.Transaction {
+	return s.CloseFuturesOrderWithCurrentPriceAndReason(coin, openTransaction, constants.CLOSE_REASON_SIGNAL)
+}
+
+func (s *OrderManagerService) CloseFuturesOrderWithCurrentPriceAndReason(coin *domains.Coin, openTransaction *domains.Transaction, closeReason constants.CloseReason) *domains.Transaction {
 	currentPrice, _ := s.ExchangeDataService.GetCurrentPrice(coin)
-	return s.CloseOrder(openTransaction, coin, currentPrice, constants.FUTURES)
+	return s.CloseOrderWithReason(openTransaction, coin, currentPrice, constants.FUTURES, closeReason)
 }
 
 func (s *OrderManagerService) CloseOrder(openTransaction *domains.Transaction, coin *domains.Coin, price float64, tradingType constants.TradingType) *domains.Transaction {
+	return s.CloseOrderWithReason(openTransaction, coin, price, tradingType, constants.CLOSE_REASON_SIGNAL)
+}
+
+func (s *OrderManagerService) CloseOrderWithReason(openTransaction *domains.Transaction, coin *domains.Coin, price float64, tradingType constants.TradingType, closeReason constants.CloseReason) *domains.Transaction {
 	var orderResponseDto api.OrderResponseDto
 	var err error
-	if tradingType == constants.SPOT {
+	if openTransaction.IsFake {
+		if price <= 0 {
+			price, err = s.ExchangeDataService.GetCurrentPrice(coin)
+		}
+		orderResponseDto = &shadowOrderDto{price: price, amount: openTransaction.Amount}
+	} else if tradingType == constants.SPOT {
 		orderResponseDto, err = s.exchangeApi.SellCoinByMarket(coin, openTransaction.Amount, price)
 	} else if tradingType == constants.FUTURES {
 		orderResponseDto, err = s.exchangeApi.CloseFuturesOrder(coin, openTransaction, price)
@@ -186,7 +235,7 @@ func (s *OrderManagerService) CloseOrder(openTransaction *domains.Transaction, c
 		return nil
 	}
 
-	closeTransaction := s.createCloseTransactionByOrderResponseDto(coin, openTransaction, orderResponseDto)
+	closeTransaction := s.createCloseTransactionByOrderResponseDto(coin, openTransaction, orderResponseDto, closeReason)
 	if errT := s.transactionRepo.SaveTransaction(closeTransaction); errT != nil {
 		zap.S().Errorf("Error during SaveTransaction: %s", errT.Error())
 		return nil
@@ -194,6 +243,7 @@ func (s *OrderManagerService) CloseOrder(openTransaction *domains.Transaction, c
 
 	openTransaction.RelatedTransactionId = sql.NullInt64{Int64: closeTransaction.Id, Valid: true}
 	_ = s.transactionRepo.SaveTransaction(openTransaction)
+	s.releaseCapital(openTransaction)
 	//telegramApi.SendTextToTelegramChat(coin.Symbol + " " + closeTransaction.String())
 
 	return closeTransaction
@@ -237,7 +287,7 @@ func (s *OrderManagerService) createOpenTransactionByOrderResponseDto(coin *doma
 }
 
 func (s *OrderManagerService) createCloseTransactionByOrderResponseDto(coin *domains.Coin, openedTransaction *domains.Transaction,
-	orderDto api.OrderResponseDto) *domains.Transaction {
+	orderDto api.OrderResponseDto, closeReason constants.CloseReason) *domains.Transaction {
 
 	var buyCost float64
 	var sellCost float64
@@ -279,6 +329,7 @@ func (s *OrderManagerService) createCloseTransactionByOrderResponseDto(coin *dom
 		PercentProfit:        sql.NullFloat64{Float64: math.Round(percentProfit*100) / 100, Valid: true},
 		CreatedAt:            createdAt,
 		IsFake:               openedTransaction.IsFake,
+		CloseReason:          sql.NullString{String: string(closeReason), Valid: closeReason != ""},
 	}
 	return &transaction
 }
@@ -291,10 +342,37 @@ func (s *OrderManagerService) getCostOfOrder() float64 {
 	}
 
 	maxOrderCost := walletBalanceDto.GetAvailableBalanceInCents() * float64(s.leverage)
+	if s.capitalAllocator != nil {
+		available, err := s.capitalAllocator.GetAvailable(s.allocationKey)
+		if err != nil {
+			zap.S().Errorf("Error during GetAvailable at %v: %s", s.Clock.NowTime(), err.Error())
+			return 0
+		}
+		maxOrderCost = util.Min(maxOrderCost, available*float64(s.leverage))
+	}
 
 	return maxOrderCost
 }
 
+// GetAllocatedCapital returns capital of the strategy instance in USD, false when portfolio allocation is disabled
+func (s *OrderManagerService) GetAllocatedCapital() (float64, bool) {
+	if s.capitalAllocator == nil {
+		return 0, false
+	}
+	capital, err := s.capitalAllocator.GetCapital(s.allocationKey)
+	if err != nil {
+		zap.S().Errorf("Error during GetCapital at %v: %s", s.Clock.NowTime(), err.Error())
+		return 0, false
+	}
+	return capital, true
+}
+
+func (s *OrderManagerService) releaseCapital(openedTransaction *domains.Transaction) {
+	if s.capitalAllocator != nil {
+		s.capitalAllocator.Release(s.allocationKey, openedTransaction.Id)
+	}
+}
+
 func (s *OrderManagerService) CalculateCurrentProfitInPercentWithoutLeverage(coin *domains.Coin, openedTransaction *domains.Transaction) (float64, error) {
 	currentPrice, err := s.ExchangeDataService.GetCurrentPrice(coin)
 	if err != nil {
@@ -375,7 +453,7 @@ func (s *OrderManagerService) CreateCloseTransactionOnOrderClosedByExchange(coin
 		return nil
 	}
 
-	closeTransaction := s.createCloseTransactionByOrderResponseDto(coin, openedTransaction, closeTradeRecord)
+	closeTransaction := s.createCloseTransactionByOrderResponseDto(coin, openedTransaction, closeTradeRecord, constants.CLOSE_REASON_EXCHANGE)
 	if errT := s.transactionRepo.SaveTransaction(closeTransaction); errT != nil {
 		zap.S().Errorf("Error during SaveTransaction: %s", errT.Error())
 		return nil
@@ -383,6 +461,7 @@ func (s *OrderManagerService) CreateCloseTransactionOnOrderClosedByExchange(coin
 
 	openedTransaction.RelatedTransactionId = sql.NullInt64{Int64: closeTransaction.Id, Valid: true}
 	_ = s.transactionRepo.SaveTransaction(openedTransaction)
+	s.releaseCapital(openedTransaction)
 
 	return closeTransaction
 }
@@ -395,16 +474,17 @@ func (s *OrderManagerService) CloseOpenedOrderByStopLossIfNeeded(coin *domains.C
 }
 
 func (s *OrderManagerService) CloseOrderByFixedStopLossOrTakeProfit(coin *domains.Coin, openedOrder *domains.Transaction, klineInterval string) *domains.Transaction {
-	if isPositionOpened := s.ExchangeDataService.IsPositionOpened(coin, openedOrder); !isPositionOpened && openedOrder != nil {
+	// fake order has no position on the exchange
+	if openedOrder != nil && !openedOrder.IsFake && !s.ExchangeDataService.IsPositionOpened(coin, openedOrder) {
 		return s.CreateCloseTransactionOnOrderClosedByExchange(coin, openedOrder)
 	}
 
 	if s.ShouldCloseByStopLoss(openedOrder, klineInterval) {
-		return s.CloseOrder(openedOrder, coin, openedOrder.StopLossPrice.Float64, constants.FUTURES)
+		return s.CloseOrderWithReason(openedOrder, coin, openedOrder.StopLossPrice.Float64, constants.FUTURES, constants.CLOSE_REASON_STOP_LOSS)
 	}
 
 	if s.ShouldCloseByTakeProfit(openedOrder, klineInterval) {
-		return s.CloseOrder(openedOrder, coin, openedOrder.TakeProfitPrice.Float64, constants.FUTURES)
+		return s.CloseOrderWithReason(openedOrder, coin, openedOrder.TakeProfitPrice.Float64, constants.FUTURES, constants.CLOSE_REASON_TAKE_PROFIT)
 	}
 	return nil
 }
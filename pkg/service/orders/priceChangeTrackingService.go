@@ -6,16 +6,10 @@ import (
 	"cryptoBot/pkg/util"
 )
 
-var serviceImpl *PriceChangeTrackingService
-
 func NewPriceChangeTrackingService(priceChangeRepo repository.PriceChange) *PriceChangeTrackingService {
-	if serviceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	serviceImpl = &PriceChangeTrackingService{
+	return &PriceChangeTrackingService{
 		priceChangeRepo: priceChangeRepo,
 	}
-	return serviceImpl
 }
 
 type PriceChangeTrackingService struct {
@@ -11,17 +11,11 @@ import (
 	"time"
 )
 
-var profitLossFinderServiceImpl *ProfitLossFinderService
-
 func NewProfitLossFinderService(clock date.Clock, klineRepo repository.Kline) *ProfitLossFinderService {
-	if profitLossFinderServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	profitLossFinderServiceImpl = &ProfitLossFinderService{
+	return &ProfitLossFinderService{
 		klineRepo: klineRepo,
 		Clock:     clock,
 	}
-	return profitLossFinderServiceImpl
 }
 
 type ProfitLossFinderService struct {
@@ -19,16 +19,10 @@ import (
 	"time"
 )
 
-var bybitArchiveParseServiceImpl *BybitArchiveParseService
-
 func NewBybitArchiveParseService(klineRepo repository.Kline) *BybitArchiveParseService {
-	if bybitArchiveParseServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	bybitArchiveParseServiceImpl = &BybitArchiveParseService{
+	return &BybitArchiveParseService{
 		klineRepo: klineRepo,
 	}
-	return bybitArchiveParseServiceImpl
 }
 
 type BybitArchiveParseService struct {
@@ -0,0 +1,414 @@
+package portfolio
+
+import (
+	"cryptoBot/pkg/api"
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/util"
+	"fmt"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"sort"
+	"sync"
+	"time"
+)
+
+type AllocationMethod string
+
+const (
+	/* Weights from config of strategies */
+	ALLOCATION_FIXED AllocationMethod = "fixed"
+	/* Weights are inversely proportional to volatility of recent trade returns */
+	ALLOCATION_RISK_PARITY AllocationMethod = "riskParity"
+	/* Weights are proportional to positive Sharpe ratio of recent trade returns */
+	ALLOCATION_SHARPE AllocationMethod = "sharpe"
+)
+
+func ParseAllocationMethod(value string) (AllocationMethod, error) {
+	switch AllocationMethod(value) {
+	case "", ALLOCATION_FIXED:
+		return ALLOCATION_FIXED, nil
+	case ALLOCATION_RISK_PARITY, ALLOCATION_SHARPE:
+		return AllocationMethod(value), nil
+	}
+	return "", fmt.Errorf("unknown allocation method %s, expected fixed, riskParity or sharpe", value)
+}
+
+// Allocation is capital of one strategy instance in USD, weights of instances of the same account sum to 1
+type Allocation struct {
+	Key      string
+	Account  string
+	Weight   float64
+	Capital  float64
+	Reserved float64
+}
+
+func (a *Allocation) Available() float64 {
+	return a.Capital - a.Reserved
+}
+
+type participant struct {
+	key         string
+	account     string
+	exchangeApi api.ExchangeApi
+	scope       TransactionScope
+	fixedWeight float64
+	leverage    float64
+	/* Margin in USD reserved by opened transactions */
+	reserved map[int64]float64
+}
+
+// NewCapitalAllocatorServiceFromConfig reads portfolio section of config.yml, nil is returned when the allocator is disabled
+func NewCapitalAllocatorServiceFromConfig(transactionRepo repository.Transaction, clock date.Clock) (*CapitalAllocatorService, error) {
+	if !viper.GetBool("portfolio.enabled") {
+		return nil, nil
+	}
+
+	method, err := ParseAllocationMethod(viper.GetString("portfolio.method"))
+	if err != nil {
+		return nil, err
+	}
+
+	service := NewCapitalAllocatorService(transactionRepo, clock, method)
+	if viper.IsSet("portfolio.equityInCents") {
+		service.equityInCents = viper.GetInt64("portfolio.equityInCents")
+	}
+	if viper.IsSet("portfolio.lookbackDays") {
+		service.lookbackDays = viper.GetInt("portfolio.lookbackDays")
+	}
+	if viper.IsSet("portfolio.minTrades") {
+		service.minTrades = viper.GetInt("portfolio.minTrades")
+	}
+	if viper.IsSet("portfolio.rebalanceHours") {
+		service.rebalanceHours = viper.GetInt("portfolio.rebalanceHours")
+	}
+	return service, nil
+}
+
+// Capital allocator splits equity of every account across strategy instances trading on it, every instance can hold
+// margin of opened positions up to its share only
+func NewCapitalAllocatorService(transactionRepo repository.Transaction, clock date.Clock, method AllocationMethod) *CapitalAllocatorService {
+	return &CapitalAllocatorService{
+		TransactionRepo: transactionRepo,
+		Clock:           clock,
+		method:          method,
+		equityInCents:   0, //wallet balance
+		lookbackDays:    30,
+		minTrades:       5,
+		rebalanceHours:  24,
+		participants:    make(map[string]*participant),
+	}
+}
+
+type CapitalAllocatorService struct {
+	TransactionRepo repository.Transaction
+	Clock           date.Clock
+	method          AllocationMethod
+	/* Fixed equity of every account, wallet balance with reserved margin is used when it's 0 */
+	equityInCents int64
+	/* Closed trades of the period are used by riskParity and sharpe */
+	lookbackDays int
+	/* Instance with fewer closed trades gets the average weight */
+	minTrades      int
+	rebalanceHours int
+
+	mutex        sync.Mutex
+	participants map[string]*participant
+	weights      map[string]float64
+	rebalancedAt time.Time
+}
+
+// Register adds strategy instance trading on the account or replaces it, margin of opened transactions of its scope is reserved again
+func (s *CapitalAllocatorService) Register(key string, account string, exchangeApi api.ExchangeApi, scope TransactionScope, weight float64, leverage int) {
+	if leverage < 1 {
+		leverage = 1
+	}
+	p := &participant{
+		key:         key,
+		account:     account,
+		exchangeApi: exchangeApi,
+		scope:       scope,
+		fixedWeight: weight,
+		leverage:    float64(leverage),
+		reserved:    make(map[int64]float64),
+	}
+
+	openedTransactions, err := scope.FindAllOpenedTransactions(s.TransactionRepo)
+	if err != nil {
+		zap.S().Errorf("Error during FindAllOpenedTransactions of %s: %s", key, err.Error())
+	}
+	for _, transaction := range openedTransactions {
+		if transaction.IsFake {
+			continue
+		}
+		p.reserved[transaction.Id] = transaction.TotalCost / p.leverage
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.participants[key] = p
+	s.weights = nil
+}
+
+func (s *CapitalAllocatorService) Unregister(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.participants, key)
+	s.weights = nil
+}
+
+// Registration is the registered instance saved before its replacement is built, so it's restored when the replacement fails
+type Registration struct {
+	key         string
+	participant *participant
+}
+
+// FindRegistration returns current registration of the key, it's empty when the key isn't registered
+func (s *CapitalAllocatorService) FindRegistration(key string) *Registration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return &Registration{key: key, participant: s.participants[key]}
+}
+
+// Restore brings back the saved registration, the key is unregistered when it wasn't registered before
+func (s *CapitalAllocatorService) Restore(registration *Registration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if registration.participant == nil {
+		delete(s.participants, registration.key)
+	} else {
+		s.participants[registration.key] = registration.participant
+	}
+	s.weights = nil
+}
+
+// CheckAvailable returns error when the order of cost in USD exceeds free capital of the instance
+func (s *CapitalAllocatorService) CheckAvailable(key string, cost float64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	allocation, err := s.getAllocation(key)
+	if err != nil {
+		return err
+	}
+	p := s.participants[key]
+	if margin := cost / p.leverage; margin > allocation.Available() {
+		return fmt.Errorf("margin %.2f of %s exceeds available capital %.2f of %.2f", margin, key, allocation.Available(), allocation.Capital)
+	}
+	return nil
+}
+
+// Reserve holds margin of the opened transaction until Release
+func (s *CapitalAllocatorService) Reserve(key string, transactionId int64, cost float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if p, ok := s.participants[key]; ok {
+		p.reserved[transactionId] = cost / p.leverage
+	}
+}
+
+func (s *CapitalAllocatorService) Release(key string, transactionId int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if p, ok := s.participants[key]; ok {
+		delete(p.reserved, transactionId)
+	}
+}
+
+// GetAvailable returns free capital of the instance in USD
+func (s *CapitalAllocatorService) GetAvailable(key string) (float64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	allocation, err := s.getAllocation(key)
+	if err != nil {
+		return 0, err
+	}
+	return allocation.Available(), nil
+}
+
+// GetCapital returns capital of the instance in USD including reserved margin
+func (s *CapitalAllocatorService) GetCapital(key string) (float64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	allocation, err := s.getAllocation(key)
+	if err != nil {
+		return 0, err
+	}
+	return allocation.Capital, nil
+}
+
+// Allocations returns capital of all instances sorted by key
+func (s *CapitalAllocatorService) Allocations() ([]*Allocation, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	allocations := make([]*Allocation, 0, len(s.participants))
+	for key := range s.participants {
+		allocation, err := s.getAllocation(key)
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, allocation)
+	}
+	sort.Slice(allocations, func(i, j int) bool {
+		return allocations[i].Key < allocations[j].Key
+	})
+	return allocations, nil
+}
+
+func (s *CapitalAllocatorService) getAllocation(key string) (*Allocation, error) {
+	p, ok := s.participants[key]
+	if !ok {
+		return nil, fmt.Errorf("strategy %s isn't registered in capital allocator", key)
+	}
+
+	equity, err := s.getEquity(p)
+	if err != nil {
+		return nil, err
+	}
+	weight := s.getWeights()[key]
+
+	return &Allocation{
+		Key:      key,
+		Account:  p.account,
+		Weight:   weight,
+		Capital:  equity * weight,
+		Reserved: util.SumFloat64(reservedValues(p)),
+	}, nil
+}
+
+// getEquity returns equity of the account of the instance in USD, available balance of the wallet doesn't include
+// margin of opened positions, margin of the transaction is counted once even if scopes of instances overlap
+func (s *CapitalAllocatorService) getEquity(p *participant) (float64, error) {
+	if s.equityInCents > 0 {
+		return util.GetDollarsByCents(s.equityInCents), nil
+	}
+
+	walletBalance, err := p.exchangeApi.GetWalletBalance()
+	if err != nil {
+		return 0, fmt.Errorf("Error during GetWalletBalance of %s: %s", p.account, err.Error())
+	}
+	equity := walletBalance.GetAvailableBalanceInCents()
+	reserved := make(map[int64]float64)
+	for _, other := range s.participants {
+		if other.account != p.account {
+			continue
+		}
+		for transactionId, margin := range other.reserved {
+			reserved[transactionId] = margin
+		}
+	}
+	for _, margin := range reserved {
+		equity += margin
+	}
+	return equity, nil
+}
+
+// getWeights returns normalized weights, they are recalculated every rebalanceHours
+func (s *CapitalAllocatorService) getWeights() map[string]float64 {
+	now := s.Clock.NowTime()
+	if s.weights != nil && now.Sub(s.rebalancedAt) < time.Duration(s.rebalanceHours)*time.Hour {
+		return s.weights
+	}
+
+	// equity of the account is split between its instances only
+	accountScores := make(map[string]map[string]float64)
+	for key, p := range s.participants {
+		if accountScores[p.account] == nil {
+			accountScores[p.account] = make(map[string]float64)
+		}
+		accountScores[p.account][key] = s.calculateScore(p, now)
+	}
+
+	weights := make(map[string]float64, len(s.participants))
+	for _, scores := range accountScores {
+		fillMissingScores(scores)
+
+		sum := float64(0)
+		for _, score := range scores {
+			sum += score
+		}
+		for key, score := range scores {
+			if sum > 0 {
+				weights[key] = score / sum
+			} else {
+				weights[key] = 1 / float64(len(scores))
+			}
+		}
+	}
+
+	s.weights = weights
+	s.rebalancedAt = now
+	zap.S().Infof("Capital is rebalanced by %s at %s: %v", s.method, now.Format(constants.DATE_TIME_FORMAT), weights)
+	return weights
+}
+
+// calculateScore returns not normalized weight by trades of the instance scope, negative score means there are not enough trades
+func (s *CapitalAllocatorService) calculateScore(p *participant, now time.Time) float64 {
+	if s.method == ALLOCATION_FIXED {
+		return p.fixedWeight
+	}
+
+	profitPercents, err := p.scope.FindAllProfitPercents(s.TransactionRepo, now.AddDate(0, 0, -s.lookbackDays), now, false)
+	if err != nil {
+		zap.S().Errorf("Error during FindAllProfitPercents of %s: %s", p.key, err.Error())
+		return -1
+	}
+	returns := make([]float64, 0, len(profitPercents))
+	for _, profitPercent := range profitPercents {
+		returns = append(returns, profitPercent.ProfitPercent)
+	}
+	if len(returns) < s.minTrades || len(returns) < 2 {
+		return -1
+	}
+
+	deviation := util.StandardDeviation(returns)
+	if deviation == 0 {
+		return -1
+	}
+	if s.method == ALLOCATION_RISK_PARITY {
+		return 1 / deviation
+	}
+	// losing strategies get no capital until their recent trades recover
+	sharpe := util.SumFloat64(returns) / float64(len(returns)) / deviation
+	return util.Max(sharpe, 0)
+}
+
+// fillMissingScores gives instances without enough trades the average score of the others
+func fillMissingScores(scores map[string]float64) {
+	sum := float64(0)
+	count := 0
+	for _, score := range scores {
+		if score >= 0 {
+			sum += score
+			count++
+		}
+	}
+
+	average := float64(1)
+	if count > 0 && sum > 0 {
+		average = sum / float64(count)
+	}
+	for key, score := range scores {
+		if score < 0 {
+			scores[key] = average
+		}
+	}
+}
+
+func reservedValues(p *participant) []float64 {
+	values := make([]float64, 0, len(p.reserved))
+	for _, value := range p.reserved {
+		values = append(values, value)
+	}
+	return values
+}
@@ -0,0 +1,84 @@
+package portfolio
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/data/dto/postgres/transaction"
+	"cryptoBot/pkg/repository"
+	"strings"
+	"time"
+)
+
+// TransactionScope selects transactions of one strategy instance, instances of the same trading strategy id
+// are told apart by coins and trading key, e.g. every pair of pair arbitrage is saved with PAIR_ARBITRAGE
+type TransactionScope struct {
+	TradingStrategy constants.TradingStrategy
+	CoinIds         []int64
+	/* Trading keys of the instance start with the prefix, empty prefix matches every key */
+	TradingKeyPrefix string
+}
+
+func NewTransactionScope(tradingStrategy constants.TradingStrategy, coins []*domains.Coin, tradingKeyPrefix string) TransactionScope {
+	coinIds := make([]int64, 0, len(coins))
+	for _, coin := range coins {
+		coinIds = append(coinIds, coin.Id)
+	}
+	return TransactionScope{
+		TradingStrategy:  tradingStrategy,
+		CoinIds:          coinIds,
+		TradingKeyPrefix: tradingKeyPrefix,
+	}
+}
+
+func (s TransactionScope) Contains(t *domains.Transaction) bool {
+	if t.TradingStrategy != s.TradingStrategy || !strings.HasPrefix(t.TradingKey, s.TradingKeyPrefix) {
+		return false
+	}
+	for _, coinId := range s.CoinIds {
+		if t.CoinId == coinId {
+			return true
+		}
+	}
+	return false
+}
+
+// FindAllOpenedTransactions returns opened transactions of the instance
+func (s TransactionScope) FindAllOpenedTransactions(transactionRepo repository.Transaction) ([]*domains.Transaction, error) {
+	openedTransactions, err := transactionRepo.FindAllOpenedTransactions(s.TradingStrategy)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*domains.Transaction, 0, len(openedTransactions))
+	for _, openedTransaction := range openedTransactions {
+		if s.Contains(openedTransaction) {
+			result = append(result, openedTransaction)
+		}
+	}
+	return result, nil
+}
+
+// FindAllProfitPercents sums percents of closed transactions of the instance grouped by created_at ascending as
+// FindAllProfitPercents of the repository, fake transactions are skipped when onlyReal is set
+func (s TransactionScope) FindAllProfitPercents(transactionRepo repository.Transaction, from time.Time, to time.Time, onlyReal bool) ([]transaction.TransactionProfitPercentsDto, error) {
+	transactions, err := transactionRepo.FindAllByTradingStrategyAndCreatedAtInRange(s.TradingStrategy, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	profitPercents := make([]transaction.TransactionProfitPercentsDto, 0)
+	for _, t := range transactions {
+		if !t.Profit.Valid || (onlyReal && t.IsFake) || !s.Contains(t) {
+			continue
+		}
+		last := len(profitPercents) - 1
+		if last >= 0 && profitPercents[last].CreatedAt.Equal(t.CreatedAt) {
+			profitPercents[last].ProfitPercent += t.PercentProfit.Float64
+			continue
+		}
+		profitPercents = append(profitPercents, transaction.TransactionProfitPercentsDto{
+			CreatedAt:     t.CreatedAt,
+			ProfitPercent: t.PercentProfit.Float64,
+		})
+	}
+	return profitPercents, nil
+}
@@ -0,0 +1,349 @@
+package report
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const DAYS_IN_YEAR = 365
+
+// BacktestTrade is a closed position, amounts are in USD
+type BacktestTrade struct {
+	Symbol        string    `json:"symbol"`
+	TradingKey    string    `json:"tradingKey"`
+	FuturesType   string    `json:"futuresType"`
+	OpenedAt      time.Time `json:"openedAt"`
+	ClosedAt      time.Time `json:"closedAt"`
+	OpenPrice     float64   `json:"openPrice"`
+	ClosePrice    float64   `json:"closePrice"`
+	Cost          float64   `json:"cost"`
+	GrossPnl      float64   `json:"grossPnl"`
+	Fees          float64   `json:"fees"`
+	NetPnl        float64   `json:"netPnl"`
+	ProfitPercent float64   `json:"profitPercent"`
+	CloseReason   string    `json:"closeReason,omitempty"`
+}
+
+func (t *BacktestTrade) HoldingTime() time.Duration {
+	return t.ClosedAt.Sub(t.OpenedAt)
+}
+
+// BacktestBreakdown is statistic of trades of one coin or one trading key
+type BacktestBreakdown struct {
+	Key      string  `json:"key"`
+	Trades   int     `json:"trades"`
+	WinRate  float64 `json:"winRate"`
+	GrossPnl float64 `json:"grossPnl"`
+	Fees     float64 `json:"fees"`
+	NetPnl   float64 `json:"netPnl"`
+	/* 0 when there are no losing trades */
+	ProfitFactor float64 `json:"profitFactor"`
+}
+
+type BacktestEquityPoint struct {
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
+}
+
+// BacktestReport is performance of one backtest run, ratios are 0 when they can't be calculated
+type BacktestReport struct {
+	Name            string    `json:"name"`
+	TradingStrategy int       `json:"tradingStrategy"`
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+	InitialCapital  float64   `json:"initialCapital"`
+	FinalEquity     float64   `json:"finalEquity"`
+
+	NetPnl        float64 `json:"netPnl"`
+	GrossPnl      float64 `json:"grossPnl"`
+	Fees          float64 `json:"fees"`
+	ReturnPercent float64 `json:"returnPercent"`
+
+	/* Percent per year */
+	Cagr float64 `json:"cagr"`
+	/* Annualized by daily returns */
+	Sharpe  float64 `json:"sharpe"`
+	Sortino float64 `json:"sortino"`
+	Calmar  float64 `json:"calmar"`
+
+	MaxDrawdownPercent float64 `json:"maxDrawdownPercent"`
+	/* The longest time below the previous peak */
+	MaxDrawdownHours float64 `json:"maxDrawdownHours"`
+
+	Trades  int     `json:"tradesCount"`
+	Wins    int     `json:"wins"`
+	Losses  int     `json:"losses"`
+	WinRate float64 `json:"winRate"`
+	/* Gross profit of winners to gross loss of losers, 0 when there are no losing trades */
+	ProfitFactor float64 `json:"profitFactor"`
+	/* Average net pnl of a trade */
+	Expectancy      float64 `json:"expectancy"`
+	AvgHoldingHours float64 `json:"avgHoldingHours"`
+	/* Percent of the period with at least one opened position */
+	ExposurePercent float64 `json:"exposurePercent"`
+	/* Positions which are still open at the end of the period, they aren't included in pnl */
+	OpenPositions int `json:"openPositions"`
+
+	ByCoin       []*BacktestBreakdown  `json:"byCoin"`
+	ByTradingKey []*BacktestBreakdown  `json:"byTradingKey"`
+	Equity       []BacktestEquityPoint `json:"equity"`
+	TradeList    []*BacktestTrade      `json:"trades"`
+	/* Buy-and-hold of the same period and capital, added by BenchmarkService */
+	Benchmarks []*BacktestBenchmark `json:"benchmarks,omitempty"`
+}
+
+// NewBacktestReport calculates metrics of closed trades, openedAt are times of positions still opened at the end
+func NewBacktestReport(name string, tradingStrategy int, from time.Time, to time.Time, initialCapital float64,
+	trades []*BacktestTrade, openedAt []time.Time) *BacktestReport {
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].ClosedAt.Before(trades[j].ClosedAt)
+	})
+
+	report := &BacktestReport{
+		Name:            name,
+		TradingStrategy: tradingStrategy,
+		From:            from,
+		To:              to,
+		InitialCapital:  initialCapital,
+		Trades:          len(trades),
+		OpenPositions:   len(openedAt),
+		TradeList:       trades,
+	}
+
+	grossProfit, grossLoss := float64(0), float64(0)
+	holdingTime := time.Duration(0)
+	for _, trade := range trades {
+		report.NetPnl += trade.NetPnl
+		report.GrossPnl += trade.GrossPnl
+		report.Fees += trade.Fees
+		holdingTime += trade.HoldingTime()
+		if trade.NetPnl > 0 {
+			report.Wins++
+			grossProfit += trade.NetPnl
+		} else {
+			report.Losses++
+			grossLoss -= trade.NetPnl
+		}
+	}
+	report.FinalEquity = initialCapital + report.NetPnl
+	report.ReturnPercent = percentOf(report.NetPnl, initialCapital)
+	report.ProfitFactor = divide(grossProfit, grossLoss)
+	if len(trades) > 0 {
+		report.WinRate = float64(report.Wins) / float64(len(trades)) * 100
+		report.Expectancy = report.NetPnl / float64(len(trades))
+		report.AvgHoldingHours = holdingTime.Hours() / float64(len(trades))
+	}
+
+	report.Equity = BuildEquity(initialCapital, from, trades)
+	report.MaxDrawdownPercent, report.MaxDrawdownHours = CalculateMaxDrawdown(report.Equity, to)
+	report.Cagr = CalculateCagr(initialCapital, report.FinalEquity, to.Sub(from))
+	report.Calmar = divide(report.Cagr, report.MaxDrawdownPercent)
+	dailyReturns := CalculateDailyReturns(initialCapital, from, to, trades)
+	report.Sharpe = CalculateSharpe(dailyReturns)
+	report.Sortino = CalculateSortino(dailyReturns)
+	report.ExposurePercent = CalculateExposurePercent(from, to, trades, openedAt)
+
+	report.ByCoin = buildBreakdowns(trades, func(trade *BacktestTrade) string { return trade.Symbol })
+	report.ByTradingKey = buildBreakdowns(trades, func(trade *BacktestTrade) string { return trade.TradingKey })
+	return report
+}
+
+// BuildEquity returns equity after every closed trade, the first point is the initial capital
+func BuildEquity(initialCapital float64, from time.Time, trades []*BacktestTrade) []BacktestEquityPoint {
+	equity := make([]BacktestEquityPoint, 0, len(trades)+1)
+	equity = append(equity, BacktestEquityPoint{Time: from, Equity: initialCapital})
+	for _, trade := range trades {
+		initialCapital += trade.NetPnl
+		equity = append(equity, BacktestEquityPoint{Time: trade.ClosedAt, Equity: initialCapital})
+	}
+	return equity
+}
+
+// CalculateMaxDrawdown returns the largest drawdown in percent and the longest time in hours below the previous peak
+func CalculateMaxDrawdown(equity []BacktestEquityPoint, to time.Time) (float64, float64) {
+	if len(equity) == 0 {
+		return 0, 0
+	}
+
+	peak := equity[0]
+	isInDrawdown := false
+	maxDrawdown := float64(0)
+	maxDuration := time.Duration(0)
+	for _, point := range equity {
+		if point.Equity >= peak.Equity {
+			if isInDrawdown && point.Time.Sub(peak.Time) > maxDuration {
+				maxDuration = point.Time.Sub(peak.Time)
+			}
+			peak = point
+			isInDrawdown = false
+			continue
+		}
+		isInDrawdown = true
+		if drawdown := percentOf(peak.Equity-point.Equity, peak.Equity); drawdown > maxDrawdown {
+			maxDrawdown = drawdown
+		}
+	}
+	// drawdown which isn't recovered until the end of the period
+	if isInDrawdown && to.Sub(peak.Time) > maxDuration {
+		maxDuration = to.Sub(peak.Time)
+	}
+	return maxDrawdown, maxDuration.Hours()
+}
+
+// CalculateCagr returns compound annual growth rate in percent
+func CalculateCagr(initialCapital float64, finalEquity float64, period time.Duration) float64 {
+	years := period.Hours() / 24 / DAYS_IN_YEAR
+	if initialCapital <= 0 || years <= 0 {
+		return 0
+	}
+	if finalEquity <= 0 {
+		return -100
+	}
+	return (math.Pow(finalEquity/initialCapital, 1/years) - 1) * 100
+}
+
+// CalculateDailyReturns returns returns of equity at the end of every day of the period, pnl is realized on close
+func CalculateDailyReturns(initialCapital float64, from time.Time, to time.Time, trades []*BacktestTrade) []float64 {
+	days := int(math.Ceil(to.Sub(from).Hours() / 24))
+	returns := make([]float64, 0, days)
+	equity := initialCapital
+	i := 0
+	for day := 1; day <= days; day++ {
+		dayEnd := from.AddDate(0, 0, day)
+		previous := equity
+		for ; i < len(trades) && trades[i].ClosedAt.Before(dayEnd); i++ {
+			equity += trades[i].NetPnl
+		}
+		if previous <= 0 {
+			break
+		}
+		returns = append(returns, equity/previous-1)
+	}
+	return returns
+}
+
+func CalculateSharpe(returns []float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+	return divide(mean(returns), standardDeviation(returns)) * math.Sqrt(DAYS_IN_YEAR)
+}
+
+// CalculateSortino is Sharpe with deviation of negative returns only
+func CalculateSortino(returns []float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+	downside := float64(0)
+	for _, value := range returns {
+		if value < 0 {
+			downside += value * value
+		}
+	}
+	return divide(mean(returns), math.Sqrt(downside/float64(len(returns)))) * math.Sqrt(DAYS_IN_YEAR)
+}
+
+// CalculateExposurePercent returns percent of the period when at least one position was opened
+func CalculateExposurePercent(from time.Time, to time.Time, trades []*BacktestTrade, openedAt []time.Time) float64 {
+	type interval struct {
+		start time.Time
+		end   time.Time
+	}
+	intervals := make([]interval, 0, len(trades)+len(openedAt))
+	for _, trade := range trades {
+		intervals = append(intervals, interval{start: trade.OpenedAt, end: trade.ClosedAt})
+	}
+	for _, opened := range openedAt {
+		intervals = append(intervals, interval{start: opened, end: to})
+	}
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start.Before(intervals[j].start)
+	})
+
+	exposure := time.Duration(0)
+	var current *interval
+	for i := range intervals {
+		if current != nil && !intervals[i].start.After(current.end) {
+			if intervals[i].end.After(current.end) {
+				current.end = intervals[i].end
+			}
+			continue
+		}
+		if current != nil {
+			exposure += current.end.Sub(current.start)
+		}
+		current = &intervals[i]
+	}
+	if current != nil {
+		exposure += current.end.Sub(current.start)
+	}
+	return percentOf(exposure.Hours(), to.Sub(from).Hours())
+}
+
+func buildBreakdowns(trades []*BacktestTrade, keyOf func(trade *BacktestTrade) string) []*BacktestBreakdown {
+	byKey := make(map[string]*BacktestBreakdown)
+	grossProfits := make(map[string]float64)
+	grossLosses := make(map[string]float64)
+	wins := make(map[string]int)
+
+	for _, trade := range trades {
+		key := keyOf(trade)
+		breakdown, ok := byKey[key]
+		if !ok {
+			breakdown = &BacktestBreakdown{Key: key}
+			byKey[key] = breakdown
+		}
+		breakdown.Trades++
+		breakdown.GrossPnl += trade.GrossPnl
+		breakdown.Fees += trade.Fees
+		breakdown.NetPnl += trade.NetPnl
+		if trade.NetPnl > 0 {
+			wins[key]++
+			grossProfits[key] += trade.NetPnl
+		} else {
+			grossLosses[key] -= trade.NetPnl
+		}
+	}
+
+	breakdowns := make([]*BacktestBreakdown, 0, len(byKey))
+	for key, breakdown := range byKey {
+		breakdown.WinRate = float64(wins[key]) / float64(breakdown.Trades) * 100
+		breakdown.ProfitFactor = divide(grossProfits[key], grossLosses[key])
+		breakdowns = append(breakdowns, breakdown)
+	}
+	sort.Slice(breakdowns, func(i, j int) bool {
+		return breakdowns[i].NetPnl > breakdowns[j].NetPnl
+	})
+	return breakdowns
+}
+
+func mean(values []float64) float64 {
+	sum := float64(0)
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// standardDeviation is sample deviation of returns
+func standardDeviation(values []float64) float64 {
+	average := mean(values)
+	sum := float64(0)
+	for _, value := range values {
+		sum += (value - average) * (value - average)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+func percentOf(value float64, base float64) float64 {
+	return divide(value, base) * 100
+}
+
+// divide returns 0 instead of infinity, json can't encode it
+func divide(value float64, divisor float64) float64 {
+	if divisor == 0 {
+		return 0
+	}
+	return value / divisor
+}
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/constants/futureType"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/util"
+	"fmt"
+	"time"
+)
+
+func NewBacktestReportService(transactionRepo repository.Transaction, coinRepo repository.Coin) *BacktestReportService {
+	return &BacktestReportService{
+		transactionRepo: transactionRepo,
+		coinRepo:        coinRepo,
+	}
+}
+
+// BacktestReportService builds report of transactions saved by the trading strategy during the backtest period
+type BacktestReportService struct {
+	transactionRepo repository.Transaction
+	coinRepo        repository.Coin
+}
+
+// BuildReport skips fake transactions, they are shadow trades without exchange orders
+func (s *BacktestReportService) BuildReport(name string, tradingStrategy constants.TradingStrategy, from time.Time, to time.Time, initialCapital float64) (*BacktestReport, error) {
+	transactions, err := s.transactionRepo.FindAllByTradingStrategyAndCreatedAtInRange(tradingStrategy, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("Error during loading transactions of %d: %s", tradingStrategy, err.Error())
+	}
+
+	openTransactions := make(map[int64]*domains.Transaction)
+	closeTransactions := make([]*domains.Transaction, 0, len(transactions)/2)
+	for _, transaction := range transactions {
+		if transaction.IsFake {
+			continue
+		}
+		if transaction.Profit.Valid {
+			closeTransactions = append(closeTransactions, transaction)
+		} else {
+			openTransactions[transaction.Id] = transaction
+		}
+	}
+
+	symbols := make(map[int64]string)
+	trades := make([]*BacktestTrade, 0, len(closeTransactions))
+	for _, closeTransaction := range closeTransactions {
+		openTransaction, ok := openTransactions[closeTransaction.RelatedTransactionId.Int64]
+		if !ok {
+			// position was opened before the period
+			continue
+		}
+		delete(openTransactions, openTransaction.Id)
+
+		symbol, err := s.findSymbol(symbols, closeTransaction.CoinId)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, NewBacktestTrade(symbol, openTransaction, closeTransaction))
+	}
+
+	openedAt := make([]time.Time, 0, len(openTransactions))
+	for _, openTransaction := range openTransactions {
+		openedAt = append(openedAt, openTransaction.CreatedAt)
+	}
+
+	return NewBacktestReport(name, int(tradingStrategy), from, to, initialCapital, trades, openedAt), nil
+}
+
+func (s *BacktestReportService) findSymbol(symbols map[int64]string, coinId int64) (string, error) {
+	if symbol, ok := symbols[coinId]; ok {
+		return symbol, nil
+	}
+	coin, err := s.coinRepo.FindById(coinId)
+	if err != nil {
+		return "", fmt.Errorf("Error during loading coin %d: %s", coinId, err.Error())
+	}
+	symbol := fmt.Sprintf("%d", coinId)
+	if coin != nil {
+		symbol = coin.Symbol
+	}
+	symbols[coinId] = symbol
+	return symbol, nil
+}
+
+// NewBacktestTrade uses profit of the close transaction, it already includes commissions of both orders
+func NewBacktestTrade(symbol string, openTransaction *domains.Transaction, closeTransaction *domains.Transaction) *BacktestTrade {
+	fees := openTransaction.Commission + closeTransaction.Commission
+	netPnl := util.GetDollarsByCents(closeTransaction.Profit.Int64)
+
+	return &BacktestTrade{
+		Symbol:        symbol,
+		TradingKey:    openTransaction.TradingKey,
+		FuturesType:   futureType.GetString(openTransaction.FuturesType),
+		OpenedAt:      openTransaction.CreatedAt,
+		ClosedAt:      closeTransaction.CreatedAt,
+		OpenPrice:     openTransaction.Price,
+		ClosePrice:    closeTransaction.Price,
+		Cost:          openTransaction.TotalCost,
+		GrossPnl:      netPnl + fees,
+		Fees:          fees,
+		NetPnl:        netPnl,
+		ProfitPercent: closeTransaction.PercentProfit.Float64,
+		CloseReason:   closeTransaction.CloseReason.String,
+	}
+}
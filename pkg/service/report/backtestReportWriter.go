@@ -0,0 +1,201 @@
+package report
+
+import (
+	"cryptoBot/pkg/constants"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	CHART_WIDTH  = 900
+	CHART_HEIGHT = 300
+)
+
+// String formats the report for the console
+func (r *BacktestReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Backtest %s [%d] %s - %s\n", r.Name, r.TradingStrategy, r.From.Format(constants.DATE_TIME_FORMAT), r.To.Format(constants.DATE_TIME_FORMAT))
+	fmt.Fprintf(&b, "| Capital          | %12.2f | Final equity     | %12.2f |\n", r.InitialCapital, r.FinalEquity)
+	fmt.Fprintf(&b, "| Net PnL          | %12.2f | Gross PnL        | %12.2f |\n", r.NetPnl, r.GrossPnl)
+	fmt.Fprintf(&b, "| Fees             | %12.2f | Return           | %11.2f%% |\n", r.Fees, r.ReturnPercent)
+	fmt.Fprintf(&b, "| CAGR             | %11.2f%% | Sharpe           | %12.2f |\n", r.Cagr, r.Sharpe)
+	fmt.Fprintf(&b, "| Sortino          | %12.2f | Calmar           | %12.2f |\n", r.Sortino, r.Calmar)
+	fmt.Fprintf(&b, "| Max drawdown     | %11.2f%% | Drawdown hours   | %12.1f |\n", r.MaxDrawdownPercent, r.MaxDrawdownHours)
+	fmt.Fprintf(&b, "| Trades           | %12d | Win rate         | %11.2f%% |\n", r.Trades, r.WinRate)
+	fmt.Fprintf(&b, "| Profit factor    | %12.2f | Expectancy       | %12.2f |\n", r.ProfitFactor, r.Expectancy)
+	fmt.Fprintf(&b, "| Avg holding hours| %12.1f | Exposure         | %11.2f%% |\n", r.AvgHoldingHours, r.ExposurePercent)
+	fmt.Fprintf(&b, "| Open positions   | %12d |                  |              |\n", r.OpenPositions)
+
+	writeBreakdowns(&b, "Coin", r.ByCoin)
+	writeBreakdowns(&b, "Trading key", r.ByTradingKey)
+	writeBenchmarks(&b, r.Benchmarks)
+	return b.String()
+}
+
+func writeBreakdowns(b *strings.Builder, title string, breakdowns []*BacktestBreakdown) {
+	fmt.Fprintf(b, "\n| %-20s | Trades | Win rate |    Net PnL |       Fees | PF    |\n", title)
+	fmt.Fprintf(b, "|----------------------|--------|----------|------------|------------|-------|\n")
+	for _, breakdown := range breakdowns {
+		fmt.Fprintf(b, "| %-20s | %6d | %7.2f%% | %10.2f | %10.2f | %5.2f |\n",
+			breakdown.Key, breakdown.Trades, breakdown.WinRate, breakdown.NetPnl, breakdown.Fees, breakdown.ProfitFactor)
+	}
+}
+
+func (r *BacktestReport) WriteJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteHtml writes self-contained page without external scripts and styles, the equity is drawn by svg
+func (r *BacktestReport) WriteHtml(writer io.Writer) error {
+	minEquity, maxEquity := r.findEquityRange()
+	benchmarkLines := make([]benchmarkLine, 0, len(r.Benchmarks))
+	for i, benchmark := range r.Benchmarks {
+		benchmarkLines = append(benchmarkLines, benchmarkLine{
+			Name:   benchmark.Name,
+			Color:  benchmarkColors[i%len(benchmarkColors)],
+			Points: r.buildLine(benchmark.Equity, minEquity, maxEquity),
+		})
+	}
+
+	return htmlTemplate.Execute(writer, struct {
+		*BacktestReport
+		Console        string
+		EquityLine     string
+		BenchmarkLines []benchmarkLine
+		ChartWidth     int
+		ChartHeight    int
+	}{
+		BacktestReport: r,
+		Console:        r.String(),
+		EquityLine:     r.buildLine(r.Equity, minEquity, maxEquity),
+		BenchmarkLines: benchmarkLines,
+		ChartWidth:     CHART_WIDTH,
+		ChartHeight:    CHART_HEIGHT,
+	})
+}
+
+type benchmarkLine struct {
+	Name   string
+	Color  string
+	Points string
+}
+
+var benchmarkColors = []string{"#7f8c8d", "#e67e22", "#27ae60", "#8e44ad"}
+
+// findEquityRange returns the lowest and the highest equity of the strategy and the benchmarks, they are drawn on one scale
+func (r *BacktestReport) findEquityRange() (float64, float64) {
+	minEquity, maxEquity := math.MaxFloat64, -math.MaxFloat64
+	curves := [][]BacktestEquityPoint{r.Equity}
+	for _, benchmark := range r.Benchmarks {
+		curves = append(curves, benchmark.Equity)
+	}
+	for _, curve := range curves {
+		for _, point := range curve {
+			minEquity = math.Min(minEquity, point.Equity)
+			maxEquity = math.Max(maxEquity, point.Equity)
+		}
+	}
+	return minEquity, maxEquity
+}
+
+// buildLine returns points of svg polyline scaled by time and equity
+func (r *BacktestReport) buildLine(equity []BacktestEquityPoint, minEquity float64, maxEquity float64) string {
+	if len(equity) == 0 {
+		return ""
+	}
+	period := r.To.Sub(r.From).Seconds()
+
+	points := make([]string, 0, len(equity)+1)
+	for _, point := range equity {
+		x := math.Min(divide(point.Time.Sub(r.From).Seconds(), period), 1) * CHART_WIDTH
+		y := CHART_HEIGHT - divide(point.Equity-minEquity, maxEquity-minEquity)*CHART_HEIGHT
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	// equity stays the same until the end of the period
+	last := equity[len(equity)-1]
+	points = append(points, fmt.Sprintf("%d,%.1f", CHART_WIDTH, CHART_HEIGHT-divide(last.Equity-minEquity, maxEquity-minEquity)*CHART_HEIGHT))
+	return strings.Join(points, " ")
+}
+
+// Save writes json and html reports into the directory, file names are built by name of the report
+func (r *BacktestReport) Save(dir string) (string, string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%s_%s_%s", fileNameReplacer.ReplaceAllString(r.Name, "_"),
+		r.From.Format(constants.DATE_FORMAT), r.To.Format(constants.DATE_FORMAT)))
+
+	jsonPath := base + ".json"
+	if err := writeFile(jsonPath, r.WriteJson); err != nil {
+		return "", "", err
+	}
+	htmlPath := base + ".html"
+	if err := writeFile(htmlPath, r.WriteHtml); err != nil {
+		return "", "", err
+	}
+	return jsonPath, htmlPath, nil
+}
+
+var fileNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+func writeFile(path string, write func(writer io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Backtest {{.Name}}</title>
+<style>
+body { font-family: sans-serif; margin: 24px; color: #222; }
+pre { background: #f5f5f5; padding: 12px; }
+table { border-collapse: collapse; margin-bottom: 24px; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th { background: #eee; }
+td.text { text-align: left; }
+.loss { color: #c0392b; }
+svg { border: 1px solid #ccc; background: #fff; }
+</style>
+</head>
+<body>
+<h1>Backtest {{.Name}}</h1>
+<h2>Equity</h2>
+<svg width="{{.ChartWidth}}" height="{{.ChartHeight}}" viewBox="0 0 {{.ChartWidth}} {{.ChartHeight}}">
+{{range .BenchmarkLines}}<polyline fill="none" stroke="{{.Color}}" stroke-width="1" stroke-dasharray="4 3" points="{{.Points}}"/>
+{{end}}<polyline fill="none" stroke="#2e86de" stroke-width="2" points="{{.EquityLine}}"/>
+</svg>
+{{if .BenchmarkLines}}<p><span style="color: #2e86de">&#9632; Strategy</span>{{range .BenchmarkLines}} <span style="color: {{.Color}}">&#9632; {{.Name}}</span>{{end}}</p>
+{{end}}
+<h2>Summary</h2>
+<pre>{{.Console}}</pre>
+<h2>Trades</h2>
+<table>
+<tr><th>Symbol</th><th>Trading key</th><th>Type</th><th>Opened</th><th>Closed</th><th>Open price</th><th>Close price</th><th>Cost</th><th>Fees</th><th>Net PnL</th><th>%</th><th>Reason</th></tr>
+{{range .TradeList}}<tr{{if lt .NetPnl 0.0}} class="loss"{{end}}>
+<td class="text">{{.Symbol}}</td><td class="text">{{.TradingKey}}</td><td class="text">{{.FuturesType}}</td>
+<td class="text">{{.OpenedAt.Format "2006-01-02 15:04"}}</td><td class="text">{{.ClosedAt.Format "2006-01-02 15:04"}}</td>
+<td>{{printf "%.4f" .OpenPrice}}</td><td>{{printf "%.4f" .ClosePrice}}</td><td>{{printf "%.2f" .Cost}}</td>
+<td>{{printf "%.2f" .Fees}}</td><td>{{printf "%.2f" .NetPnl}}</td><td>{{printf "%.2f" .ProfitPercent}}</td><td class="text">{{.CloseReason}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
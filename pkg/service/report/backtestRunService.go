@@ -0,0 +1,227 @@
+package report
+
+import (
+	"bytes"
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+func NewBacktestRunService(runRepo repository.BacktestRun, clock date.Clock) *BacktestRunService {
+	return &BacktestRunService{
+		runRepo:     runRepo,
+		clock:       clock,
+		gitRevision: FindGitRevision(),
+	}
+}
+
+// BacktestRunService records backtests in backtest_run, so runs of the same strategy with different params are kept apart
+type BacktestRunService struct {
+	runRepo     repository.BacktestRun
+	clock       date.Clock
+	gitRevision string
+}
+
+// FindGitRevision returns short hash of HEAD with -dirty suffix for uncommitted changes, empty when git isn't available
+func FindGitRevision() string {
+	output, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	revision := strings.TrimSpace(string(output))
+	if status, err := exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output(); err == nil && len(bytes.TrimSpace(status)) > 0 {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// Start saves the run before the strategy is executed, its id is used to tag transactions
+func (s *BacktestRunService) Start(name string, strategy string, tradingStrategy constants.TradingStrategy, coins []string, interval int,
+	params map[string]interface{}, from time.Time, to time.Time, initialCapital float64) (*domains.BacktestRun, error) {
+	paramsJson, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("Error during serializing params of %s: %s", name, err.Error())
+	}
+	if params == nil {
+		paramsJson = []byte("{}")
+	}
+
+	run := &domains.BacktestRun{
+		Name:            name,
+		Strategy:        strategy,
+		TradingStrategy: tradingStrategy,
+		Coins:           strings.Join(coins, ","),
+		Interval:        interval,
+		Params:          string(paramsJson),
+		FromTime:        from,
+		ToTime:          to,
+		GitRevision:     s.gitRevision,
+		InitialCapital:  initialCapital,
+		Status:          constants.BACKTEST_RUN_RUNNING,
+		CreatedAt:       s.clock.NowTime(),
+	}
+	if err := s.runRepo.SaveBacktestRun(run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Finish saves the main metrics into columns for listing and comparing, the whole report is saved as json
+func (s *BacktestRunService) Finish(run *domains.BacktestRun, backtestReport *BacktestReport) error {
+	var reportJson bytes.Buffer
+	if err := backtestReport.WriteJson(&reportJson); err != nil {
+		return err
+	}
+
+	run.Status = constants.BACKTEST_RUN_FINISHED
+	run.NetPnl = backtestReport.NetPnl
+	run.ReturnPercent = backtestReport.ReturnPercent
+	run.Sharpe = backtestReport.Sharpe
+	run.MaxDrawdownPercent = backtestReport.MaxDrawdownPercent
+	run.Trades = backtestReport.Trades
+	run.WinRate = backtestReport.WinRate
+	run.ProfitFactor = backtestReport.ProfitFactor
+	run.Report = sql.NullString{String: reportJson.String(), Valid: true}
+	run.FinishedAt = sql.NullTime{Time: s.clock.NowTime(), Valid: true}
+	return s.runRepo.SaveBacktestRun(run)
+}
+
+func (s *BacktestRunService) Fail(run *domains.BacktestRun, cause error) error {
+	run.Status = constants.BACKTEST_RUN_FAILED
+	run.Error = sql.NullString{String: cause.Error(), Valid: true}
+	run.FinishedAt = sql.NullTime{Time: s.clock.NowTime(), Valid: true}
+	return s.runRepo.SaveBacktestRun(run)
+}
+
+func (s *BacktestRunService) FindAll(limit int) ([]*domains.BacktestRun, error) {
+	return s.runRepo.FindAll(limit)
+}
+
+// FindAllByIds returns runs in the order of ids, unknown id is an error
+func (s *BacktestRunService) FindAllByIds(ids []int64) ([]*domains.BacktestRun, error) {
+	runs := make([]*domains.BacktestRun, 0, len(ids))
+	for _, id := range ids {
+		run, err := s.runRepo.FindById(id)
+		if err != nil {
+			return nil, err
+		}
+		if run == nil {
+			return nil, fmt.Errorf("Backtest run %d not found", id)
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// ParseReport returns report saved by Finish, trades of the run are in its TradeList
+func ParseReport(run *domains.BacktestRun) (*BacktestReport, error) {
+	if !run.Report.Valid {
+		return nil, fmt.Errorf("Backtest run %d has no report, status %s", run.Id, run.Status)
+	}
+	var backtestReport BacktestReport
+	if err := json.Unmarshal([]byte(run.Report.String), &backtestReport); err != nil {
+		return nil, fmt.Errorf("Error during parsing report of run %d: %s", run.Id, err.Error())
+	}
+	return &backtestReport, nil
+}
+
+func (s *BacktestRunService) Delete(id int64) error {
+	return s.runRepo.DeleteById(id)
+}
+
+// FormatRuns prints runs as a table for the console
+func FormatRuns(runs []*domains.BacktestRun) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "| %5s | %-30s | %-10s | %-10s | %-8s | %-12s | %10s | %8s | %6s | %7s | %6s |\n",
+		"Id", "Name", "From", "To", "Status", "Revision", "Net PnL", "Return", "Sharpe", "Max DD", "Trades")
+	for _, run := range runs {
+		fmt.Fprintf(&b, "| %5d | %-30s | %-10s | %-10s | %-8s | %-12s | %10.2f | %7.2f%% | %6.2f | %6.2f%% | %6d |\n",
+			run.Id, run.Name, run.FromTime.Format(constants.DATE_FORMAT), run.ToTime.Format(constants.DATE_FORMAT), run.Status,
+			run.GitRevision, run.NetPnl, run.ReturnPercent, run.Sharpe, run.MaxDrawdownPercent, run.Trades)
+	}
+	return b.String()
+}
+
+// FormatComparison prints runs side by side, params are printed when they differ between the runs
+func FormatComparison(runs []*domains.BacktestRun) string {
+	rows := [][]string{
+		{"Id"}, {"Name"}, {"Period"}, {"Interval"}, {"Revision"}, {"Status"},
+		{"Net PnL"}, {"Return"}, {"Sharpe"}, {"Max drawdown"}, {"Trades"}, {"Win rate"}, {"Profit factor"},
+	}
+	params := make([]map[string]interface{}, 0, len(runs))
+	for _, run := range runs {
+		rows[0] = append(rows[0], fmt.Sprintf("%d", run.Id))
+		rows[1] = append(rows[1], run.Name)
+		rows[2] = append(rows[2], run.FromTime.Format(constants.DATE_FORMAT)+" - "+run.ToTime.Format(constants.DATE_FORMAT))
+		rows[3] = append(rows[3], fmt.Sprintf("%d", run.Interval))
+		rows[4] = append(rows[4], run.GitRevision)
+		rows[5] = append(rows[5], string(run.Status))
+		rows[6] = append(rows[6], fmt.Sprintf("%.2f", run.NetPnl))
+		rows[7] = append(rows[7], fmt.Sprintf("%.2f%%", run.ReturnPercent))
+		rows[8] = append(rows[8], fmt.Sprintf("%.2f", run.Sharpe))
+		rows[9] = append(rows[9], fmt.Sprintf("%.2f%%", run.MaxDrawdownPercent))
+		rows[10] = append(rows[10], fmt.Sprintf("%d", run.Trades))
+		rows[11] = append(rows[11], fmt.Sprintf("%.2f%%", run.WinRate))
+		rows[12] = append(rows[12], fmt.Sprintf("%.2f", run.ProfitFactor))
+
+		runParams := make(map[string]interface{})
+		_ = json.Unmarshal([]byte(run.Params), &runParams)
+		params = append(params, runParams)
+	}
+	rows = append(rows, buildParamRows(params)...)
+
+	var b strings.Builder
+	for _, row := range rows {
+		fmt.Fprintf(&b, "| %-16s |", row[0])
+		for _, value := range row[1:] {
+			fmt.Fprintf(&b, " %-25s |", value)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// buildParamRows returns rows of params having different values in the runs
+func buildParamRows(params []map[string]interface{}) [][]string {
+	names := make(map[string]bool)
+	for _, runParams := range params {
+		for name := range runParams {
+			names[strings.ToLower(name)] = true
+		}
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	rows := make([][]string, 0)
+	for _, name := range sortedNames {
+		row := []string{name}
+		isChanged := false
+		for _, runParams := range params {
+			value := ""
+			for paramName, paramValue := range runParams {
+				if strings.EqualFold(paramName, name) {
+					value = fmt.Sprintf("%v", paramValue)
+				}
+			}
+			if len(row) > 1 && row[1] != value {
+				isChanged = true
+			}
+			row = append(row, value)
+		}
+		if isChanged {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
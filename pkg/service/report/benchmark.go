@@ -0,0 +1,233 @@
+package report
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"fmt"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BenchmarkConfig is read from analyser.benchmark, empty Btc disables the BTC benchmark
+type BenchmarkConfig struct {
+	Btc string
+	/* Coins of equal-weight basket, traded coins when it's empty */
+	Basket []string
+	/* Interval of klines of benchmark prices, interval of the run when it's empty */
+	Interval string
+}
+
+func NewBenchmarkConfig() *BenchmarkConfig {
+	return &BenchmarkConfig{
+		Btc:      viper.GetString("analyser.benchmark.btc"),
+		Basket:   viper.GetStringSlice("analyser.benchmark.basket"),
+		Interval: viper.GetString("analyser.benchmark.interval"),
+	}
+}
+
+// BenchmarkCurve is equity of the capital invested at the start of the period and held until the end, one point per day
+type BenchmarkCurve struct {
+	Name    string
+	Symbols []string
+	Equity  []BacktestEquityPoint
+}
+
+// BacktestBenchmark compares daily returns of the strategy with daily returns of the benchmark
+type BacktestBenchmark struct {
+	Name               string   `json:"name"`
+	Symbols            []string `json:"symbols"`
+	FinalEquity        float64  `json:"finalEquity"`
+	ReturnPercent      float64  `json:"returnPercent"`
+	MaxDrawdownPercent float64  `json:"maxDrawdownPercent"`
+	/* Annualized return of the strategy not explained by the benchmark in percent, risk-free rate is 0 */
+	Alpha       float64               `json:"alpha"`
+	Beta        float64               `json:"beta"`
+	Correlation float64               `json:"correlation"`
+	Equity      []BacktestEquityPoint `json:"equity"`
+}
+
+func NewBenchmarkService(klineRepo repository.Kline, coinRepo repository.Coin, config *BenchmarkConfig) *BenchmarkService {
+	return &BenchmarkService{
+		klineRepo: klineRepo,
+		coinRepo:  coinRepo,
+		config:    config,
+	}
+}
+
+// BenchmarkService builds buy-and-hold curves of traded coins, BTC and equal-weight basket from stored klines
+type BenchmarkService struct {
+	klineRepo repository.Kline
+	coinRepo  repository.Coin
+	config    *BenchmarkConfig
+}
+
+// AddBenchmarks compares the report with benchmarks of the same period and capital, missing klines only skip the benchmark
+func (s *BenchmarkService) AddBenchmarks(backtestReport *BacktestReport, symbols []string, interval string) {
+	strategyReturns := CalculateDailyReturns(backtestReport.InitialCapital, backtestReport.From, backtestReport.To, backtestReport.TradeList)
+	for _, curve := range s.BuildCurves(symbols, interval, backtestReport.From, backtestReport.To, backtestReport.InitialCapital) {
+		backtestReport.Benchmarks = append(backtestReport.Benchmarks, CompareWithBenchmark(strategyReturns, curve))
+	}
+}
+
+// BuildCurves returns buy-and-hold of every traded coin, BTC and equal-weight basket, the same curve isn't repeated
+func (s *BenchmarkService) BuildCurves(symbols []string, interval string, from time.Time, to time.Time, capital float64) []*BenchmarkCurve {
+	if s.config.Interval != "" {
+		interval = s.config.Interval
+	}
+
+	prices := make(map[string][]float64)
+	findPrices := func(symbol string) []float64 {
+		if _, ok := prices[symbol]; !ok {
+			symbolPrices, err := s.findDailyPrices(symbol, interval, from, to)
+			if err != nil {
+				zap.S().Warnf("Benchmark of %s is skipped: %s", symbol, err.Error())
+			}
+			prices[symbol] = symbolPrices
+		}
+		return prices[symbol]
+	}
+
+	curves := make([]*BenchmarkCurve, 0, len(symbols)+2)
+	held := make(map[string]bool)
+	for _, symbol := range append(append([]string{}, symbols...), s.config.Btc) {
+		if symbol == "" || held[symbol] {
+			continue
+		}
+		held[symbol] = true
+		if symbolPrices := findPrices(symbol); len(symbolPrices) > 0 {
+			curves = append(curves, &BenchmarkCurve{
+				Name:    "Hold " + symbol,
+				Symbols: []string{symbol},
+				Equity:  buildBasketEquity(from, capital, [][]float64{symbolPrices}),
+			})
+		}
+	}
+
+	basket := s.config.Basket
+	if len(basket) == 0 {
+		basket = symbols
+	}
+	basketSymbols := make([]string, 0, len(basket))
+	basketPrices := make([][]float64, 0, len(basket))
+	for _, symbol := range basket {
+		if symbolPrices := findPrices(symbol); len(symbolPrices) > 0 {
+			basketSymbols = append(basketSymbols, symbol)
+			basketPrices = append(basketPrices, symbolPrices)
+		}
+	}
+	// basket of one coin is its buy-and-hold
+	if len(basketSymbols) > 1 {
+		curves = append(curves, &BenchmarkCurve{
+			Name:    "Equal-weight basket",
+			Symbols: basketSymbols,
+			Equity:  buildBasketEquity(from, capital, basketPrices),
+		})
+	}
+	return curves
+}
+
+// findDailyPrices returns close price at the start of the period and at the end of every day as in CalculateDailyReturns
+func (s *BenchmarkService) findDailyPrices(symbol string, interval string, from time.Time, to time.Time) ([]float64, error) {
+	coin, err := s.coinRepo.FindBySymbol(symbol)
+	if err != nil || coin == nil {
+		return nil, fmt.Errorf("coin [%s] not found", symbol)
+	}
+	// kline closed just before the start gives the price at the start
+	klines, err := s.klineRepo.FindAllByCoinIdAndIntervalAndCloseTimeInRange(coin.Id, interval, from.AddDate(0, 0, -1), to)
+	if err != nil {
+		return nil, err
+	}
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("klines with interval %s are not found", interval)
+	}
+	sorted := make([]*domains.Kline, len(klines))
+	copy(sorted, klines)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].CloseTime.Before(sorted[j].CloseTime)
+	})
+
+	days := int(math.Ceil(to.Sub(from).Hours() / 24))
+	prices := make([]float64, 0, days+1)
+	// price before the first kline is its open
+	price := sorted[0].Open
+	i := 0
+	for day := 0; day <= days; day++ {
+		dayEnd := from.AddDate(0, 0, day)
+		for ; i < len(sorted) && sorted[i].CloseTime.Before(dayEnd); i++ {
+			price = sorted[i].Close
+		}
+		prices = append(prices, price)
+	}
+	if prices[0] <= 0 {
+		return nil, fmt.Errorf("price at the start is %v", prices[0])
+	}
+	return prices, nil
+}
+
+// buildBasketEquity splits the capital equally between the coins at the start without rebalancing
+func buildBasketEquity(from time.Time, capital float64, prices [][]float64) []BacktestEquityPoint {
+	equity := make([]BacktestEquityPoint, len(prices[0]))
+	for day := range equity {
+		equity[day].Time = from.AddDate(0, 0, day)
+		for _, symbolPrices := range prices {
+			equity[day].Equity += capital / float64(len(prices)) * symbolPrices[day] / symbolPrices[0]
+		}
+	}
+	return equity
+}
+
+// CompareWithBenchmark calculates alpha, beta and correlation by daily returns of the strategy and the benchmark
+func CompareWithBenchmark(strategyReturns []float64, curve *BenchmarkCurve) *BacktestBenchmark {
+	first, last := curve.Equity[0].Equity, curve.Equity[len(curve.Equity)-1].Equity
+	benchmark := &BacktestBenchmark{
+		Name:          curve.Name,
+		Symbols:       curve.Symbols,
+		FinalEquity:   last,
+		ReturnPercent: percentOf(last-first, first),
+		Equity:        curve.Equity,
+	}
+	benchmark.MaxDrawdownPercent, _ = CalculateMaxDrawdown(curve.Equity, curve.Equity[len(curve.Equity)-1].Time)
+
+	benchmarkReturns := make([]float64, 0, len(curve.Equity)-1)
+	for day := 1; day < len(curve.Equity); day++ {
+		benchmarkReturns = append(benchmarkReturns, divide(curve.Equity[day].Equity, curve.Equity[day-1].Equity)-1)
+	}
+	// returns of the strategy stop when its equity is lost
+	count := len(strategyReturns)
+	if len(benchmarkReturns) < count {
+		count = len(benchmarkReturns)
+	}
+	if count < 2 {
+		return benchmark
+	}
+	strategyReturns, benchmarkReturns = strategyReturns[:count], benchmarkReturns[:count]
+
+	strategyMean, benchmarkMean := mean(strategyReturns), mean(benchmarkReturns)
+	covariance := float64(0)
+	for i := 0; i < count; i++ {
+		covariance += (strategyReturns[i] - strategyMean) * (benchmarkReturns[i] - benchmarkMean)
+	}
+	covariance /= float64(count - 1)
+	benchmarkDeviation := standardDeviation(benchmarkReturns)
+
+	benchmark.Beta = divide(covariance, benchmarkDeviation*benchmarkDeviation)
+	benchmark.Correlation = divide(covariance, standardDeviation(strategyReturns)*benchmarkDeviation)
+	benchmark.Alpha = (strategyMean - benchmark.Beta*benchmarkMean) * DAYS_IN_YEAR * 100
+	return benchmark
+}
+
+func writeBenchmarks(b *strings.Builder, benchmarks []*BacktestBenchmark) {
+	if len(benchmarks) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n| %-20s | Final equity |   Return |   Max DD |    Alpha |  Beta | Corr  |\n", "Benchmark")
+	fmt.Fprintf(b, "|----------------------|--------------|----------|----------|----------|-------|-------|\n")
+	for _, benchmark := range benchmarks {
+		fmt.Fprintf(b, "| %-20s | %12.2f | %7.2f%% | %7.2f%% | %7.2f%% | %5.2f | %5.2f |\n", benchmark.Name, benchmark.FinalEquity,
+			benchmark.ReturnPercent, benchmark.MaxDrawdownPercent, benchmark.Alpha, benchmark.Beta, benchmark.Correlation)
+	}
+}
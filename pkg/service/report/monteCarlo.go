@@ -0,0 +1,231 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+type MonteCarloMethod string
+
+const (
+	/* The same trades in random order, final equity is the same, drawdowns differ */
+	MONTE_CARLO_SHUFFLE MonteCarloMethod = "shuffle"
+	/* Trades drawn with replacement, some trades are repeated and some are missed */
+	MONTE_CARLO_BOOTSTRAP MonteCarloMethod = "bootstrap"
+	/* Every trade is skipped with SkipPercent probability, e.g. missed by latency or rejected order */
+	MONTE_CARLO_SKIP MonteCarloMethod = "skip"
+)
+
+var MonteCarloMethods = []MonteCarloMethod{MONTE_CARLO_SHUFFLE, MONTE_CARLO_BOOTSTRAP, MONTE_CARLO_SKIP}
+
+type MonteCarloConfig struct {
+	Simulations int
+	SkipPercent float64
+	/* Simulation is ruined when the drawdown from the peak reaches it */
+	RuinDrawdownPercent float64
+	/* The same seed gives the same simulations */
+	Seed int64
+}
+
+type MonteCarloPercentiles struct {
+	P5  float64 `json:"p5"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P95 float64 `json:"p95"`
+}
+
+// MonteCarloResult is distribution of equity of simulated sequences of backtest trades, equity is in USD
+type MonteCarloResult struct {
+	Method         MonteCarloMethod `json:"method"`
+	Simulations    int              `json:"simulations"`
+	Trades         int              `json:"trades"`
+	InitialCapital float64          `json:"initialCapital"`
+
+	ActualFinalEquity        float64 `json:"actualFinalEquity"`
+	ActualMaxDrawdownPercent float64 `json:"actualMaxDrawdownPercent"`
+
+	FinalEquity        MonteCarloPercentiles `json:"finalEquity"`
+	MaxDrawdownPercent MonteCarloPercentiles `json:"maxDrawdownPercent"`
+	/* Percent of simulations reaching RuinDrawdownPercent */
+	RiskOfRuinPercent float64 `json:"riskOfRuinPercent"`
+	/* Percent of simulations ending below the initial capital */
+	LossPercent float64 `json:"lossPercent"`
+
+	/* Equity after every trade of every simulation, the first point is the initial capital */
+	Paths [][]float64 `json:"-"`
+}
+
+// RunMonteCarlo simulates net pnl of closed trades by every method, trades keep fixed size in USD as in the backtest
+func RunMonteCarlo(initialCapital float64, trades []*BacktestTrade, config MonteCarloConfig) []*MonteCarloResult {
+	pnls := make([]float64, 0, len(trades))
+	for _, trade := range trades {
+		pnls = append(pnls, trade.NetPnl)
+	}
+
+	results := make([]*MonteCarloResult, 0, len(MonteCarloMethods))
+	for _, method := range MonteCarloMethods {
+		results = append(results, SimulateMonteCarlo(initialCapital, pnls, method, config))
+	}
+	return results
+}
+
+func SimulateMonteCarlo(initialCapital float64, pnls []float64, method MonteCarloMethod, config MonteCarloConfig) *MonteCarloResult {
+	random := rand.New(rand.NewSource(config.Seed))
+	actual := buildEquityPath(initialCapital, pnls)
+
+	result := &MonteCarloResult{
+		Method:                   method,
+		Simulations:              config.Simulations,
+		Trades:                   len(pnls),
+		InitialCapital:           initialCapital,
+		ActualFinalEquity:        actual[len(actual)-1],
+		ActualMaxDrawdownPercent: calculatePathDrawdown(actual),
+		Paths:                    make([][]float64, 0, config.Simulations),
+	}
+
+	finalEquities := make([]float64, 0, config.Simulations)
+	drawdowns := make([]float64, 0, config.Simulations)
+	ruined, losing := 0, 0
+	for i := 0; i < config.Simulations; i++ {
+		path := buildEquityPath(initialCapital, samplePnls(pnls, method, config.SkipPercent, random))
+		drawdown := calculatePathDrawdown(path)
+		finalEquity := path[len(path)-1]
+
+		if drawdown >= config.RuinDrawdownPercent || finalEquity <= 0 {
+			ruined++
+		}
+		if finalEquity < initialCapital {
+			losing++
+		}
+		finalEquities = append(finalEquities, finalEquity)
+		drawdowns = append(drawdowns, drawdown)
+		result.Paths = append(result.Paths, path)
+	}
+
+	result.FinalEquity = calculatePercentiles(finalEquities)
+	result.MaxDrawdownPercent = calculatePercentiles(drawdowns)
+	result.RiskOfRuinPercent = percentOf(float64(ruined), float64(config.Simulations))
+	result.LossPercent = percentOf(float64(losing), float64(config.Simulations))
+	return result
+}
+
+// EquityPercentile returns the percentile of equity of all simulations after every trade
+func (r *MonteCarloResult) EquityPercentile(percentile float64) []float64 {
+	if len(r.Paths) == 0 {
+		return nil
+	}
+
+	curve := make([]float64, len(r.Paths[0]))
+	values := make([]float64, len(r.Paths))
+	for step := range curve {
+		for i, path := range r.Paths {
+			values[i] = path[step]
+		}
+		sort.Float64s(values)
+		curve[step] = percentileOfSorted(values, percentile)
+	}
+	return curve
+}
+
+// samplePnls returns sequence of the same length, skipped trades are replaced by 0
+func samplePnls(pnls []float64, method MonteCarloMethod, skipPercent float64, random *rand.Rand) []float64 {
+	sample := make([]float64, len(pnls))
+	switch method {
+	case MONTE_CARLO_SHUFFLE:
+		copy(sample, pnls)
+		random.Shuffle(len(sample), func(i, j int) {
+			sample[i], sample[j] = sample[j], sample[i]
+		})
+	case MONTE_CARLO_BOOTSTRAP:
+		for i := range sample {
+			sample[i] = pnls[random.Intn(len(pnls))]
+		}
+	case MONTE_CARLO_SKIP:
+		for i, pnl := range pnls {
+			if random.Float64()*100 >= skipPercent {
+				sample[i] = pnl
+			}
+		}
+	}
+	return sample
+}
+
+func buildEquityPath(initialCapital float64, pnls []float64) []float64 {
+	path := make([]float64, 0, len(pnls)+1)
+	equity := initialCapital
+	path = append(path, equity)
+	for _, pnl := range pnls {
+		equity += pnl
+		path = append(path, equity)
+	}
+	return path
+}
+
+// calculatePathDrawdown returns the largest drawdown from the peak in percent, losing the whole equity is 100%
+func calculatePathDrawdown(path []float64) float64 {
+	peak := path[0]
+	maxDrawdown := float64(0)
+	for _, equity := range path {
+		if equity > peak {
+			peak = equity
+			continue
+		}
+		if drawdown := math.Min(percentOf(peak-equity, peak), 100); drawdown > maxDrawdown {
+			maxDrawdown = drawdown
+		}
+	}
+	return maxDrawdown
+}
+
+func calculatePercentiles(values []float64) MonteCarloPercentiles {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	return MonteCarloPercentiles{
+		P5:  percentileOfSorted(sorted, 5),
+		P25: percentileOfSorted(sorted, 25),
+		P50: percentileOfSorted(sorted, 50),
+		P75: percentileOfSorted(sorted, 75),
+		P95: percentileOfSorted(sorted, 95),
+	}
+}
+
+// percentileOfSorted interpolates between the closest ranks
+func percentileOfSorted(sorted []float64, percentile float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := percentile / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// FormatMonteCarlo prints percentiles of the methods as a table for the console
+func FormatMonteCarlo(name string, results []*MonteCarloResult) string {
+	var b strings.Builder
+	if len(results) > 0 {
+		fmt.Fprintf(&b, "Monte Carlo of %s: %d trades, %d simulations, initial capital %.2f, actual final equity %.2f, actual max drawdown %.2f%%\n",
+			name, results[0].Trades, results[0].Simulations, results[0].InitialCapital, results[0].ActualFinalEquity, results[0].ActualMaxDrawdownPercent)
+	}
+	fmt.Fprintf(&b, "| %-9s | %-12s | %10s | %10s | %10s | %10s | %10s |\n", "Method", "Value", "P5", "P25", "P50", "P75", "P95")
+	for _, result := range results {
+		writePercentiles(&b, result.Method, "Final equity", result.FinalEquity, "%10.2f")
+		writePercentiles(&b, result.Method, "Max DD %", result.MaxDrawdownPercent, "%10.2f")
+		fmt.Fprintf(&b, "| %-9s | risk of ruin %.2f%%, loss %.2f%%\n", result.Method, result.RiskOfRuinPercent, result.LossPercent)
+	}
+	return b.String()
+}
+
+func writePercentiles(b *strings.Builder, method MonteCarloMethod, title string, percentiles MonteCarloPercentiles, format string) {
+	fmt.Fprintf(b, "| %-9s | %-12s |", method, title)
+	for _, value := range []float64{percentiles.P5, percentiles.P25, percentiles.P50, percentiles.P75, percentiles.P95} {
+		fmt.Fprintf(b, " "+format+" |", value)
+	}
+	b.WriteString("\n")
+}
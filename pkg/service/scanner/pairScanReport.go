@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/data/domains"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+var pairScanReportHeader = []string{"symbol1", "symbol2", "from", "to", "rank", "score", "correlation", "hedgeRatio", "adf", "halfLife", "windows"}
+
+// PairCandidate is a pair for the analyser taken from the scanner report or from the last scan
+type PairCandidate struct {
+	Symbol1 string
+	Symbol2 string
+	From    string
+	To      string
+}
+
+// WriteReport saves results to csv file, the file is the input of cmd/analyser/pairArbitrage
+func (s *PairScannerService) WriteReport(path string, results []*domains.PairScanResult) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(pairScanReportHeader); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		coin1, coin2, err := s.findPairCoins(result)
+		if err != nil {
+			return err
+		}
+
+		if err := writer.Write([]string{
+			coin1.Symbol,
+			coin2.Symbol,
+			result.FromTime.Format(constants.DATE_FORMAT),
+			result.ToTime.Format(constants.DATE_FORMAT),
+			strconv.Itoa(result.Rank),
+			strconv.FormatFloat(result.Score, 'f', 4, 64),
+			strconv.FormatFloat(result.Correlation, 'f', 4, 64),
+			strconv.FormatFloat(result.HedgeRatio, 'f', 6, 64),
+			strconv.FormatFloat(result.AdfStatistic, 'f', 4, 64),
+			strconv.FormatFloat(result.HalfLife, 'f', 1, 64),
+			fmt.Sprintf("%d/%d", result.CointegratedWindows, result.TotalWindows),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadReport returns top pairs from csv report ordered as in the file, all pairs when top is zero
+func ReadReport(path string, top int) ([]PairCandidate, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || records[0][0] != pairScanReportHeader[0] {
+		return nil, fmt.Errorf("file %s isn't a pair scan report", path)
+	}
+
+	candidates := make([]PairCandidate, 0, len(records)-1)
+	for _, record := range records[1:] {
+		if top > 0 && len(candidates) >= top {
+			break
+		}
+		candidates = append(candidates, PairCandidate{Symbol1: record[0], Symbol2: record[1], From: record[2], To: record[3]})
+	}
+	return candidates, nil
+}
+
+// FindLastScanCandidates returns top pairs of the last scan stored in pair_scan_result, all pairs when top is zero
+func (s *PairScannerService) FindLastScanCandidates(top int) ([]PairCandidate, error) {
+	results, err := s.PairScanResultRepo.FindAllByLastScan(top)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]PairCandidate, 0, len(results))
+	for _, result := range results {
+		coin1, coin2, err := s.findPairCoins(result)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, PairCandidate{
+			Symbol1: coin1.Symbol,
+			Symbol2: coin2.Symbol,
+			From:    result.FromTime.Format(constants.DATE_FORMAT),
+			To:      result.ToTime.Format(constants.DATE_FORMAT),
+		})
+	}
+	return candidates, nil
+}
+
+func (s *PairScannerService) findPairCoins(result *domains.PairScanResult) (*domains.Coin, *domains.Coin, error) {
+	coin1, err := s.CoinRepo.FindById(result.CoinId1)
+	if err != nil {
+		return nil, nil, err
+	}
+	coin2, err := s.CoinRepo.FindById(result.CoinId2)
+	if err != nil {
+		return nil, nil, err
+	}
+	return coin1, coin2, nil
+}
@@ -0,0 +1,253 @@
+package scanner
+
+import (
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/indicator"
+	"fmt"
+	"go.uber.org/zap"
+	"math"
+	"sort"
+	"time"
+)
+
+// PairScanConfig describes one run of the scanner
+type PairScanConfig struct {
+	/* Coins to combine into pairs, all coins when empty */
+	Symbols  []string
+	Interval string
+	From     time.Time
+	To       time.Time
+
+	/* Klines in one rolling window and shift between windows */
+	WindowSize int
+	WindowStep int
+
+	/* Pairs with lower average correlation of windows are not tested for cointegration, window with lower correlation fails */
+	MinCorrelation float64
+	/* Pairs with longer median half-life of windows get zero score, window with longer half-life fails, in klines */
+	MaxHalfLife float64
+}
+
+func NewPairScannerService(
+	coinRepo repository.Coin,
+	klineRepo repository.Kline,
+	pairScanResultRepo repository.PairScanResult,
+	cointegrationService *indicator.CointegrationService,
+) *PairScannerService {
+	return &PairScannerService{
+		CoinRepo:             coinRepo,
+		KlineRepo:            klineRepo,
+		PairScanResultRepo:   pairScanResultRepo,
+		CointegrationService: cointegrationService,
+	}
+}
+
+// PairScannerService ranks pairs of coins by correlation, Engle-Granger cointegration and half-life over rolling windows
+type PairScannerService struct {
+	CoinRepo             repository.Coin
+	KlineRepo            repository.Kline
+	PairScanResultRepo   repository.PairScanResult
+	CointegrationService *indicator.CointegrationService
+}
+
+type coinPrices struct {
+	coin   *domains.Coin
+	prices map[time.Time]float64
+	times  []time.Time
+}
+
+// Scan tests every pair of coins and returns results ordered by rank
+func (s *PairScannerService) Scan(config PairScanConfig) ([]*domains.PairScanResult, error) {
+	if config.WindowSize < 2 {
+		return nil, fmt.Errorf("window size %d is too small", config.WindowSize)
+	}
+	if config.WindowStep < 1 {
+		config.WindowStep = config.WindowSize
+	}
+
+	coins, err := s.findCoins(config.Symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	allPrices := make([]*coinPrices, 0, len(coins))
+	for _, coin := range coins {
+		klines, err := s.KlineRepo.FindAllByCoinIdAndIntervalAndCloseTimeInRange(coin.Id, config.Interval, config.From, config.To)
+		if err != nil {
+			return nil, err
+		}
+		if len(klines) < config.WindowSize {
+			zap.S().Infof("Skip %s: %d klines is less than window %d", coin.Symbol, len(klines), config.WindowSize)
+			continue
+		}
+
+		prices := &coinPrices{coin: coin, prices: make(map[time.Time]float64, len(klines))}
+		for _, kline := range klines {
+			prices.prices[kline.OpenTime] = kline.Close
+			prices.times = append(prices.times, kline.OpenTime)
+		}
+		sort.Slice(prices.times, func(i, j int) bool { return prices.times[i].Before(prices.times[j]) })
+		allPrices = append(allPrices, prices)
+	}
+
+	scannedAt := time.Now()
+	results := make([]*domains.PairScanResult, 0)
+	for i := 0; i < len(allPrices); i++ {
+		for j := i + 1; j < len(allPrices); j++ {
+			result := s.scanPair(config, allPrices[i], allPrices[j])
+			if result == nil {
+				continue
+			}
+			result.ScannedAt = scannedAt
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	for i, result := range results {
+		result.Rank = i + 1
+	}
+
+	return results, nil
+}
+
+func (s *PairScannerService) Save(results []*domains.PairScanResult) error {
+	for _, result := range results {
+		if err := s.PairScanResultRepo.SavePairScanResult(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PairScannerService) scanPair(config PairScanConfig, coin1 *coinPrices, coin2 *coinPrices) *domains.PairScanResult {
+	prices1 := make([]float64, 0, len(coin1.times))
+	prices2 := make([]float64, 0, len(coin1.times))
+	for _, openTime := range coin1.times {
+		if price2, ok := coin2.prices[openTime]; ok {
+			prices1 = append(prices1, coin1.prices[openTime])
+			prices2 = append(prices2, price2)
+		}
+	}
+	if len(prices1) < config.WindowSize {
+		return nil
+	}
+
+	// correlation is cheap, uncorrelated pairs are skipped before cointegration tests, window without correlation is NaN
+	correlations := make([]float64, 0)
+	for from := 0; from+config.WindowSize <= len(prices1); from += config.WindowStep {
+		correlation, err := s.CointegrationService.Correlation(prices1[from:from+config.WindowSize], prices2[from:from+config.WindowSize])
+		if err != nil {
+			correlation = math.NaN()
+		}
+		correlations = append(correlations, correlation)
+	}
+	correlation := mean(correlations)
+	if math.IsNaN(correlation) || math.Abs(correlation) < config.MinCorrelation {
+		return nil
+	}
+
+	engleGranger, err := s.CointegrationService.EngleGranger(prices1, prices2)
+	if err != nil {
+		zap.S().Errorf("Error during Engle-Granger test %s-%s: %s", coin1.coin.Symbol, coin2.coin.Symbol, err.Error())
+		return nil
+	}
+
+	halfLives := make([]float64, 0, len(correlations))
+	cointegratedWindows := 0
+	for i := range correlations {
+		from, to := i*config.WindowStep, i*config.WindowStep+config.WindowSize
+		window, err := s.CointegrationService.EngleGranger(prices1[from:to], prices2[from:to])
+		if err != nil {
+			continue
+		}
+		halfLife, err := s.CointegrationService.HalfLife(window.Residuals)
+		if err != nil {
+			continue
+		}
+		halfLives = append(halfLives, halfLife)
+
+		if math.Abs(correlations[i]) >= config.MinCorrelation && window.IsCointegrated() && halfLife > 0 && halfLife <= config.MaxHalfLife {
+			cointegratedWindows++
+		}
+	}
+	if len(halfLives) == 0 {
+		zap.S().Errorf("Error during half-life calculation %s-%s: no window has half-life", coin1.coin.Symbol, coin2.coin.Symbol)
+		return nil
+	}
+	halfLife := median(halfLives)
+
+	result := &domains.PairScanResult{
+		CoinId1:             coin1.coin.Id,
+		CoinId2:             coin2.coin.Id,
+		Interval:            config.Interval,
+		FromTime:            config.From,
+		ToTime:              config.To,
+		WindowSize:          config.WindowSize,
+		Correlation:         correlation,
+		HedgeRatio:          engleGranger.Beta,
+		AdfStatistic:        engleGranger.AdfStatistic,
+		HalfLife:            math.Min(halfLife, math.MaxInt32),
+		CointegratedWindows: cointegratedWindows,
+		TotalWindows:        len(correlations),
+	}
+	result.Score = s.calculateScore(config, result)
+
+	zap.S().Debugf("%s-%s %s", coin1.coin.Symbol, coin2.coin.Symbol, result.String())
+	return result
+}
+
+// calculateScore prefers pairs passing all tests in most of windows, the whole period ADF statistic breaks ties.
+// Pair is useless for trading when spread doesn't return to mean in reasonable time or hedge ratio is negative.
+func (s *PairScannerService) calculateScore(config PairScanConfig, result *domains.PairScanResult) float64 {
+	if result.HalfLife <= 0 || result.HalfLife > config.MaxHalfLife || result.HedgeRatio <= 0 {
+		return 0
+	}
+	return result.GetCointegratedWindowsRatio()*10 + math.Max(0, -result.AdfStatistic)
+}
+
+func (s *PairScannerService) findCoins(symbols []string) ([]*domains.Coin, error) {
+	if len(symbols) == 0 {
+		return s.CoinRepo.FindAll()
+	}
+
+	coins := make([]*domains.Coin, 0, len(symbols))
+	for _, symbol := range symbols {
+		coin, err := s.CoinRepo.FindBySymbol(symbol)
+		if err != nil {
+			return nil, err
+		}
+		if coin == nil {
+			return nil, fmt.Errorf("Coin [%s] not found", symbol)
+		}
+		coins = append(coins, coin)
+	}
+	return coins, nil
+}
+
+// mean skips NaN values, it's NaN when there are no other values
+func mean(values []float64) float64 {
+	sum, count := float64(0), 0
+	for _, value := range values {
+		if !math.IsNaN(value) {
+			sum += value
+			count++
+		}
+	}
+	if count == 0 {
+		return math.NaN()
+	}
+	return sum / float64(count)
+}
+
+// median keeps infinite half-life of windows without mean reversion from breaking the result
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
@@ -0,0 +1,53 @@
+package signal
+
+import (
+	"cryptoBot/pkg/data/domains"
+)
+
+// CompositeSink executes signals and records executed ones, positions are opened transactions
+func NewCompositeSink(executionSink *ExecutionSink, recordingSink *RecordingSink) *CompositeSink {
+	return &CompositeSink{
+		ExecutionSink: executionSink,
+		RecordingSink: recordingSink,
+	}
+}
+
+type CompositeSink struct {
+	ExecutionSink *ExecutionSink
+	RecordingSink *RecordingSink
+}
+
+func (s *CompositeSink) Emit(signal *Signal) *domains.Transaction {
+	transaction := s.ExecutionSink.Emit(signal)
+	if transaction == nil {
+		return nil
+	}
+	return s.RecordingSink.record(signal, transaction)
+}
+
+func (s *CompositeSink) FindOpenedTransaction(coin *domains.Coin, tradingKey string) (*domains.Transaction, error) {
+	return s.ExecutionSink.FindOpenedTransaction(coin, tradingKey)
+}
+
+func (s *CompositeSink) FindAllOpenedTransactions(coin *domains.Coin, tradingKey string) ([]*domains.Transaction, error) {
+	return s.ExecutionSink.FindAllOpenedTransactions(coin, tradingKey)
+}
+
+func (s *CompositeSink) CloseByFixedStopLossOrTakeProfit(coin *domains.Coin, openedTransaction *domains.Transaction, klineInterval string) *domains.Transaction {
+	closedTransaction := s.ExecutionSink.CloseByFixedStopLossOrTakeProfit(coin, openedTransaction, klineInterval)
+	if closedTransaction == nil {
+		return nil
+	}
+
+	closeSignal := NewCloseSignal(coin, openedTransaction, closedTransaction.Price, "")
+	closeSignal.Reason = closedTransaction.CloseReason.String
+	return s.RecordingSink.record(closeSignal, closedTransaction)
+}
+
+func (s *CompositeSink) SetFuturesLeverage(coin *domains.Coin, leverage int) error {
+	return s.ExecutionSink.SetFuturesLeverage(coin, leverage)
+}
+
+func (s *CompositeSink) SetIsolatedMargin(coin *domains.Coin, leverage int) error {
+	return s.ExecutionSink.SetIsolatedMargin(coin, leverage)
+}
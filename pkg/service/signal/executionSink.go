@@ -0,0 +1,73 @@
+package signal
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/orders"
+	"go.uber.org/zap"
+)
+
+// ExecutionSink places orders of signals, positions are opened transactions
+func NewExecutionSink(transactionRepo repository.Transaction, orderManagerService *orders.OrderManagerService, tradingStrategy constants.TradingStrategy) *ExecutionSink {
+	return &ExecutionSink{
+		TransactionRepo:     transactionRepo,
+		OrderManagerService: orderManagerService,
+		tradingStrategy:     tradingStrategy,
+	}
+}
+
+type ExecutionSink struct {
+	TransactionRepo     repository.Transaction
+	OrderManagerService *orders.OrderManagerService
+	tradingStrategy     constants.TradingStrategy
+}
+
+func (s *ExecutionSink) Emit(signal *Signal) *domains.Transaction {
+	if signal.Type != constants.SIGNAL_CLOSE {
+		return s.OrderManagerService.OpenOrderWithCostAndFixedStopLossAndTakeProfit(signal.Coin, signal.TradingKey, signal.GetFuturesType(),
+			signal.Cost, signal.StopLossPrice, signal.TakeProfitPrice, signal.TradingType)
+	}
+
+	price := signal.Price
+	if price == 0 {
+		currentPrice, err := s.OrderManagerService.ExchangeDataService.GetCurrentPrice(signal.Coin)
+		if err != nil {
+			zap.S().Errorf("Error during GetCurrentPrice of %s: %s", signal.Coin.Symbol, err.Error())
+			return nil
+		}
+		price = currentPrice
+	}
+	return s.OrderManagerService.CloseOrderWithReason(signal.OpenedTransaction, signal.Coin, price, signal.TradingType, constants.CloseReason(signal.Reason))
+}
+
+func (s *ExecutionSink) FindOpenedTransaction(coin *domains.Coin, tradingKey string) (*domains.Transaction, error) {
+	return s.TransactionRepo.FindOpenedTransactionByCoinAndTradingKey(s.tradingStrategy, coin.Id, tradingKey)
+}
+
+func (s *ExecutionSink) FindAllOpenedTransactions(coin *domains.Coin, tradingKey string) ([]*domains.Transaction, error) {
+	openedTransactions, err := s.TransactionRepo.FindAllOpenedTransactions(s.tradingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domains.Transaction, 0, len(openedTransactions))
+	for _, openedTransaction := range openedTransactions {
+		if openedTransaction.CoinId == coin.Id && openedTransaction.TradingKey == tradingKey {
+			result = append(result, openedTransaction)
+		}
+	}
+	return result, nil
+}
+
+func (s *ExecutionSink) CloseByFixedStopLossOrTakeProfit(coin *domains.Coin, openedTransaction *domains.Transaction, klineInterval string) *domains.Transaction {
+	return s.OrderManagerService.CloseOrderByFixedStopLossOrTakeProfit(coin, openedTransaction, klineInterval)
+}
+
+func (s *ExecutionSink) SetFuturesLeverage(coin *domains.Coin, leverage int) error {
+	return s.OrderManagerService.SetFuturesLeverage(coin, leverage)
+}
+
+func (s *ExecutionSink) SetIsolatedMargin(coin *domains.Coin, leverage int) error {
+	return s.OrderManagerService.SetIsolatedMargin(coin, leverage)
+}
@@ -0,0 +1,191 @@
+package signal
+
+import (
+	telegramApi "cryptoBot/pkg/api/telegram"
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/constants/futureType"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/orders"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+)
+
+// RecordingSink saves signals in signal table and sends them to Telegram without placing orders,
+// positions are open signals without close signal
+func NewRecordingSink(signalRepo repository.Signal, clock date.Clock, orderManagerService *orders.OrderManagerService, tradingStrategy constants.TradingStrategy, name string) *RecordingSink {
+	return &RecordingSink{
+		SignalRepo:          signalRepo,
+		Clock:               clock,
+		OrderManagerService: orderManagerService,
+		tradingStrategy:     tradingStrategy,
+		name:                name,
+	}
+}
+
+type RecordingSink struct {
+	SignalRepo repository.Signal
+	Clock      date.Clock
+	/* Used only for prices and stop loss checks, orders are never placed */
+	OrderManagerService *orders.OrderManagerService
+	tradingStrategy     constants.TradingStrategy
+	/* Strategy name in Telegram messages */
+	name string
+}
+
+func (s *RecordingSink) Emit(signal *Signal) *domains.Transaction {
+	return s.record(signal, nil)
+}
+
+// record saves the signal, transaction is set for executed signal
+func (s *RecordingSink) record(signal *Signal, transaction *domains.Transaction) *domains.Transaction {
+	price := signal.Price
+	if price == 0 {
+		currentPrice, err := s.OrderManagerService.ExchangeDataService.GetCurrentPrice(signal.Coin)
+		if err != nil {
+			zap.S().Errorf("Error during GetCurrentPrice of %s: %s", signal.Coin.Symbol, err.Error())
+			return nil
+		}
+		price = currentPrice
+	}
+
+	domain := &domains.Signal{
+		TradingStrategy: s.tradingStrategy,
+		TradingKey:      signal.TradingKey,
+		CoinId:          signal.Coin.Id,
+		SignalType:      signal.Type,
+		FuturesType:     signal.GetFuturesType(),
+		Reason:          sql.NullString{String: signal.Reason, Valid: signal.Reason != ""},
+		Price:           price,
+		Cost:            signal.Cost,
+		StopLossPrice:   sql.NullFloat64{Float64: signal.StopLossPrice, Valid: signal.StopLossPrice > 0},
+		TakeProfitPrice: sql.NullFloat64{Float64: signal.TakeProfitPrice, Valid: signal.TakeProfitPrice > 0},
+		CreatedAt:       s.Clock.NowTime(),
+	}
+	if transaction != nil {
+		domain.TransactionId = sql.NullInt64{Int64: transaction.Id, Valid: true}
+	}
+	if len(signal.Indicators) > 0 {
+		indicators, err := json.Marshal(signal.Indicators)
+		if err != nil {
+			zap.S().Errorf("Error during marshal indicators of %s: %s", s.name, err.Error())
+		} else {
+			domain.Indicators = sql.NullString{String: string(indicators), Valid: true}
+		}
+	}
+	if signal.Type == constants.SIGNAL_CLOSE {
+		openedSignal, err := s.SignalRepo.FindOpenedSignalByCoinAndTradingKey(s.tradingStrategy, signal.Coin.Id, signal.TradingKey)
+		if err != nil {
+			zap.S().Errorf("Error during FindOpenedSignal of %s: %s", s.name, err.Error())
+		}
+		if openedSignal != nil {
+			domain.RelatedSignalId = sql.NullInt64{Int64: openedSignal.Id, Valid: true}
+		}
+	}
+
+	if err := s.SignalRepo.SaveSignal(domain); err != nil {
+		return nil
+	}
+	telegramApi.SendTextToTelegramChat(s.formatMessage(signal, domain))
+
+	if transaction != nil {
+		return transaction
+	}
+	return s.toVirtualTransaction(domain)
+}
+
+func (s *RecordingSink) FindOpenedTransaction(coin *domains.Coin, tradingKey string) (*domains.Transaction, error) {
+	openedSignal, err := s.SignalRepo.FindOpenedSignalByCoinAndTradingKey(s.tradingStrategy, coin.Id, tradingKey)
+	if err != nil || openedSignal == nil {
+		return nil, err
+	}
+	return s.toVirtualTransaction(openedSignal), nil
+}
+
+func (s *RecordingSink) FindAllOpenedTransactions(coin *domains.Coin, tradingKey string) ([]*domains.Transaction, error) {
+	openedSignals, err := s.SignalRepo.FindAllOpenedSignalsByCoinAndTradingKey(s.tradingStrategy, coin.Id, tradingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domains.Transaction, 0, len(openedSignals))
+	for _, openedSignal := range openedSignals {
+		result = append(result, s.toVirtualTransaction(openedSignal))
+	}
+	return result, nil
+}
+
+func (s *RecordingSink) CloseByFixedStopLossOrTakeProfit(coin *domains.Coin, openedTransaction *domains.Transaction, klineInterval string) *domains.Transaction {
+	if s.OrderManagerService.ShouldCloseByStopLoss(openedTransaction, klineInterval) {
+		return s.Emit(NewCloseSignal(coin, openedTransaction, openedTransaction.StopLossPrice.Float64, constants.CLOSE_REASON_STOP_LOSS))
+	}
+	if s.OrderManagerService.ShouldCloseByTakeProfit(openedTransaction, klineInterval) {
+		return s.Emit(NewCloseSignal(coin, openedTransaction, openedTransaction.TakeProfitPrice.Float64, constants.CLOSE_REASON_TAKE_PROFIT))
+	}
+	return nil
+}
+
+func (s *RecordingSink) SetFuturesLeverage(coin *domains.Coin, leverage int) error {
+	return nil
+}
+
+func (s *RecordingSink) SetIsolatedMargin(coin *domains.Coin, leverage int) error {
+	return nil
+}
+
+// toVirtualTransaction represents open signal as fake transaction, its id is id of the signal
+func (s *RecordingSink) toVirtualTransaction(signal *domains.Signal) *domains.Transaction {
+	// open long and close short buy, open short and close long sell
+	transactionType := constants.BUY
+	if (signal.FuturesType == futureType.SHORT) != (signal.SignalType == constants.SIGNAL_CLOSE) {
+		transactionType = constants.SELL
+	}
+
+	transaction := &domains.Transaction{
+		Id:              signal.Id,
+		CoinId:          signal.CoinId,
+		TransactionType: transactionType,
+		Price:           signal.Price,
+		TotalCost:       signal.Cost,
+		StopLossPrice:   signal.StopLossPrice,
+		TakeProfitPrice: signal.TakeProfitPrice,
+		CreatedAt:       signal.CreatedAt,
+		TradingStrategy: signal.TradingStrategy,
+		FuturesType:     signal.FuturesType,
+		IsFake:          true,
+		TradingKey:      signal.TradingKey,
+	}
+	if signal.Price > 0 {
+		transaction.Amount = signal.Cost / signal.Price
+	}
+	if signal.SignalType == constants.SIGNAL_CLOSE {
+		transaction.RelatedTransactionId = signal.RelatedSignalId
+		transaction.CloseReason = signal.Reason
+	}
+	return transaction
+}
+
+func (s *RecordingSink) formatMessage(signal *Signal, domain *domains.Signal) string {
+	message := fmt.Sprintf("SIGNAL %s %s %s at %v", s.name, signal.Type, signal.Coin.Symbol, domain.Price)
+	if signal.Reason != "" {
+		message += " (" + signal.Reason + ")"
+	}
+	if len(signal.Indicators) > 0 {
+		names := make([]string, 0, len(signal.Indicators))
+		for name := range signal.Indicators {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		values := make([]string, 0, len(names))
+		for _, name := range names {
+			values = append(values, fmt.Sprintf("%s=%.4f", name, signal.Indicators[name]))
+		}
+		message += ": " + strings.Join(values, ", ")
+	}
+	return message
+}
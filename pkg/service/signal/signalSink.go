@@ -0,0 +1,104 @@
+package signal
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/constants/futureType"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/orders"
+	"fmt"
+)
+
+// Signal is decision of the strategy, strategies emit signals to the Sink instead of placing orders
+type Signal struct {
+	Type       constants.SignalType
+	Coin       *domains.Coin
+	TradingKey string
+	/* Close reason or entry rule */
+	Reason string
+	/* Price the decision was made on, current price is used for execution when it's 0 */
+	Price           float64
+	Cost            float64
+	StopLossPrice   float64
+	TakeProfitPrice float64
+	TradingType     constants.TradingType
+	/* Values of indicators the decision was made on */
+	Indicators map[string]float64
+	/* Position closed by SIGNAL_CLOSE */
+	OpenedTransaction *domains.Transaction
+}
+
+func NewOpenSignal(coin *domains.Coin, tradingKey string, futuresType futureType.FuturesType, cost float64, reason string) *Signal {
+	signalType := constants.SIGNAL_OPEN_LONG
+	if futuresType == futureType.SHORT {
+		signalType = constants.SIGNAL_OPEN_SHORT
+	}
+	return &Signal{
+		Type:        signalType,
+		Coin:        coin,
+		TradingKey:  tradingKey,
+		Reason:      reason,
+		Cost:        cost,
+		TradingType: constants.FUTURES,
+	}
+}
+
+func NewCloseSignal(coin *domains.Coin, openedTransaction *domains.Transaction, price float64, closeReason constants.CloseReason) *Signal {
+	return &Signal{
+		Type:              constants.SIGNAL_CLOSE,
+		Coin:              coin,
+		TradingKey:        openedTransaction.TradingKey,
+		Reason:            string(closeReason),
+		Price:             price,
+		TradingType:       constants.FUTURES,
+		OpenedTransaction: openedTransaction,
+	}
+}
+
+func (s *Signal) GetFuturesType() futureType.FuturesType {
+	if s.Type == constants.SIGNAL_CLOSE && s.OpenedTransaction != nil {
+		return s.OpenedTransaction.FuturesType
+	}
+	if s.Type == constants.SIGNAL_OPEN_SHORT {
+		return futureType.SHORT
+	}
+	return futureType.LONG
+}
+
+// Sink executes or records signals and keeps positions of the strategy, so the strategy works the same in every mode
+type Sink interface {
+	// Emit returns transaction of the executed signal, virtual transaction of the recorded open signal or nil on failure
+	Emit(signal *Signal) *domains.Transaction
+	FindOpenedTransaction(coin *domains.Coin, tradingKey string) (*domains.Transaction, error)
+	// FindAllOpenedTransactions returns positions of strategies adding to them, the newest first
+	FindAllOpenedTransactions(coin *domains.Coin, tradingKey string) ([]*domains.Transaction, error)
+	// CloseByFixedStopLossOrTakeProfit closes the position when its stop loss or take profit was reached
+	CloseByFixedStopLossOrTakeProfit(coin *domains.Coin, openedTransaction *domains.Transaction, klineInterval string) *domains.Transaction
+	// SetFuturesLeverage is skipped when orders aren't placed, so signals are recorded without credentials
+	SetFuturesLeverage(coin *domains.Coin, leverage int) error
+	SetIsolatedMargin(coin *domains.Coin, leverage int) error
+}
+
+func NewSink(
+	mode constants.SignalMode,
+	transactionRepo repository.Transaction,
+	signalRepo repository.Signal,
+	clock date.Clock,
+	orderManagerService *orders.OrderManagerService,
+	tradingStrategy constants.TradingStrategy,
+	name string,
+) (Sink, error) {
+	switch mode {
+	case constants.SIGNAL_MODE_EXECUTE, "":
+		return NewExecutionSink(transactionRepo, orderManagerService, tradingStrategy), nil
+	case constants.SIGNAL_MODE_RECORD:
+		return NewRecordingSink(signalRepo, clock, orderManagerService, tradingStrategy, name), nil
+	case constants.SIGNAL_MODE_BOTH:
+		return NewCompositeSink(
+			NewExecutionSink(transactionRepo, orderManagerService, tradingStrategy),
+			NewRecordingSink(signalRepo, clock, orderManagerService, tradingStrategy, name),
+		), nil
+	}
+	return nil, fmt.Errorf("unknown signal mode [%s], expected execute, signal or both", mode)
+}
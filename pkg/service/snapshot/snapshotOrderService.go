@@ -18,19 +18,13 @@ import (
 	"time"
 )
 
-var snapshotOrderServiceImpl *SnapshotOrderService
-
 func NewSnapshotOrderService(transactionRepo repository.Transaction, klineRepo repository.Kline,
 	localExtremumTrendService *indicator.LocalExtremumTrendService) *SnapshotOrderService {
-	if snapshotOrderServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	snapshotOrderServiceImpl = &SnapshotOrderService{
+	return &SnapshotOrderService{
 		klineRepo:                 klineRepo,
 		transactionRepo:           transactionRepo,
 		LocalExtremumTrendService: localExtremumTrendService,
 	}
-	return snapshotOrderServiceImpl
 }
 
 type SnapshotOrderService struct {
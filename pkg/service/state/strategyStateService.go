@@ -0,0 +1,167 @@
+package state
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"fmt"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+)
+
+// StrategyState is key/value state of one strategy instance, changed values are saved by StrategyStateService.Save
+type StrategyState struct {
+	StrategyKey string
+	entries     map[string]*domains.StrategyState
+	changed     map[string]bool
+}
+
+func (st *StrategyState) GetString(key string, defaultValue string) string {
+	if entry, ok := st.entries[key]; ok {
+		return entry.Value
+	}
+	return defaultValue
+}
+
+func (st *StrategyState) SetString(key string, value string) {
+	entry, ok := st.entries[key]
+	if !ok {
+		entry = &domains.StrategyState{StrategyKey: st.StrategyKey, StateKey: key}
+		st.entries[key] = entry
+	} else if entry.Value == value {
+		return
+	}
+	entry.Value = value
+	st.changed[key] = true
+}
+
+func (st *StrategyState) GetBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(st.GetString(key, strconv.FormatBool(defaultValue)))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func (st *StrategyState) SetBool(key string, value bool) {
+	st.SetString(key, strconv.FormatBool(value))
+}
+
+func (st *StrategyState) GetFloat64(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(st.GetString(key, ""), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func (st *StrategyState) SetFloat64(key string, value float64) {
+	st.SetString(key, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+func NewStrategyStateService(strategyStateRepo repository.StrategyState, clock date.Clock) *StrategyStateService {
+	return &StrategyStateService{
+		strategyStateRepo: strategyStateRepo,
+		Clock:             clock,
+	}
+}
+
+// StrategyStateService keeps state of strategies in strategy_state, strategies load it in Initialize and save it after Execute
+type StrategyStateService struct {
+	strategyStateRepo repository.StrategyState
+	Clock             date.Clock
+}
+
+func (s *StrategyStateService) Load(strategyKey string) (*StrategyState, error) {
+	entries, err := s.strategyStateRepo.FindAllByStrategyKey(strategyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &StrategyState{
+		StrategyKey: strategyKey,
+		entries:     make(map[string]*domains.StrategyState, len(entries)),
+		changed:     make(map[string]bool),
+	}
+	for _, entry := range entries {
+		st.entries[entry.StateKey] = entry
+	}
+	return st, nil
+}
+
+// Save writes changed values with the next version. Value changed by another instance meanwhile is overwritten:
+// the stored version is reloaded and the write is retried once, any other error is returned
+func (s *StrategyStateService) Save(st *StrategyState) error {
+	for key := range st.changed {
+		entry := st.entries[key]
+		if err := s.save(entry); err != nil {
+			stored, findErr := s.findStored(entry)
+			if findErr != nil || stored == nil || (stored.Id == entry.Id && stored.Version == entry.Version) {
+				return err
+			}
+
+			zap.S().Warnf("State %s is changed by another instance to [%s] v%d, it's overwritten", entry.String(), stored.Value, stored.Version)
+			entry.Id = stored.Id
+			entry.Version = stored.Version
+			if err := s.save(entry); err != nil {
+				return err
+			}
+		}
+		delete(st.changed, key)
+	}
+	return nil
+}
+
+func (s *StrategyStateService) save(entry *domains.StrategyState) error {
+	entry.Version++
+	entry.UpdatedAt = s.Clock.NowTime()
+	if err := s.strategyStateRepo.SaveStrategyState(entry); err != nil {
+		entry.Version--
+		return err
+	}
+	return nil
+}
+
+// findStored returns the entry as it's saved now, nil when it isn't saved
+func (s *StrategyStateService) findStored(entry *domains.StrategyState) (*domains.StrategyState, error) {
+	entries, err := s.strategyStateRepo.FindAllByStrategyKey(entry.StrategyKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, stored := range entries {
+		if stored.StateKey == entry.StateKey {
+			return stored, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *StrategyStateService) FindAll(strategyKeyPrefix string) ([]*domains.StrategyState, error) {
+	return s.strategyStateRepo.FindAllByStrategyKeyPrefix(strategyKeyPrefix)
+}
+
+// Describe formats states of strategies with the key prefix for Telegram
+func (s *StrategyStateService) Describe(strategyKeyPrefix string) string {
+	entries, err := s.FindAll(strategyKeyPrefix)
+	if err != nil {
+		return fmt.Sprintf("Error during loading state: %s", err.Error())
+	}
+	if len(entries) == 0 {
+		return "No state of " + strategyKeyPrefix + "*"
+	}
+
+	var builder strings.Builder
+	builder.WriteString("<pre>\n")
+	strategyKey := ""
+	for _, entry := range entries {
+		if entry.StrategyKey != strategyKey {
+			strategyKey = entry.StrategyKey
+			builder.WriteString(strategyKey + "\n")
+		}
+		builder.WriteString(fmt.Sprintf("  %s = %s (v%d, %s)\n", entry.StateKey, entry.Value, entry.Version, entry.UpdatedAt.Format(constants.DATE_TIME_FORMAT)))
+	}
+	builder.WriteString("</pre>")
+	return builder.String()
+}
@@ -5,25 +5,25 @@ import (
 	"cryptoBot/pkg/constants"
 	"cryptoBot/pkg/data/domains"
 	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/trading"
 	"cryptoBot/pkg/util"
 	"fmt"
-	"github.com/spf13/viper"
 )
 
 type IStatisticService interface {
 	BuildStatistics() string
 	BuildHourStatistics() string
+	BuildEquityCurveStatistics() string
 }
 
-var statisticPairTradingServiceImpl *StatisticPairTradingService
-
 func NewStatisticPairTradingService(transactionRepo repository.Transaction, coinRepo repository.Coin, exchangeApi api.ExchangeApi) *StatisticPairTradingService {
-	statisticPairTradingServiceImpl = &StatisticPairTradingService{
+	return &StatisticPairTradingService{
 		transactionRepo: transactionRepo,
 		coinRepo:        coinRepo,
 		exchangeApi:     exchangeApi,
 	}
-	return statisticPairTradingServiceImpl
 }
 
 type StatisticPairTradingService struct {
@@ -33,17 +33,15 @@ type StatisticPairTradingService struct {
 }
 
 func (s *StatisticPairTradingService) BuildHourStatistics() string {
-	coins := viper.GetStringSlice("strategy.pairArbitrage.coins")
+	pairs, _ := trading.LoadPairArbitrageConfigs()
 
 	var response = "<pre>\n" +
 		"| Coin1 | Coin2 |      Date open      |   Profit   |\n" +
 		"|-------|-------|---------------------|------------|"
 
-	for i := 0; i < len(coins); i += 4 {
-		symbol1 := coins[i]
-		symbol2 := coins[i+1]
-		coin1, _ := s.coinRepo.FindBySymbol(symbol1)
-		coin2, _ := s.coinRepo.FindBySymbol(symbol2)
+	for _, pair := range pairs {
+		coin1, _ := s.coinRepo.FindBySymbol(pair.Coin1)
+		coin2, _ := s.coinRepo.FindBySymbol(pair.Coin2)
 
 		response += s.BuildHourStatisticsByCoins(coin1, coin2)
 	}
@@ -54,17 +52,15 @@ func (s *StatisticPairTradingService) BuildHourStatistics() string {
 }
 
 func (s *StatisticPairTradingService) BuildStatistics() string {
-	coins := viper.GetStringSlice("strategy.pairArbitrage.coins")
+	pairs, _ := trading.LoadPairArbitrageConfigs()
 
 	var response = "<pre>\n" +
 		"| Coin1 | Coin2 |    Date    |   Profit   |   Percent  | Size |\n" +
 		"|-------|-------|------------|------------|------------|------|"
 
-	for i := 0; i < len(coins); i += 4 {
-		symbol1 := coins[i]
-		symbol2 := coins[i+1]
-		coin1, _ := s.coinRepo.FindBySymbol(symbol1)
-		coin2, _ := s.coinRepo.FindBySymbol(symbol2)
+	for _, pair := range pairs {
+		coin1, _ := s.coinRepo.FindBySymbol(pair.Coin1)
+		coin2, _ := s.coinRepo.FindBySymbol(pair.Coin2)
 
 		response += s.BuildStatisticsByCoins(coin1, coin2)
 	}
@@ -120,3 +116,36 @@ func (s *StatisticPairTradingService) BuildStatisticsByCoins(coin1 *domains.Coin
 
 	return response
 }
+
+// BuildEquityCurveStatistics compares the shadow curve of all trades with the real curve of trades placed on the exchange
+func (s *StatisticPairTradingService) BuildEquityCurveStatistics() string {
+	pairs, _ := trading.LoadPairArbitrageConfigs()
+	equityCurveFilterService := orders.NewEquityCurveFilterService(s.transactionRepo, date.GetClock(), 0, 0)
+
+	var response = "<pre>\n" +
+		"| Coin1 | Coin2 |   Shadow   |    Real    | Shadow DD  |  Real DD   | Fake |\n" +
+		"|-------|-------|------------|------------|------------|------------|------|"
+
+	for _, pair := range pairs {
+		tradingStrategy := pair.ToStrategyConfig().GetTradingStrategy(constants.PAIR_ARBITRAGE)
+		shadowCurve, err1 := equityCurveFilterService.BuildShadowCurve(tradingStrategy)
+		realCurve, err2 := equityCurveFilterService.BuildRealCurve(tradingStrategy)
+		if err1 != nil || err2 != nil {
+			response += "\n failed BuildEquityCurve " + pair.Coin1 + " " + pair.Coin2
+			continue
+		}
+
+		response += fmt.Sprintf("\n| %5v | %5v | %9.2f%% | %9.2f%% | %9.2f%% | %9.2f%% | %4v |",
+			pair.Coin1[:len(pair.Coin1)-4],
+			pair.Coin2[:len(pair.Coin2)-4],
+			orders.CalculateLastEquity(shadowCurve)-orders.EQUITY_CURVE_START,
+			orders.CalculateLastEquity(realCurve)-orders.EQUITY_CURVE_START,
+			orders.CalculateMaxDrawdownPercent(shadowCurve),
+			orders.CalculateMaxDrawdownPercent(realCurve),
+			len(shadowCurve)-len(realCurve))
+	}
+
+	response += "\n</pre>"
+
+	return response
+}
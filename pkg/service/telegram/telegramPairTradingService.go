@@ -5,31 +5,28 @@ import (
 	telegramApi "cryptoBot/pkg/api/telegram"
 	"cryptoBot/pkg/data/dto/telegram"
 	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/state"
 	"cryptoBot/pkg/service/statistic"
 	"strings"
 )
 
-var telegramPairTradingServiceImpl ITelegramService
-
 func NewTelegramPairTradingService(transactionRepo repository.Transaction, coinRepo repository.Coin,
-	exchangeApi api.ExchangeApi, statisticPairTradingService statistic.IStatisticService) ITelegramService {
-	if telegramPairTradingServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	telegramPairTradingServiceImpl = &TelegramPairTradingService{
-		transactionRepo:  transactionRepo,
-		coinRepo:         coinRepo,
-		exchangeApi:      exchangeApi,
-		statisticService: statisticPairTradingService,
+	exchangeApi api.ExchangeApi, statisticPairTradingService statistic.IStatisticService, strategyStateService *state.StrategyStateService) ITelegramService {
+	return &TelegramPairTradingService{
+		transactionRepo:      transactionRepo,
+		coinRepo:             coinRepo,
+		exchangeApi:          exchangeApi,
+		statisticService:     statisticPairTradingService,
+		strategyStateService: strategyStateService,
 	}
-	return telegramPairTradingServiceImpl
 }
 
 type TelegramPairTradingService struct {
-	transactionRepo  repository.Transaction
-	coinRepo         repository.Coin
-	exchangeApi      api.ExchangeApi
-	statisticService statistic.IStatisticService
+	transactionRepo      repository.Transaction
+	coinRepo             repository.Coin
+	exchangeApi          api.ExchangeApi
+	statisticService     statistic.IStatisticService
+	strategyStateService *state.StrategyStateService
 }
 
 func (s *TelegramPairTradingService) HandleMessage(update *telegram.Update) {
@@ -41,6 +38,10 @@ func (s *TelegramPairTradingService) HandleMessage(update *telegram.Update) {
 func (s *TelegramPairTradingService) buildResponse(update *telegram.Update) string {
 	if strings.HasPrefix(update.Message.Text, COMMAND_STATS) {
 		return s.statisticService.BuildStatistics()
+	} else if strings.HasPrefix(update.Message.Text, COMMAND_EQUITY) {
+		return s.statisticService.BuildEquityCurveStatistics()
+	} else if strings.HasPrefix(update.Message.Text, COMMAND_STATE) && s.strategyStateService != nil {
+		return s.strategyStateService.Describe(strings.TrimSpace(strings.TrimPrefix(update.Message.Text, COMMAND_STATE)))
 	}
 	return "Unexpected command"
 }
@@ -21,19 +21,15 @@ const COMMAND_PROFIT string = "/profit"
 const COMMAND_BUY_STOP string = "/stop_buying"
 const COMMAND_BUY_START string = "/start_buying"
 const COMMAND_LIMIT_SPEND string = "/limit_spend"
-
-var telegramServiceImpl *TelegramService
+const COMMAND_STATE string = "/state" // /state [strategy key prefix]
+const COMMAND_EQUITY string = "/equity"
 
 func NewTelegramService(transactionRepo repository.Transaction, coinRepo repository.Coin, exchangeApi api.ExchangeApi) *TelegramService {
-	if telegramServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	telegramServiceImpl = &TelegramService{
+	return &TelegramService{
 		transactionRepo: transactionRepo,
 		coinRepo:        coinRepo,
 		exchangeApi:     exchangeApi,
 	}
-	return telegramServiceImpl
 }
 
 type ITelegramService interface {
@@ -0,0 +1,288 @@
+package trading
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/constants/futureType"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/exchange"
+	"cryptoBot/pkg/service/indicator"
+	"cryptoBot/pkg/service/indicator/techanLib"
+	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/signal"
+	"cryptoBot/pkg/util"
+	"github.com/sdcoffey/techan"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// NewBreakoutStrategyConfigs reads strategy.breakout.strategies from config.yml
+func NewBreakoutStrategyConfigs() []*StrategyConfig {
+	return loadStrategyConfigs(BREAKOUT_STRATEGY, "strategy.breakout.strategies")
+}
+
+// Donchian (Turtle) breakout strategy opens long when the close breaks the highest high of entryLength klines
+// and short when it breaks the lowest low. One more unit is added every pyramidAtrStep * N in profit, where N is ATR
+// on the first entry. All units are closed on the stop stopAtrMultiplier * N from the last unit
+// or when the close breaks the opposite channel of exitLength klines.
+func NewBreakoutStrategyTradingService(
+	transactionRepo repository.Transaction,
+	clock date.Clock,
+	exchangeDataService *exchange.DataService,
+	klinesFetcherService *exchange.KlinesFetcherService,
+	orderManagerService *orders.OrderManagerService,
+	signalSink signal.Sink,
+	techanConvertorService *techanLib.TechanConvertorService,
+	coin *domains.Coin,
+	name string,
+	klineInterval int,
+) *BreakoutStrategyTradingService {
+	return &BreakoutStrategyTradingService{
+		TransactionRepo:        transactionRepo,
+		Clock:                  clock,
+		ExchangeDataService:    exchangeDataService,
+		KlinesFetcherService:   klinesFetcherService,
+		OrderManagerService:    orderManagerService,
+		SignalSink:             signalSink,
+		TechanConvertorService: techanConvertorService,
+		coin:                   coin,
+		name:                   name,
+		klineInterval:          klineInterval,
+		klineIntervalS:         strconv.Itoa(klineInterval),
+		entryLength:            20,
+		exitLength:             10,
+		atrLength:              20,
+		stopAtrMultiplier:      2,
+		pyramidAtrStep:         0.5,
+		maxUnits:               4,
+		allowLong:              true,
+		allowShort:             true,
+		leverage:               1,
+		costOfOrderInCents:     100 * 100,
+		riskPerUnitInCents:     0, //disabled
+		tradingStrategy:        constants.BREAKOUT,
+	}
+}
+
+type BreakoutStrategyTradingService struct {
+	TransactionRepo        repository.Transaction
+	Clock                  date.Clock
+	ExchangeDataService    *exchange.DataService
+	KlinesFetcherService   *exchange.KlinesFetcherService
+	OrderManagerService    *orders.OrderManagerService
+	SignalSink             signal.Sink
+	TechanConvertorService *techanLib.TechanConvertorService
+	/* Positions are opened in sessions outside of blackouts only, closing is always allowed */
+	SessionsService *indicator.SessionsService
+	coin            *domains.Coin
+	/* Prefix of trading keys of units */
+	name           string
+	klineInterval  int
+	klineIntervalS string
+	entryLength    int
+	exitLength     int
+	atrLength      int
+	/* Stop of all units is stopAtrMultiplier * N from the price of the last unit */
+	stopAtrMultiplier float64
+	/* Unit is added when the price moves pyramidAtrStep * N from the last unit */
+	pyramidAtrStep float64
+	maxUnits       int
+	allowLong      bool
+	allowShort     bool
+	leverage       int
+	/* Cost of every unit, used when riskPerUnitInCents is disabled */
+	costOfOrderInCents int
+	/* Turtle sizing: unit loses riskPerUnitInCents on the stop */
+	riskPerUnitInCents int
+	tradingStrategy    constants.TradingStrategy
+
+	/* N is calculated once per position by the first unit */
+	atrTransactionId int64
+	atr              float64
+}
+
+func (s *BreakoutStrategyTradingService) BotAction(coin *domains.Coin) {
+	return
+}
+
+func (s *BreakoutStrategyTradingService) InitializeTrading(coin *domains.Coin) error {
+	return nil
+}
+
+func (s *BreakoutStrategyTradingService) Initialize() error {
+	err := s.SignalSink.SetFuturesLeverage(s.coin, s.leverage)
+	if err != nil {
+		return err
+	}
+
+	s.KlinesFetcherService.FetchActualKlines(s.coin, s.klineInterval)
+
+	return nil
+}
+
+func (s *BreakoutStrategyTradingService) BeforeExecute() {
+	return
+}
+
+func (s *BreakoutStrategyTradingService) Execute() {
+	s.KlinesFetcherService.FetchActualKlines(s.coin, s.klineInterval)
+
+	units, err := s.findOpenedUnits()
+	if err != nil {
+		zap.S().Errorf("Error during findOpenedUnits of %s: %s", s.name, err.Error())
+		return
+	}
+
+	series := s.buildSeries(s.Clock.NowTime())
+	if series == nil {
+		zap.S().Errorf("Not enough klines for %s at %s", s.coin.Symbol, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		return
+	}
+	index := series.LastIndex()
+	closePrice := series.LastCandle().ClosePrice.Float()
+
+	if len(units) == 0 {
+		// channel of the previous klines, the last one must break it
+		entryHigh := techan.NewMaximumValueIndicator(techan.NewHighPriceIndicator(series), s.entryLength).Calculate(index - 1).Float()
+		entryLow := techan.NewMinimumValueIndicator(techan.NewLowPriceIndicator(series), s.entryLength).Calculate(index - 1).Float()
+		atr := techan.NewAverageTrueRangeIndicator(series, s.atrLength).Calculate(index).Float()
+
+		if s.allowLong && closePrice > entryHigh {
+			zap.S().Infof("LONG BREAKOUT close %.4f > %.4f at %v", closePrice, entryHigh, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+			s.openUnit(0, futureType.LONG, atr)
+		} else if s.allowShort && closePrice < entryLow {
+			zap.S().Infof("SHORT BREAKOUT close %.4f < %.4f at %v", closePrice, entryLow, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+			s.openUnit(0, futureType.SHORT, atr)
+		}
+		return
+	}
+
+	atr := s.getEntryAtr(units[0])
+	if atr <= 0 {
+		return
+	}
+	currentPrice, err := s.ExchangeDataService.GetCurrentPriceWithInterval(s.coin, s.klineInterval)
+	if err != nil {
+		zap.S().Errorf("Error during GetCurrentPrice at %v: %s", s.Clock.NowTime(), err.Error())
+		return
+	}
+
+	futuresType := units[0].FuturesType
+	lastUnit := units[len(units)-1]
+	// sign turns distances of short position into the same comparisons as of long one
+	sign := float64(1)
+	if futuresType == futureType.SHORT {
+		sign = -1
+	}
+
+	stopPrice := lastUnit.Price - sign*s.stopAtrMultiplier*atr
+	if sign*(currentPrice-stopPrice) <= 0 {
+		zap.S().Infof("STOP %s price %.4f stop %.4f at %v", s.name, currentPrice, stopPrice, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		s.closeUnits(units, currentPrice, constants.CLOSE_REASON_STOP_LOSS)
+		return
+	}
+
+	exitPrice := techan.NewMinimumValueIndicator(techan.NewLowPriceIndicator(series), s.exitLength).Calculate(index - 1).Float()
+	if futuresType == futureType.SHORT {
+		exitPrice = techan.NewMaximumValueIndicator(techan.NewHighPriceIndicator(series), s.exitLength).Calculate(index - 1).Float()
+	}
+	if sign*(closePrice-exitPrice) < 0 {
+		zap.S().Infof("EXIT %s close %.4f broke %.4f at %v", s.name, closePrice, exitPrice, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		s.closeUnits(units, currentPrice, constants.CLOSE_REASON_SIGNAL)
+		return
+	}
+
+	if len(units) < s.maxUnits && sign*(currentPrice-lastUnit.Price) >= s.pyramidAtrStep*atr {
+		zap.S().Infof("PYRAMID %s unit %d at %.4f", s.name, len(units), currentPrice)
+		s.openUnit(len(units), futuresType, atr)
+	}
+}
+
+func (s *BreakoutStrategyTradingService) buildSeries(moment time.Time) *techan.TimeSeries {
+	lookback := s.entryLength
+	for _, length := range []int{s.exitLength, s.atrLength} {
+		if length > lookback {
+			lookback = length
+		}
+	}
+
+	series := s.TechanConvertorService.BuildTimeSeriesByKlinesAtMoment(s.coin, s.klineIntervalS, int64(lookback*2+1), moment)
+	if series == nil || series.LastIndex() <= lookback {
+		return nil
+	}
+	return series
+}
+
+// findOpenedUnits returns units in order of opening, units are keyed <name>#<index>
+func (s *BreakoutStrategyTradingService) findOpenedUnits() ([]*domains.Transaction, error) {
+	units := make([]*domains.Transaction, 0, s.maxUnits)
+	for i := 0; i < s.maxUnits; i++ {
+		unit, err := s.SignalSink.FindOpenedTransaction(s.coin, s.getTradingKey(i))
+		if err != nil {
+			return nil, err
+		}
+		if unit == nil {
+			break
+		}
+		units = append(units, unit)
+	}
+	return units, nil
+}
+
+// getEntryAtr restores N of the position by klines before the first unit, so it survives restart
+func (s *BreakoutStrategyTradingService) getEntryAtr(firstUnit *domains.Transaction) float64 {
+	if s.atrTransactionId == firstUnit.Id {
+		return s.atr
+	}
+
+	series := s.buildSeries(firstUnit.CreatedAt)
+	if series == nil {
+		zap.S().Errorf("Not enough klines for ATR of %s at %s", s.name, firstUnit.CreatedAt.Format(constants.DATE_TIME_FORMAT))
+		return 0
+	}
+
+	s.atrTransactionId = firstUnit.Id
+	s.atr = techan.NewAverageTrueRangeIndicator(series, s.atrLength).Calculate(series.LastIndex()).Float()
+	return s.atr
+}
+
+func (s *BreakoutStrategyTradingService) openUnit(unitIndex int, futuresType futureType.FuturesType, atr float64) {
+	if s.SessionsService != nil && !s.SessionsService.IsTradingAllowedNow() {
+		zap.S().Infof("Trading of %s isn't allowed by sessions at %v", s.coin.Symbol, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		return
+	}
+
+	cost := util.GetDollarsByCents(int64(s.costOfOrderInCents))
+	if s.riskPerUnitInCents > 0 {
+		if atr <= 0 {
+			return
+		}
+		currentPrice, err := s.ExchangeDataService.GetCurrentPriceWithInterval(s.coin, s.klineInterval)
+		if err != nil {
+			zap.S().Errorf("Error during GetCurrentPrice at %v: %s", s.Clock.NowTime(), err.Error())
+			return
+		}
+		amount := util.GetDollarsByCents(int64(s.riskPerUnitInCents)) / (s.stopAtrMultiplier * atr)
+		cost = amount * currentPrice
+	}
+
+	openSignal := signal.NewOpenSignal(s.coin, s.getTradingKey(unitIndex), futuresType, cost, "donchian breakout unit "+strconv.Itoa(unitIndex))
+	openSignal.Indicators = map[string]float64{"atr": atr, "unit": float64(unitIndex)}
+	unit := s.SignalSink.Emit(openSignal)
+	if unit != nil && unitIndex == 0 {
+		s.atrTransactionId = unit.Id
+		s.atr = atr
+	}
+}
+
+func (s *BreakoutStrategyTradingService) closeUnits(units []*domains.Transaction, price float64, closeReason constants.CloseReason) {
+	for _, unit := range units {
+		s.SignalSink.Emit(signal.NewCloseSignal(s.coin, unit, price, closeReason))
+	}
+}
+
+func (s *BreakoutStrategyTradingService) getTradingKey(unitIndex int) string {
+	return s.name + "#" + strconv.Itoa(unitIndex)
+}
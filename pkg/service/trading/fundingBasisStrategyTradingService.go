@@ -0,0 +1,289 @@
+package trading
+
+import (
+	telegramApi "cryptoBot/pkg/api/telegram"
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/constants/futureType"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/exchange"
+	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/signal"
+	"cryptoBot/pkg/util"
+	"fmt"
+	"go.uber.org/zap"
+)
+
+// NewFundingBasisStrategyConfigs reads strategy.fundingBasis.strategies from config.yml
+func NewFundingBasisStrategyConfigs() []*StrategyConfig {
+	return loadStrategyConfigs(FUNDING_BASIS_STRATEGY, "strategy.fundingBasis.strategies")
+}
+
+// FundingBasisReport splits result of the strategy into price movement of both legs and received funding
+type FundingBasisReport struct {
+	PriceProfitInCents   int64
+	FundingIncomeInCents int64
+}
+
+func (r FundingBasisReport) TotalInCents() int64 {
+	return r.PriceProfitInCents + r.FundingIncomeInCents
+}
+
+func (r FundingBasisReport) String() string {
+	return fmt.Sprintf("price PnL %s, funding income %s, total %s",
+		util.RoundCentsToUsd(r.PriceProfitInCents), util.RoundCentsToUsd(r.FundingIncomeInCents), util.RoundCentsToUsd(r.TotalInCents()))
+}
+
+// Funding basis (cash-and-carry) strategy buys spot and shorts the same amount of perpetual when funding
+// or basis is high enough, so price movement of the legs is hedged and the short leg receives funding.
+// Position is held while funding stays above exitFundingRate and both legs are unwound together.
+// Received funding is saved in funding_payment, so it's reported separately from price PnL of transactions.
+func NewFundingBasisStrategyTradingService(
+	transactionRepo repository.Transaction,
+	fundingRateRepo repository.FundingRate,
+	clock date.Clock,
+	exchangeDataService *exchange.DataService,
+	klinesFetcherService *exchange.KlinesFetcherService,
+	fundingRateFetcherService *exchange.FundingRateFetcherService,
+	orderManagerService *orders.OrderManagerService,
+	signalSink signal.Sink,
+	coin *domains.Coin,
+	name string,
+	klineInterval int,
+) *FundingBasisStrategyTradingService {
+	return &FundingBasisStrategyTradingService{
+		TransactionRepo:           transactionRepo,
+		FundingRateRepo:           fundingRateRepo,
+		Clock:                     clock,
+		ExchangeDataService:       exchangeDataService,
+		KlinesFetcherService:      klinesFetcherService,
+		FundingRateFetcherService: fundingRateFetcherService,
+		OrderManagerService:       orderManagerService,
+		SignalSink:                signalSink,
+		coin:                      coin,
+		name:                      name,
+		klineInterval:             klineInterval,
+		entryFundingRate:          0.0003,
+		exitFundingRate:           0,
+		entryBasisPercent:         0,
+		costOfOrderInCents:        10000,
+		leverage:                  1,
+		tradingStrategy:           constants.FUNDING_BASIS,
+	}
+}
+
+type FundingBasisStrategyTradingService struct {
+	TransactionRepo           repository.Transaction
+	FundingRateRepo           repository.FundingRate
+	Clock                     date.Clock
+	ExchangeDataService       *exchange.DataService
+	KlinesFetcherService      *exchange.KlinesFetcherService
+	FundingRateFetcherService *exchange.FundingRateFetcherService
+	OrderManagerService       *orders.OrderManagerService
+	SignalSink                signal.Sink
+	coin                      *domains.Coin
+	/* Prefix of trading keys of both legs */
+	name          string
+	klineInterval int
+	/* Position is opened when the last funding rate is at least entryFundingRate (0.0003 = 0.03% per 8h) */
+	entryFundingRate float64
+	/* Position is closed when the last funding rate drops to exitFundingRate */
+	exitFundingRate float64
+	/* Position is also opened when (perpetual - spot) / spot * 100 is at least entryBasisPercent, 0 disables it */
+	entryBasisPercent  float64
+	costOfOrderInCents int
+	leverage           int
+	tradingStrategy    constants.TradingStrategy
+}
+
+func (s *FundingBasisStrategyTradingService) BotAction(coin *domains.Coin) {
+	return
+}
+
+func (s *FundingBasisStrategyTradingService) InitializeTrading(coin *domains.Coin) error {
+	return nil
+}
+
+func (s *FundingBasisStrategyTradingService) Initialize() error {
+	return s.SignalSink.SetFuturesLeverage(s.coin, s.leverage)
+}
+
+func (s *FundingBasisStrategyTradingService) BeforeExecute() {
+	return
+}
+
+func (s *FundingBasisStrategyTradingService) Execute() {
+	s.KlinesFetcherService.FetchActualKlines(s.coin, s.klineInterval)
+	s.FundingRateFetcherService.FetchActualFundingRates(s.coin)
+
+	spotTransaction, err := s.SignalSink.FindOpenedTransaction(s.coin, s.getSpotTradingKey())
+	if err != nil {
+		zap.S().Errorf("Error during FindOpenedTransaction of %s: %s", s.getSpotTradingKey(), err.Error())
+		return
+	}
+	perpTransaction, err := s.SignalSink.FindOpenedTransaction(s.coin, s.getPerpTradingKey())
+	if err != nil {
+		zap.S().Errorf("Error during FindOpenedTransaction of %s: %s", s.getPerpTradingKey(), err.Error())
+		return
+	}
+
+	if perpTransaction != nil {
+		s.accrueFunding(perpTransaction)
+	}
+
+	lastRate, err := s.FundingRateRepo.FindLastByCoinIdAndFundingTimeLessOrEqual(s.coin.Id, s.Clock.NowTime())
+	if err != nil {
+		zap.S().Errorf("Error during FindLast funding rate of %s: %s", s.coin.Symbol, err.Error())
+		return
+	}
+
+	switch {
+	case spotTransaction == nil && perpTransaction == nil:
+		if s.shouldOpen(lastRate) {
+			s.openPosition()
+		}
+	case spotTransaction == nil || perpTransaction == nil:
+		// one leg was closed by exchange or failed to close, the other one isn't hedged anymore
+		s.closePosition(spotTransaction, perpTransaction, constants.CLOSE_REASON_LEG_CLOSED)
+	case lastRate == nil || lastRate.Rate <= s.exitFundingRate:
+		s.closePosition(spotTransaction, perpTransaction, constants.CLOSE_REASON_FUNDING)
+	}
+}
+
+func (s *FundingBasisStrategyTradingService) shouldOpen(lastRate *domains.FundingRate) bool {
+	if lastRate != nil && lastRate.Rate >= s.entryFundingRate {
+		return true
+	}
+	if s.entryBasisPercent <= 0 {
+		return false
+	}
+
+	basisPercent, err := s.getBasisPercent()
+	if err != nil {
+		zap.S().Errorf("Error during getBasisPercent of %s: %s", s.coin.Symbol, err.Error())
+		return false
+	}
+	return basisPercent >= s.entryBasisPercent
+}
+
+// getBasisPercent compares prices of the exchange, stored klines are futures only
+func (s *FundingBasisStrategyTradingService) getBasisPercent() (float64, error) {
+	spotPrice, err := s.ExchangeDataService.ExchangeApi.GetCurrentCoinPrice(s.coin)
+	if err != nil {
+		return 0, err
+	}
+	perpPrice, err := s.ExchangeDataService.ExchangeApi.GetCurrentCoinPriceForFutures(s.coin)
+	if err != nil {
+		return 0, err
+	}
+	if spotPrice <= 0 {
+		return 0, fmt.Errorf("invalid spot price %v", spotPrice)
+	}
+	return (perpPrice - spotPrice) / spotPrice * 100, nil
+}
+
+func (s *FundingBasisStrategyTradingService) openPosition() {
+	cost := float64(s.costOfOrderInCents) / 100
+
+	spotSignal := signal.NewOpenSignal(s.coin, s.getSpotTradingKey(), futureType.LONG, cost, "basis spot leg")
+	spotSignal.TradingType = constants.SPOT
+	spotTransaction := s.SignalSink.Emit(spotSignal)
+	if spotTransaction == nil {
+		return
+	}
+
+	perpTransaction := s.SignalSink.Emit(signal.NewOpenSignal(s.coin, s.getPerpTradingKey(), futureType.SHORT, cost, "basis perpetual leg"))
+	if perpTransaction == nil {
+		zap.S().Errorf("Short leg of %s isn't opened, spot leg is unwound", s.name)
+		s.closePosition(spotTransaction, nil, constants.CLOSE_REASON_LEG_CLOSED)
+		return
+	}
+
+	telegramApi.SendTextToTelegramChat(fmt.Sprintf("Opened basis %s: spot ⬆️ perpetual ⬇️", s.coin.Symbol))
+}
+
+// closePosition unwinds both legs, missing leg is skipped
+func (s *FundingBasisStrategyTradingService) closePosition(spotTransaction *domains.Transaction, perpTransaction *domains.Transaction, closeReason constants.CloseReason) {
+	currentPrice, err := s.ExchangeDataService.GetCurrentPriceWithInterval(s.coin, s.klineInterval)
+	if err != nil {
+		zap.S().Errorf("Error during GetCurrentPrice at %v: %s", s.Clock.NowTime(), err.Error())
+		return
+	}
+
+	if spotTransaction != nil {
+		spotSignal := signal.NewCloseSignal(s.coin, spotTransaction, currentPrice, closeReason)
+		spotSignal.TradingType = constants.SPOT
+		s.SignalSink.Emit(spotSignal)
+	}
+	if perpTransaction != nil {
+		s.SignalSink.Emit(signal.NewCloseSignal(s.coin, perpTransaction, currentPrice, closeReason))
+	}
+
+	report, err := s.BuildReport()
+	if err != nil {
+		zap.S().Errorf("Error during BuildReport of %s: %s", s.name, err.Error())
+		return
+	}
+	telegramApi.SendTextToTelegramChat(fmt.Sprintf("Closed basis %s (%s): %s", s.coin.Symbol, closeReason, report.String()))
+}
+
+// accrueFunding saves funding settled since the last payment, notional is approximated by the open price of the short leg
+func (s *FundingBasisStrategyTradingService) accrueFunding(perpTransaction *domains.Transaction) {
+	accruedFrom := perpTransaction.CreatedAt
+	lastPaymentTime, err := s.FundingRateRepo.FindLastPaymentTimeByTransactionId(perpTransaction.Id)
+	if err != nil {
+		zap.S().Errorf("Error during FindLastPaymentTime of %d: %s", perpTransaction.Id, err.Error())
+		return
+	}
+	if lastPaymentTime != nil {
+		accruedFrom = *lastPaymentTime
+	}
+
+	rates, err := s.FundingRateRepo.FindAllByCoinIdAndFundingTimeInRange(s.coin.Id, accruedFrom, s.Clock.NowTime())
+	if err != nil {
+		zap.S().Errorf("Error during FindAll funding rates of %s: %s", s.coin.Symbol, err.Error())
+		return
+	}
+
+	notional := perpTransaction.Amount * perpTransaction.Price
+	for _, rate := range rates {
+		// positive rate is paid by longs to shorts
+		income := rate.Rate * notional
+		if perpTransaction.FuturesType == futureType.LONG {
+			income = -income
+		}
+
+		err := s.FundingRateRepo.SaveFundingPayment(&domains.FundingPayment{
+			TransactionId: perpTransaction.Id,
+			CoinId:        s.coin.Id,
+			FundingTime:   rate.FundingTime,
+			Rate:          rate.Rate,
+			Income:        util.GetCents(income),
+		})
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *FundingBasisStrategyTradingService) BuildReport() (FundingBasisReport, error) {
+	priceProfit, err := s.TransactionRepo.CalculateSumOfProfitByTradingKeyPrefix(s.tradingStrategy, s.name+":")
+	if err != nil {
+		return FundingBasisReport{}, err
+	}
+	fundingIncome, err := s.FundingRateRepo.CalculateSumOfFundingIncome(int(s.tradingStrategy), s.getPerpTradingKey())
+	if err != nil {
+		return FundingBasisReport{}, err
+	}
+
+	return FundingBasisReport{PriceProfitInCents: priceProfit, FundingIncomeInCents: fundingIncome}, nil
+}
+
+func (s *FundingBasisStrategyTradingService) getSpotTradingKey() string {
+	return s.name + ":spot"
+}
+
+func (s *FundingBasisStrategyTradingService) getPerpTradingKey() string {
+	return s.name + ":perp"
+}
@@ -0,0 +1,325 @@
+package trading
+
+import (
+	telegramApi "cryptoBot/pkg/api/telegram"
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/constants/futureType"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/exchange"
+	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/signal"
+	"database/sql"
+	"fmt"
+	"go.uber.org/zap"
+	"math"
+	"strconv"
+)
+
+type GridSpacing string
+
+const (
+	/* Levels have the same distance in price */
+	GRID_SPACING_ARITHMETIC GridSpacing = "arithmetic"
+	/* Levels have the same distance in percent */
+	GRID_SPACING_GEOMETRIC GridSpacing = "geometric"
+)
+
+// NewGridPrices returns levelsCount prices from lowerPrice to upperPrice inclusive
+func NewGridPrices(lowerPrice float64, upperPrice float64, levelsCount int, spacing GridSpacing) ([]float64, error) {
+	if lowerPrice <= 0 || upperPrice <= lowerPrice {
+		return nil, fmt.Errorf("expected 0 < lowerPrice < upperPrice, got %v and %v", lowerPrice, upperPrice)
+	}
+	if levelsCount < 2 {
+		return nil, fmt.Errorf("expected at least 2 levels, got %d", levelsCount)
+	}
+
+	prices := make([]float64, levelsCount)
+	switch spacing {
+	case GRID_SPACING_ARITHMETIC, "":
+		step := (upperPrice - lowerPrice) / float64(levelsCount-1)
+		for i := range prices {
+			prices[i] = lowerPrice + step*float64(i)
+		}
+	case GRID_SPACING_GEOMETRIC:
+		ratio := math.Pow(upperPrice/lowerPrice, 1/float64(levelsCount-1))
+		for i := range prices {
+			prices[i] = lowerPrice * math.Pow(ratio, float64(i))
+		}
+	default:
+		return nil, fmt.Errorf("unknown grid spacing [%s], expected arithmetic or geometric", spacing)
+	}
+	prices[levelsCount-1] = upperPrice
+
+	return prices, nil
+}
+
+// NewGridStrategyConfigs reads strategy.grid.strategies from config.yml
+func NewGridStrategyConfigs() []*StrategyConfig {
+	return loadStrategyConfigs(GRID_STRATEGY, "strategy.grid.strategies")
+}
+
+// Grid strategy buys on every level below the price and sells it on the next level above (long grid),
+// short grid sells on the upper level and buys back on the lower one. Levels are saved in grid_level,
+// so opened levels are managed after restart. Grid is stopped and all levels are closed when the price leaves the range.
+func NewGridStrategyTradingService(
+	transactionRepo repository.Transaction,
+	gridLevelRepo repository.GridLevel,
+	clock date.Clock,
+	exchangeDataService *exchange.DataService,
+	klinesFetcherService *exchange.KlinesFetcherService,
+	orderManagerService *orders.OrderManagerService,
+	signalSink signal.Sink,
+	coin *domains.Coin,
+	gridName string,
+	prices []float64,
+	quantity float64,
+	klineInterval int,
+) *GridStrategyTradingService {
+	return &GridStrategyTradingService{
+		TransactionRepo:      transactionRepo,
+		GridLevelRepo:        gridLevelRepo,
+		Clock:                clock,
+		ExchangeDataService:  exchangeDataService,
+		KlinesFetcherService: klinesFetcherService,
+		OrderManagerService:  orderManagerService,
+		SignalSink:           signalSink,
+		coin:                 coin,
+		gridName:             gridName,
+		prices:               prices,
+		quantity:             quantity,
+		klineInterval:        klineInterval,
+		tradingType:          constants.FUTURES,
+		futuresType:          futureType.LONG,
+		leverage:             1,
+		stopOutPercent:       0,
+		tradingStrategy:      constants.GRID,
+	}
+}
+
+type GridStrategyTradingService struct {
+	TransactionRepo      repository.Transaction
+	GridLevelRepo        repository.GridLevel
+	Clock                date.Clock
+	ExchangeDataService  *exchange.DataService
+	KlinesFetcherService *exchange.KlinesFetcherService
+	OrderManagerService  *orders.OrderManagerService
+	SignalSink           signal.Sink
+	coin                 *domains.Coin
+	/* Key of levels in grid_level and prefix of trading key of transactions */
+	gridName string
+	/* Ascending prices of levels */
+	prices []float64
+	/* Amount of coin bought or sold on every level */
+	quantity      float64
+	klineInterval int
+	tradingType   constants.TradingType
+	/* SHORT is allowed only for futures */
+	futuresType futureType.FuturesType
+	leverage    int
+	/* Grid is stopped when price is further from the range than stopOutPercent */
+	stopOutPercent  float64
+	tradingStrategy constants.TradingStrategy
+	levels          []*domains.GridLevel
+}
+
+func (s *GridStrategyTradingService) BotAction(coin *domains.Coin) {
+	return
+}
+
+func (s *GridStrategyTradingService) InitializeTrading(coin *domains.Coin) error {
+	return nil
+}
+
+func (s *GridStrategyTradingService) Initialize() error {
+	if s.tradingType == constants.FUTURES {
+		if err := s.SignalSink.SetFuturesLeverage(s.coin, s.leverage); err != nil {
+			return err
+		}
+	}
+
+	return s.loadLevels()
+}
+
+// loadLevels restores levels saved before restart, levels are recreated when the range in config is changed and nothing is opened
+func (s *GridStrategyTradingService) loadLevels() error {
+	levels, err := s.GridLevelRepo.FindAllByGridName(s.gridName)
+	if err != nil {
+		return err
+	}
+
+	if len(levels) > 0 {
+		if s.isSameGrid(levels) {
+			s.levels = levels
+			return nil
+		}
+		for _, level := range levels {
+			if level.State == constants.GRID_LEVEL_FILLED {
+				zap.S().Warnf("Grid %s is changed in config, but has filled levels. Saved grid is used", s.gridName)
+				s.levels = levels
+				return nil
+			}
+		}
+		if err := s.GridLevelRepo.DeleteAllByGridName(s.gridName); err != nil {
+			return err
+		}
+	}
+
+	s.levels = make([]*domains.GridLevel, 0, len(s.prices)-1)
+	for i := 0; i+1 < len(s.prices); i++ {
+		level := &domains.GridLevel{
+			GridName:   s.gridName,
+			CoinId:     s.coin.Id,
+			LevelIndex: i,
+			OpenPrice:  s.prices[i],
+			ClosePrice: s.prices[i+1],
+			Quantity:   s.quantity,
+			State:      constants.GRID_LEVEL_ARMED,
+			UpdatedAt:  s.Clock.NowTime(),
+		}
+		if s.futuresType == futureType.SHORT {
+			level.OpenPrice, level.ClosePrice = s.prices[i+1], s.prices[i]
+		}
+		if err := s.GridLevelRepo.SaveGridLevel(level); err != nil {
+			return err
+		}
+		s.levels = append(s.levels, level)
+	}
+
+	zap.S().Infof("Grid %s created with %d levels from %v to %v", s.gridName, len(s.levels), s.prices[0], s.prices[len(s.prices)-1])
+	return nil
+}
+
+func (s *GridStrategyTradingService) isSameGrid(levels []*domains.GridLevel) bool {
+	if len(levels) != len(s.prices)-1 {
+		return false
+	}
+	for i, level := range levels {
+		low, high := math.Min(level.OpenPrice, level.ClosePrice), math.Max(level.OpenPrice, level.ClosePrice)
+		if math.Abs(low-s.prices[i]) > 1e-9*s.prices[i] || math.Abs(high-s.prices[i+1]) > 1e-9*s.prices[i+1] || level.Quantity != s.quantity {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *GridStrategyTradingService) BeforeExecute() {
+	return
+}
+
+func (s *GridStrategyTradingService) Execute() {
+	s.KlinesFetcherService.FetchActualKlines(s.coin, s.klineInterval)
+
+	if len(s.levels) == 0 || s.levels[0].State == constants.GRID_LEVEL_STOPPED {
+		return
+	}
+
+	currentPrice, err := s.ExchangeDataService.GetCurrentPrice(s.coin)
+	if err != nil {
+		zap.S().Errorf("Error during GetCurrentPrice at %v: %s", s.Clock.NowTime(), err.Error())
+		return
+	}
+
+	if s.isOutOfRange(currentPrice) {
+		s.stopOut(currentPrice)
+		return
+	}
+
+	for _, level := range s.levels {
+		if level.State == constants.GRID_LEVEL_FILLED && s.shouldClose(level, currentPrice) {
+			s.closeLevel(level, constants.CLOSE_REASON_TAKE_PROFIT)
+		}
+	}
+	for _, level := range s.levels {
+		if level.State == constants.GRID_LEVEL_ARMED && s.shouldOpen(level, currentPrice) {
+			s.openLevel(level, currentPrice)
+		}
+	}
+}
+
+func (s *GridStrategyTradingService) isOutOfRange(price float64) bool {
+	if s.stopOutPercent <= 0 {
+		return false
+	}
+	lowerBound := s.prices[0] * (1 - s.stopOutPercent/100)
+	upperBound := s.prices[len(s.prices)-1] * (1 + s.stopOutPercent/100)
+	return price < lowerBound || price > upperBound
+}
+
+func (s *GridStrategyTradingService) shouldOpen(level *domains.GridLevel, price float64) bool {
+	if s.futuresType == futureType.SHORT {
+		return price >= level.OpenPrice
+	}
+	return price <= level.OpenPrice
+}
+
+func (s *GridStrategyTradingService) shouldClose(level *domains.GridLevel, price float64) bool {
+	if s.futuresType == futureType.SHORT {
+		return price <= level.ClosePrice
+	}
+	return price >= level.ClosePrice
+}
+
+func (s *GridStrategyTradingService) openLevel(level *domains.GridLevel, price float64) {
+	openSignal := signal.NewOpenSignal(s.coin, s.getTradingKey(level), s.futuresType, level.Quantity*price, "grid level "+strconv.Itoa(level.LevelIndex))
+	openSignal.TradingType = s.tradingType
+	transaction := s.SignalSink.Emit(openSignal)
+	if transaction == nil {
+		return
+	}
+
+	level.State = constants.GRID_LEVEL_FILLED
+	level.TransactionId = sql.NullInt64{Int64: transaction.Id, Valid: true}
+	s.saveLevel(level)
+}
+
+// closeLevel closes position of the level and re-arms it, position closed by exchange only re-arms the level
+func (s *GridStrategyTradingService) closeLevel(level *domains.GridLevel, closeReason constants.CloseReason) bool {
+	openedTransaction, err := s.SignalSink.FindOpenedTransaction(s.coin, s.getTradingKey(level))
+	if err != nil {
+		zap.S().Errorf("Error during FindOpenedTransaction of level %d of grid %s: %s", level.LevelIndex, s.gridName, err.Error())
+		return false
+	}
+
+	if openedTransaction != nil {
+		closeSignal := signal.NewCloseSignal(s.coin, openedTransaction, 0, closeReason)
+		closeSignal.TradingType = s.tradingType
+		if closedTransaction := s.SignalSink.Emit(closeSignal); closedTransaction == nil {
+			return false
+		}
+	}
+
+	level.State = constants.GRID_LEVEL_ARMED
+	level.TransactionId = sql.NullInt64{}
+	s.saveLevel(level)
+	return true
+}
+
+func (s *GridStrategyTradingService) stopOut(price float64) {
+	zap.S().Infof("Grid %s is stopped, price %v is out of range at %v", s.gridName, price, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+
+	for _, level := range s.levels {
+		if level.State == constants.GRID_LEVEL_FILLED && !s.closeLevel(level, constants.CLOSE_REASON_RANGE_EXIT) {
+			// level is closed on the next Execute
+			return
+		}
+	}
+	for _, level := range s.levels {
+		level.State = constants.GRID_LEVEL_STOPPED
+		s.saveLevel(level)
+	}
+
+	telegramApi.SendTextToTelegramChat(fmt.Sprintf("Grid %s is stopped, price %v is out of range", s.gridName, price))
+}
+
+func (s *GridStrategyTradingService) saveLevel(level *domains.GridLevel) {
+	level.UpdatedAt = s.Clock.NowTime()
+	if err := s.GridLevelRepo.SaveGridLevel(level); err != nil {
+		zap.S().Errorf("Error during SaveGridLevel %s: %s", level.String(), err.Error())
+	}
+}
+
+func (s *GridStrategyTradingService) getTradingKey(level *domains.GridLevel) string {
+	return s.gridName + "#" + strconv.Itoa(level.LevelIndex)
+}
@@ -7,11 +7,11 @@ import (
 	"cryptoBot/pkg/constants"
 	"cryptoBot/pkg/data/domains"
 	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
 	"cryptoBot/pkg/util"
 	"database/sql"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
-	"time"
 )
 
 type TradingService interface {
@@ -26,24 +26,20 @@ type TradingService interface {
 	InitializeTrading(coin *domains.Coin) error
 }
 
-var tradingServiceImpl *HolderStrategyTradingService
-
-func NewHolderStrategyTradingService(transactionRepo repository.Transaction, priceChangeRepo repository.PriceChange, exchangeApi api.ExchangeApi) *HolderStrategyTradingService {
-	if tradingServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	tradingServiceImpl = &HolderStrategyTradingService{
+func NewHolderStrategyTradingService(transactionRepo repository.Transaction, priceChangeRepo repository.PriceChange, exchangeApi api.ExchangeApi, clock date.Clock) *HolderStrategyTradingService {
+	return &HolderStrategyTradingService{
 		transactionRepo: transactionRepo,
 		priceChangeRepo: priceChangeRepo,
 		exchangeApi:     exchangeApi,
+		Clock:           clock,
 	}
-	return tradingServiceImpl
 }
 
 type HolderStrategyTradingService struct {
 	transactionRepo repository.Transaction
 	priceChangeRepo repository.PriceChange
 	exchangeApi     api.ExchangeApi
+	Clock           date.Clock
 }
 
 func (s *HolderStrategyTradingService) InitializeTrading(coin *domains.Coin) error {
@@ -127,7 +123,7 @@ func (s *HolderStrategyTradingService) buy(coin *domains.Coin, currentPrice floa
 		return
 	}
 	if configs.RuntimeConfig.HasLimitSpendDay() {
-		var dayAgo = time.Now().AddDate(0, 0, -1)
+		var dayAgo = s.Clock.NowTime().AddDate(0, 0, -1)
 		spentForTheLast24Hours, err := s.transactionRepo.CalculateSumOfSpentTransactionsAndCreatedAfter(dayAgo, constants.HOLDER)
 		if err != nil {
 			zap.S().Errorf("Error on CalculateSumOfSpentTransactionsAndCreatedAfter: %s", err)
@@ -0,0 +1,210 @@
+package trading
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/constants/futureType"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/exchange"
+	"cryptoBot/pkg/service/indicator"
+	"cryptoBot/pkg/service/indicator/techanLib"
+	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/signal"
+	"cryptoBot/pkg/util"
+	"github.com/sdcoffey/techan"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+type MeanReversionBands string
+
+const (
+	/* SMA(length) +- deviation * standard deviation */
+	MEAN_REVERSION_BANDS_BOLLINGER MeanReversionBands = "bollinger"
+	/* EMA(length) +- deviation * ATR(length) */
+	MEAN_REVERSION_BANDS_KELTNER MeanReversionBands = "keltner"
+)
+
+// NewMeanReversionStrategyConfigs reads strategy.meanReversion.strategies from config.yml
+func NewMeanReversionStrategyConfigs() []*StrategyConfig {
+	return loadStrategyConfigs(MEAN_REVERSION_STRATEGY, "strategy.meanReversion.strategies")
+}
+
+// Mean reversion strategy opens long when the close is below the lower band and RSI is oversold,
+// short when the close is above the upper band and RSI is overbought. Position is closed on the middle band.
+func NewMeanReversionStrategyTradingService(
+	transactionRepo repository.Transaction,
+	clock date.Clock,
+	exchangeDataService *exchange.DataService,
+	klinesFetcherService *exchange.KlinesFetcherService,
+	orderManagerService *orders.OrderManagerService,
+	signalSink signal.Sink,
+	techanConvertorService *techanLib.TechanConvertorService,
+	coin *domains.Coin,
+	bands MeanReversionBands,
+	klineInterval int,
+) *MeanReversionStrategyTradingService {
+	return &MeanReversionStrategyTradingService{
+		TransactionRepo:        transactionRepo,
+		Clock:                  clock,
+		ExchangeDataService:    exchangeDataService,
+		KlinesFetcherService:   klinesFetcherService,
+		OrderManagerService:    orderManagerService,
+		SignalSink:             signalSink,
+		TechanConvertorService: techanConvertorService,
+		coin:                   coin,
+		bands:                  bands,
+		klineInterval:          klineInterval,
+		klineIntervalS:         strconv.Itoa(klineInterval),
+		length:                 20,
+		deviation:              2,
+		rsiLength:              14,
+		rsiOversold:            30,
+		rsiOverbought:          70,
+		allowLong:              true,
+		allowShort:             true,
+		leverage:               1,
+		stopLossPercent:        0, //disabled
+		costOfOrderInCents:     100 * 100,
+		tradingStrategy:        constants.MEAN_REVERSION,
+	}
+}
+
+type MeanReversionStrategyTradingService struct {
+	TransactionRepo        repository.Transaction
+	Clock                  date.Clock
+	ExchangeDataService    *exchange.DataService
+	KlinesFetcherService   *exchange.KlinesFetcherService
+	OrderManagerService    *orders.OrderManagerService
+	SignalSink             signal.Sink
+	TechanConvertorService *techanLib.TechanConvertorService
+	/* Positions are opened in sessions outside of blackouts only, closing is always allowed */
+	SessionsService *indicator.SessionsService
+	coin            *domains.Coin
+	bands           MeanReversionBands
+	klineInterval   int
+	klineIntervalS  string
+	/* Klines of the middle band and the deviation */
+	length int
+	/* Multiplier of standard deviation for bollinger or of ATR for keltner */
+	deviation          float64
+	rsiLength          int
+	rsiOversold        float64
+	rsiOverbought      float64
+	allowLong          bool
+	allowShort         bool
+	leverage           int
+	stopLossPercent    float64
+	costOfOrderInCents int
+	tradingStrategy    constants.TradingStrategy
+}
+
+func (s *MeanReversionStrategyTradingService) BotAction(coin *domains.Coin) {
+	return
+}
+
+func (s *MeanReversionStrategyTradingService) InitializeTrading(coin *domains.Coin) error {
+	return nil
+}
+
+func (s *MeanReversionStrategyTradingService) Initialize() error {
+	err := s.SignalSink.SetFuturesLeverage(s.coin, s.leverage)
+	if err != nil {
+		return err
+	}
+
+	s.KlinesFetcherService.FetchActualKlines(s.coin, s.klineInterval)
+
+	return nil
+}
+
+func (s *MeanReversionStrategyTradingService) BeforeExecute() {
+	return
+}
+
+func (s *MeanReversionStrategyTradingService) Execute() {
+	s.KlinesFetcherService.FetchActualKlines(s.coin, s.klineInterval)
+
+	openedOrder, _ := s.SignalSink.FindOpenedTransaction(s.coin, "")
+	if openedOrder != nil {
+		if closedOrder := s.SignalSink.CloseByFixedStopLossOrTakeProfit(s.coin, openedOrder, s.klineIntervalS); closedOrder != nil {
+			return
+		}
+	}
+
+	lookback := s.length
+	if s.rsiLength > lookback {
+		lookback = s.rsiLength
+	}
+	// EMA and RSI need a few more klines to converge
+	series := s.TechanConvertorService.BuildTimeSeriesByKlines(s.coin, s.klineIntervalS, int64(lookback*3+1))
+	if series == nil || series.LastIndex() < lookback {
+		zap.S().Errorf("Not enough klines for %s at %s", s.coin.Symbol, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		return
+	}
+
+	index := series.LastIndex()
+	closePrice := series.LastCandle().ClosePrice.Float()
+	middle, upper, lower := s.calculateBands(series, index)
+	rsi := techan.NewRelativeStrengthIndexIndicator(techan.NewClosePriceIndicator(series), s.rsiLength).Calculate(index).Float()
+	indicators := map[string]float64{"close": closePrice, "middle": middle, "upper": upper, "lower": lower, "rsi": rsi}
+
+	if openedOrder != nil {
+		if openedOrder.FuturesType == futureType.LONG && closePrice >= middle ||
+			openedOrder.FuturesType == futureType.SHORT && closePrice <= middle {
+			zap.S().Infof("EXIT on middle band %.4f at %v", middle, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+			closeSignal := signal.NewCloseSignal(s.coin, openedOrder, 0, constants.CLOSE_REASON_SIGNAL)
+			closeSignal.Indicators = indicators
+			s.SignalSink.Emit(closeSignal)
+		}
+		return
+	}
+
+	if s.allowLong && closePrice < lower && rsi < s.rsiOversold {
+		zap.S().Infof("LONG SIGNAL close %.4f < lower %.4f, rsi %.2f at %v", closePrice, lower, rsi, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		s.openOrder(futureType.LONG, "close below lower band", indicators)
+	} else if s.allowShort && closePrice > upper && rsi > s.rsiOverbought {
+		zap.S().Infof("SHORT SIGNAL close %.4f > upper %.4f, rsi %.2f at %v", closePrice, upper, rsi, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		s.openOrder(futureType.SHORT, "close above upper band", indicators)
+	}
+}
+
+func (s *MeanReversionStrategyTradingService) calculateBands(series *techan.TimeSeries, index int) (middle float64, upper float64, lower float64) {
+	closePrices := techan.NewClosePriceIndicator(series)
+
+	var width float64
+	if s.bands == MEAN_REVERSION_BANDS_KELTNER {
+		middle = techan.NewEMAIndicator(closePrices, s.length).Calculate(index).Float()
+		width = techan.NewAverageTrueRangeIndicator(series, s.length).Calculate(index).Float() * s.deviation
+	} else {
+		middle = techan.NewSimpleMovingAverage(closePrices, s.length).Calculate(index).Float()
+		width = techan.NewWindowedStandardDeviationIndicator(closePrices, s.length).Calculate(index).Float() * s.deviation
+	}
+
+	return middle, middle + width, middle - width
+}
+
+func (s *MeanReversionStrategyTradingService) openOrder(futuresType futureType.FuturesType, reason string, indicators map[string]float64) {
+	if s.SessionsService != nil && !s.SessionsService.IsTradingAllowedNow() {
+		zap.S().Infof("Trading of %s isn't allowed by sessions at %v", s.coin.Symbol, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		return
+	}
+
+	currentPrice, err := s.ExchangeDataService.GetCurrentPrice(s.coin)
+	if err != nil {
+		zap.S().Errorf("Error during GetCurrentPrice at %v: %s", s.Clock.NowTime(), err.Error())
+		return
+	}
+
+	stopLoss := float64(0)
+	if s.stopLossPercent > 0 {
+		stopLoss = util.CalculatePriceForStopLoss(currentPrice, s.stopLossPercent, futuresType)
+	}
+
+	openSignal := signal.NewOpenSignal(s.coin, "", futuresType, util.GetDollarsByCents(int64(s.costOfOrderInCents)), string(s.bands)+" "+reason)
+	openSignal.Price = currentPrice
+	openSignal.StopLossPrice = stopLoss
+	openSignal.Indicators = indicators
+	s.SignalSink.Emit(openSignal)
+}
@@ -17,15 +17,10 @@ import (
 	"go.uber.org/zap"
 )
 
-var movingAverageResistanceStrategyTradingServiceImpl *MovingAverageResistanceStrategyTradingService
-
 func NewMovingAverageResistanceStrategyTradingService(transactionRepo repository.Transaction, priceChangeRepo repository.PriceChange,
 	exchangeApi api.ExchangeApi, clock date.Clock, exchangeDataService *exchange.DataService, klineRepo repository.Kline,
 	priceChangeTrackingService *orders.PriceChangeTrackingService, movingAverageService *indicator.MovingAverageService) *MovingAverageResistanceStrategyTradingService {
-	if movingAverageResistanceStrategyTradingServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	movingAverageResistanceStrategyTradingServiceImpl = &MovingAverageResistanceStrategyTradingService{
+	return &MovingAverageResistanceStrategyTradingService{
 		klineRepo:                  klineRepo,
 		transactionRepo:            transactionRepo,
 		priceChangeRepo:            priceChangeRepo,
@@ -35,7 +30,6 @@ func NewMovingAverageResistanceStrategyTradingService(transactionRepo repository
 		PriceChangeTrackingService: priceChangeTrackingService,
 		MovingAverageService:       movingAverageService,
 	}
-	return movingAverageResistanceStrategyTradingServiceImpl
 }
 
 type MovingAverageResistanceStrategyTradingService struct {
@@ -19,16 +19,11 @@ import (
 	"time"
 )
 
-var movingAverageStrategyTradingServiceImpl *MovingAverageStrategyTradingService
-
 func NewMAStrategyTradingService(transactionRepo repository.Transaction, priceChangeRepo repository.PriceChange,
 	exchangeApi api.ExchangeApi, clock date.Clock, exchangeDataService *exchange.DataService, klineRepo repository.Kline,
 	priceChangeTrackingService *orders.PriceChangeTrackingService, movingAverageService *indicator.MovingAverageService,
 	standardDeviationService *indicator.StandardDeviationService, klinesFetcherService *exchange.KlinesFetcherService) *MovingAverageStrategyTradingService {
-	if movingAverageStrategyTradingServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	movingAverageStrategyTradingServiceImpl = &MovingAverageStrategyTradingService{
+	return &MovingAverageStrategyTradingService{
 		klineRepo:                  klineRepo,
 		transactionRepo:            transactionRepo,
 		priceChangeRepo:            priceChangeRepo,
@@ -40,7 +35,6 @@ func NewMAStrategyTradingService(transactionRepo repository.Transaction, priceCh
 		StandardDeviationService:   standardDeviationService,
 		KlinesFetcherService:       klinesFetcherService,
 	}
-	return movingAverageStrategyTradingServiceImpl
 }
 
 type MovingAverageStrategyTradingService struct {
@@ -0,0 +1,187 @@
+package trading
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/repository"
+	"fmt"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"os"
+)
+
+var pairArbitrageIntervals = map[int]bool{1: true, 3: true, 5: true, 15: true, 30: true, 60: true, 120: true, 240: true, 360: true, 720: true}
+
+// PairArbitrageConfig is one pair from strategy.pairArbitrage.pairs of config.yml
+type PairArbitrageConfig struct {
+	Coin1 string
+	Coin2 string
+
+	/* Exchange account, credentials are read from BYBIT_<account>_API_KEY and BYBIT_<account>_API_SECRET */
+	Account string
+
+	/* Kline interval in minutes */
+	Interval int
+	/* Klines used for zScore of the spread */
+	Length int
+
+	EntryZScore float64
+	ExitZScore  float64
+
+	Leverage            int
+	StartCapitalInCents int
+
+	/* Disabled pair doesn't open new positions, but opened positions are still closed by the strategy */
+	Enabled bool
+
+	TradingStrategy constants.TradingStrategy
+
+	/* Other parameters of the strategy, e.g. hedgeRatio or maxHoldingKlines */
+	Params map[string]interface{}
+}
+
+// LoadPairArbitrageConfigs reads and validates strategy.pairArbitrage.pairs, fails on the first invalid pair
+func LoadPairArbitrageConfigs() ([]*PairArbitrageConfig, error) {
+	items := cast.ToSlice(viper.Get("strategy.pairArbitrage.pairs"))
+	configs := make([]*PairArbitrageConfig, 0, len(items))
+	keys := make(map[string]bool, len(items))
+
+	for i, item := range items {
+		config := newPairArbitrageConfig(lowerKeys(cast.ToStringMap(item)))
+		if err := config.Validate(); err != nil {
+			return nil, fmt.Errorf("strategy.pairArbitrage.pairs[%d]: %s", i, err.Error())
+		}
+
+		key := config.ToStrategyConfig().Key()
+		if keys[key] {
+			return nil, fmt.Errorf("strategy.pairArbitrage.pairs[%d]: pair %s is duplicated", i, key)
+		}
+		keys[key] = true
+
+		configs = append(configs, config)
+	}
+
+	return configs, nil
+}
+
+func newPairArbitrageConfig(values map[string]interface{}) *PairArbitrageConfig {
+	config := &PairArbitrageConfig{
+		Coin1:               cast.ToString(values["coin1"]),
+		Coin2:               cast.ToString(values["coin2"]),
+		Account:             cast.ToString(values["account"]),
+		Interval:            60,
+		Length:              20,
+		EntryZScore:         2,
+		ExitZScore:          0.2,
+		Leverage:            1,
+		StartCapitalInCents: 10000,
+		Enabled:             true,
+		TradingStrategy:     constants.TradingStrategy(cast.ToInt8(values["tradingstrategy"])),
+		Params:              make(map[string]interface{}),
+	}
+
+	for key, value := range values {
+		switch key {
+		case "coin1", "coin2", "account", "tradingstrategy":
+		case "interval":
+			config.Interval = cast.ToInt(value)
+		case "length":
+			config.Length = cast.ToInt(value)
+		case "entryzscore":
+			config.EntryZScore = cast.ToFloat64(value)
+		case "exitzscore":
+			config.ExitZScore = cast.ToFloat64(value)
+		case "leverage":
+			config.Leverage = cast.ToInt(value)
+		case "startcapitalincents":
+			config.StartCapitalInCents = cast.ToInt(value)
+		case "enabled":
+			config.Enabled = cast.ToBool(value)
+		default:
+			config.Params[key] = value
+		}
+	}
+
+	return config
+}
+
+func (c *PairArbitrageConfig) Validate() error {
+	if c.Coin1 == "" || c.Coin2 == "" {
+		return fmt.Errorf("coin1 and coin2 are required")
+	}
+	if c.Coin1 == c.Coin2 {
+		return fmt.Errorf("coin1 and coin2 are the same %s", c.Coin1)
+	}
+	if c.Account == "" {
+		return fmt.Errorf("account of %s-%s is required", c.Coin1, c.Coin2)
+	}
+	if !pairArbitrageIntervals[c.Interval] {
+		return fmt.Errorf("interval %d of %s-%s isn't supported", c.Interval, c.Coin1, c.Coin2)
+	}
+	if c.Length < 2 {
+		return fmt.Errorf("length %d of %s-%s is too small", c.Length, c.Coin1, c.Coin2)
+	}
+	if c.ExitZScore < 0 || c.EntryZScore <= c.ExitZScore {
+		return fmt.Errorf("expected entryZScore > exitZScore >= 0 for %s-%s, got %.2f and %.2f", c.Coin1, c.Coin2, c.EntryZScore, c.ExitZScore)
+	}
+	if c.Leverage < 1 {
+		return fmt.Errorf("leverage %d of %s-%s must be positive", c.Leverage, c.Coin1, c.Coin2)
+	}
+	if c.StartCapitalInCents <= 0 {
+		return fmt.Errorf("startCapitalInCents %d of %s-%s must be positive", c.StartCapitalInCents, c.Coin1, c.Coin2)
+	}
+	return nil
+}
+
+// ValidateCoins checks both coins are known, so the pair isn't replaced by the instance failing on start
+func (c *PairArbitrageConfig) ValidateCoins(coinRepo repository.Coin) error {
+	for _, symbol := range []string{c.Coin1, c.Coin2} {
+		coin, err := coinRepo.FindBySymbol(symbol)
+		if err != nil {
+			return fmt.Errorf("Error during FindBySymbol %s of %s-%s: %s", symbol, c.Coin1, c.Coin2, err.Error())
+		}
+		if coin == nil {
+			return fmt.Errorf("coin %s of %s-%s not found", symbol, c.Coin1, c.Coin2)
+		}
+	}
+	return nil
+}
+
+// ValidateAccount checks credentials of the account are present in environment, only traders need it
+func (c *PairArbitrageConfig) ValidateAccount() error {
+	account := c.GetAccount()
+	for _, env := range []string{account.ApiKeyEnv, account.ApiSecretEnv} {
+		if os.Getenv(env) == "" {
+			return fmt.Errorf("env %s of %s-%s is empty", env, c.Coin1, c.Coin2)
+		}
+	}
+	return nil
+}
+
+func (c *PairArbitrageConfig) GetAccount() StrategyAccount {
+	return StrategyAccount{
+		ApiKeyEnv:    "BYBIT_" + c.Account + "_API_KEY",
+		ApiSecretEnv: "BYBIT_" + c.Account + "_API_SECRET",
+	}
+}
+
+func (c *PairArbitrageConfig) ToStrategyConfig() *StrategyConfig {
+	params := make(map[string]interface{}, len(c.Params)+6)
+	for key, value := range c.Params {
+		params[key] = value
+	}
+	params["strategyLength"] = c.Length
+	params["entryZScore"] = c.EntryZScore
+	params["zScoreCloseToZero"] = c.ExitZScore
+	params["leverage"] = c.Leverage
+	params["startCapitalInCents"] = c.StartCapitalInCents
+	params["enabled"] = c.Enabled
+
+	return &StrategyConfig{
+		Strategy:        PAIR_ARBITRAGE_STRATEGY,
+		TradingStrategy: c.TradingStrategy,
+		Coins:           []string{c.Coin1, c.Coin2},
+		Account:         c.GetAccount(),
+		Interval:        c.Interval,
+		Params:          params,
+	}
+}
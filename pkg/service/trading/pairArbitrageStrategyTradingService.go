@@ -8,26 +8,27 @@ import (
 	"cryptoBot/pkg/repository"
 	"cryptoBot/pkg/service/date"
 	"cryptoBot/pkg/service/exchange"
+	"cryptoBot/pkg/service/indicator"
 	"cryptoBot/pkg/service/indicator/techanLib"
 	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/signal"
 	"cryptoBot/pkg/util"
 	"fmt"
 	"github.com/sdcoffey/big"
 	"github.com/sdcoffey/techan"
-	"github.com/spf13/viper"
 	"go.uber.org/zap"
-	"os"
+	"reflect"
 	"time"
 )
 
 // https://youtu.be/9jn3DnLNyU0
 // Z-Score script: https://www.tradingview.com/pine/?id=PUB%3BC0yY0a1BOlCTSIHGTDWwBkWcwTdjpeEd
-var pairArbitrageStrategyTradingService *PairArbitrageStrategyTradingService
-var pairArbitrageStrategyTradingServiceContainer *PairArbitrageStrategyTradingServiceContainer
-
-func NewPairArbitrageStrategyTradingServiceContainer(tradingService *PairArbitrageStrategyTradingService) *PairArbitrageStrategyTradingServiceContainer {
+func NewPairArbitrageStrategyTradingServiceContainer(registry *StrategyRegistry, executor *StrategyExecutor, syntheticKlineRepo repository.SyntheticKline) *PairArbitrageStrategyTradingServiceContainer {
 	return &PairArbitrageStrategyTradingServiceContainer{
-		TradingService: tradingService,
+		Registry:           registry,
+		Executor:           executor,
+		SyntheticKlineRepo: syntheticKlineRepo,
+		Clock:              registry.deps.Clock,
 	}
 }
 
@@ -39,11 +40,14 @@ func NewPairArbitrageStrategyTradingService(
 	syntheticKlineRepo repository.SyntheticKline,
 	klinesFetcherService *exchange.KlinesFetcherService,
 	orderManagerService *orders.OrderManagerService,
+	signalSink signal.Sink,
 	techanConvertorService *techanLib.TechanConvertorService,
+	hedgeRatioService *indicator.HedgeRatioService,
+	cointegrationService *indicator.CointegrationService,
 	coin1 *domains.Coin,
 	coin2 *domains.Coin,
 ) *PairArbitrageStrategyTradingService {
-	pairArbitrageStrategyTradingService = &PairArbitrageStrategyTradingService{
+	return &PairArbitrageStrategyTradingService{
 		CoinRepo:               coinRepo,
 		SyntheticKlineRepo:     syntheticKlineRepo,
 		TransactionRepo:        transactionRepo,
@@ -51,9 +55,13 @@ func NewPairArbitrageStrategyTradingService(
 		ExchangeDataService:    exchangeDataService,
 		KlinesFetcherService:   klinesFetcherService,
 		OrderManagerService:    orderManagerService,
+		SignalSink:             signalSink,
 		TechanConvertorService: techanConvertorService,
+		HedgeRatioService:      hedgeRatioService,
+		CointegrationService:   cointegrationService,
 		coin1:                  coin1,
 		coin2:                  coin2,
+		tradingKey:             coin1.Symbol + "-" + coin2.Symbol,
 		startCapitalInCents:    10000,
 		strategyLength:         20,
 		klineInterval:          60,
@@ -62,11 +70,21 @@ func NewPairArbitrageStrategyTradingService(
 		stopLossPercent:        0, //disabled
 		closeOnProfit:          1,
 		maxOrderLoss:           -3,
+		entryZScore:            2,
 		zScoreCloseToZero:      0.2,
 		zScoreMinProfit:        0.3,
 		tradingStrategy:        constants.PAIR_ARBITRAGE,
+		hedgeRatioMethod:       indicator.HEDGE_RATIO_PRICE_RATIO,
+		hedgeRatioLength:       100,
+		hedgeRatio:             1,
+		zScoreStopLoss:         0, //disabled
+		halfLifeMultiplier:     0, //disabled
+		maxHoldingKlines:       0, //disabled
+		regimeLength:           0, //disabled
+		regimeMinCorrelation:   0.6,
+		regimeSignificance:     0.1,
+		enabled:                true,
 	}
-	return pairArbitrageStrategyTradingService
 }
 
 type PairArbitrageStrategyTradingService struct {
@@ -77,7 +95,10 @@ type PairArbitrageStrategyTradingService struct {
 	ExchangeDataService    *exchange.DataService
 	KlinesFetcherService   *exchange.KlinesFetcherService
 	OrderManagerService    *orders.OrderManagerService
+	SignalSink             signal.Sink
 	TechanConvertorService *techanLib.TechanConvertorService
+	HedgeRatioService      *indicator.HedgeRatioService
+	CointegrationService   *indicator.CointegrationService
 	coin1                  *domains.Coin
 	coin2                  *domains.Coin
 	startCapitalInCents    int
@@ -88,15 +109,43 @@ type PairArbitrageStrategyTradingService struct {
 	stopLossPercent        float64
 	closeOnProfit          float64
 	maxOrderLoss           float64
+	entryZScore            float64
 	zScoreCloseToZero      float64
 	zScoreMinProfit        float64
 	tradingStrategy        constants.TradingStrategy
+	hedgeRatioMethod       indicator.HedgeRatioMethod
+	/* Key of transactions and signals of the instance, the pair with the account of the instance */
+	tradingKey string
+	/* Klines used for estimation of beta by ols and kalman methods */
+	hedgeRatioLength int
+	/* Beta calculated on the last Execute, used for sizing of legs */
+	hedgeRatio float64
+	/* Positions are closed when spread runs away further than this zScore, 0 - disabled */
+	zScoreStopLoss float64
+	/* Positions are closed after halfLifeMultiplier * half-life of the spread, but not later than maxHoldingKlines, 0 - disabled */
+	halfLifeMultiplier float64
+	maxHoldingKlines   int
+	/* Klines used for correlation, Engle-Granger test and half-life of the pair, 0 - disabled */
+	regimeLength         int
+	regimeMinCorrelation float64
+	regimeSignificance   float64
+	/* Half-life of the spread in klines calculated on the last Execute */
+	halfLife float64
+	/* Half-life at the moment of opening of the position */
+	entryHalfLife float64
+	/* New positions aren't opened while correlation or cointegration of the pair is degraded */
+	suspended bool
+	/* Disabled in config.yml, new positions aren't opened */
+	enabled bool
 }
 
 type PairArbitrageStrategyTradingServiceContainer struct {
-	TradingService         *PairArbitrageStrategyTradingService
-	IsBeforeExecuteRunning bool
-	IsExecuteRunning       bool
+	Registry           *StrategyRegistry
+	Executor           *StrategyExecutor
+	SyntheticKlineRepo repository.SyntheticKline
+	Clock              date.Clock
+	/* Moment of the current tick, Execute runs the same pairs as BeforeExecute even if fetching took longer than a minute */
+	tick time.Time
 }
 
 func (s *PairArbitrageStrategyTradingServiceContainer) BotAction(coin *domains.Coin) {
@@ -107,74 +156,57 @@ func (s *PairArbitrageStrategyTradingServiceContainer) InitializeTrading(coin *d
 }
 
 func (s *PairArbitrageStrategyTradingServiceContainer) Initialize() error {
-	coins := viper.GetStringSlice("strategy.pairArbitrage.coins")
-
-	for i := 0; i < len(coins); i += 4 {
-		coin1, _ := s.TradingService.CoinRepo.FindBySymbol(coins[i])
-		coin2, _ := s.TradingService.CoinRepo.FindBySymbol(coins[i+1])
+	return s.Registry.Initialize()
+}
 
-		s.TradingService.coin1 = coin1
-		s.TradingService.coin2 = coin2
-		s.TradingService.ExchangeDataService.ExchangeApi.SetApiKey(os.Getenv(coins[i+2]))
-		s.TradingService.ExchangeDataService.ExchangeApi.SetSecretKey(os.Getenv(coins[i+3]))
+// Reload applies changed strategy.pairArbitrage.pairs: new pairs are created, changed pairs are recreated with new parameters,
+// removed pairs stop trading. Unchanged pairs keep running instances with their in-memory state (suspension, entry half-life). Opened positions are found by trading key, so recreated instance continues to manage them.
+// New instances are built and initialized before any change, invalid pairs leave all running pairs as they are.
+func (s *PairArbitrageStrategyTradingServiceContainer) Reload(pairs []*PairArbitrageConfig) error {
+	configs := make(map[string]*StrategyConfig, len(pairs))
+	changed := make([]*StrategyConfig, 0, len(pairs))
+	for _, pair := range pairs {
+		if err := pair.ValidateCoins(s.Registry.deps.Repos.Coin); err != nil {
+			return err
+		}
+		config := pair.ToStrategyConfig()
+		configs[config.Key()] = config
 
-		s.TradingService.Initialize()
+		instance := s.Registry.Find(config.Key())
+		if instance == nil || !reflect.DeepEqual(instance.Config, config) {
+			changed = append(changed, config)
+		}
 	}
 
-	return nil
-}
-
-func (s *PairArbitrageStrategyTradingServiceContainer) BeforeExecute() {
-	if time.Now().Minute()%60 != 0 {
-		return
+	if err := s.Registry.Replace(changed); err != nil {
+		return err
 	}
-	if s.IsBeforeExecuteRunning {
-		return
+	for _, config := range changed {
+		zap.S().Infof("Pair %s is created", config.Key())
 	}
-	s.IsBeforeExecuteRunning = true
 
-	coins := viper.GetStringSlice("strategy.pairArbitrage.coins")
-
-	for i := 0; i < len(coins); i += 4 {
-		coin1, _ := s.TradingService.CoinRepo.FindBySymbol(coins[i])
-		coin2, _ := s.TradingService.CoinRepo.FindBySymbol(coins[i+1])
-
-		s.TradingService.coin1 = coin1
-		s.TradingService.coin2 = coin2
-		s.TradingService.ExchangeDataService.ExchangeApi.SetApiKey(os.Getenv(coins[i+2]))
-		s.TradingService.ExchangeDataService.ExchangeApi.SetSecretKey(os.Getenv(coins[i+3]))
-
-		s.TradingService.BeforeExecute()
+	for _, instance := range s.Registry.Instances() {
+		if _, ok := configs[instance.Config.Key()]; !ok {
+			zap.S().Infof("Pair %s is removed", instance.Config.Key())
+			s.Registry.Remove(instance.Config)
+		}
 	}
 
-	s.TradingService.SyntheticKlineRepo.RefreshView()
-
-	s.IsBeforeExecuteRunning = false
+	return nil
 }
 
-func (s *PairArbitrageStrategyTradingServiceContainer) Execute() {
-	if time.Now().Minute()%60 != 0 {
-		return
-	}
-	if s.IsExecuteRunning {
+// BeforeExecute fetches klines of pairs whose interval starts now, every pair has its own interval
+func (s *PairArbitrageStrategyTradingServiceContainer) BeforeExecute() {
+	s.tick = s.Clock.NowTime()
+	if results := s.Executor.BeforeExecuteAt(s.tick); len(results) == 0 {
 		return
 	}
-	s.IsExecuteRunning = true
-	coins := viper.GetStringSlice("strategy.pairArbitrage.coins")
-
-	for i := 0; i < len(coins); i += 4 {
-		coin1, _ := s.TradingService.CoinRepo.FindBySymbol(coins[i])
-		coin2, _ := s.TradingService.CoinRepo.FindBySymbol(coins[i+1])
-		s.TradingService.ExchangeDataService.ExchangeApi.SetApiKey(os.Getenv(coins[i+2]))
-		s.TradingService.ExchangeDataService.ExchangeApi.SetSecretKey(os.Getenv(coins[i+3]))
-
-		s.TradingService.coin1 = coin1
-		s.TradingService.coin2 = coin2
 
-		s.TradingService.Execute()
-	}
+	s.SyntheticKlineRepo.RefreshView()
+}
 
-	s.IsExecuteRunning = false
+func (s *PairArbitrageStrategyTradingServiceContainer) Execute() {
+	s.Executor.ExecuteAt(s.tick)
 }
 
 func (s *PairArbitrageStrategyTradingService) BotAction(coin *domains.Coin) {
@@ -185,12 +217,12 @@ func (s *PairArbitrageStrategyTradingService) InitializeTrading(coin *domains.Co
 }
 
 func (s *PairArbitrageStrategyTradingService) Initialize() error {
-	err := s.OrderManagerService.SetIsolatedMargin(s.coin1, s.leverage)
+	err := s.SignalSink.SetIsolatedMargin(s.coin1, s.leverage)
 	if err != nil {
 		return err
 	}
 
-	err = s.OrderManagerService.SetIsolatedMargin(s.coin2, s.leverage)
+	err = s.SignalSink.SetIsolatedMargin(s.coin2, s.leverage)
 	if err != nil {
 		return err
 	}
@@ -207,32 +239,60 @@ func (s *PairArbitrageStrategyTradingService) Execute() {
 	zap.S().Debugf("Execute %s-%s", s.coin1.Symbol, s.coin2.Symbol)
 
 	klinesFetchLimit := s.strategyLength + 1
+	if s.hedgeRatioMethod != indicator.HEDGE_RATIO_PRICE_RATIO && s.hedgeRatioLength > klinesFetchLimit {
+		klinesFetchLimit = s.hedgeRatioLength
+	}
+	minKlines := klinesFetchLimit
+	if s.regimeLength > klinesFetchLimit {
+		klinesFetchLimit = s.regimeLength
+	}
 	klines, err := s.SyntheticKlineRepo.FindAllByCoinIdAndIntervalAndCloseTimeLessOrderByOpenTimeWithLimit(s.coin1.Id, s.coin2.Id, s.klineIntervalS, s.Clock.NowTime(), klinesFetchLimit)
 	if err != nil {
 		zap.S().Errorf("Error on fetch synthetic klines: %s. ", err.Error())
 		return
 	}
-	if len(klines) < klinesFetchLimit {
+	if len(klines) < minKlines {
 		zap.S().Errorf("Empty klines: %s. ", s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
 		return
 	}
 
-	zScore := s.calculateZScore(klines)
+	zScore, err := s.calculateZScore(klines)
+	if err != nil {
+		zap.S().Errorf("Error on calculate zScore %s-%s: %s", s.coin1.Symbol, s.coin2.Symbol, err.Error())
+		return
+	}
+
+	s.checkRegime(klines)
 
 	if s.hasOpenedOrders() {
 		s.CloseOpenedOrderByStopLossIfNeeded(zScore)
 		return
 	}
 
-	if zScore.GT(big.NewDecimal(2)) {
+	if !s.enabled {
+		return
+	}
+	if s.suspended {
+		zap.S().Debugf("Pair %s-%s is suspended at %v", s.coin1.Symbol, s.coin2.Symbol, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		return
+	}
+
+	if s.hedgeRatio <= 0 {
+		zap.S().Infof("Negative hedge ratio %.4f for %s-%s, pair isn't traded at %v", s.hedgeRatio, s.coin1.Symbol, s.coin2.Symbol, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		return
+	}
+
+	if zScore.GT(big.NewDecimal(s.entryZScore)) {
 		zap.S().Infof("Upper Level zScore(%.2f) crossed at %v", zScore.Float(), s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		s.entryHalfLife = s.halfLife
 		s.openOrder(s.coin1, futureType.SHORT)
 		s.openOrder(s.coin2, futureType.LONG)
 		telegramApi.SendTextToTelegramChat("Opened " + s.coin1.Symbol + "⬇️" + s.coin2.Symbol + "⬆ ️")
 		//s.debugPrices(s.coin1, s.klineInterval)
 		//s.debugPrices(s.coin2, s.klineInterval)
-	} else if zScore.LT(big.NewDecimal(-2)) {
+	} else if zScore.LT(big.NewDecimal(-s.entryZScore)) {
 		zap.S().Infof("Lower Level zScore(%.2f) crossed at %v", zScore.Float(), s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		s.entryHalfLife = s.halfLife
 		s.openOrder(s.coin1, futureType.LONG)
 		s.openOrder(s.coin2, futureType.SHORT)
 		telegramApi.SendTextToTelegramChat("Opened " + s.coin1.Symbol + "⬆ ️" + s.coin2.Symbol + "⬇️")
@@ -242,7 +302,7 @@ func (s *PairArbitrageStrategyTradingService) Execute() {
 }
 
 func (s *PairArbitrageStrategyTradingService) debugPrices(coin *domains.Coin, intervalInMinutes int) {
-	openedOrder1, _ := s.TransactionRepo.FindOpenedTransactionByCoinAndTradingKey(s.tradingStrategy, coin.Id, s.getTradingKey())
+	openedOrder1, _ := s.SignalSink.FindOpenedTransaction(coin, s.getTradingKey())
 	zap.S().Debugf("Opened order not found for %s", coin.Symbol)
 
 	priceByLastKline := openedOrder1.Price
@@ -252,10 +312,90 @@ func (s *PairArbitrageStrategyTradingService) debugPrices(coin *domains.Coin, in
 	zap.S().Debugf("%v last kline price and current price priceByLastKline[%.4f] priceForFutures[%.4f] priceSpot[%.4f] priceSpot2[%.4f]", coin.Symbol, priceByLastKline, priceForFutures, priceSpot, priceSpot2)
 }
 
-func (s *PairArbitrageStrategyTradingService) calculateZScore(klines []domains.IKline) big.Decimal {
-	series := s.TechanConvertorService.ConvertKlinesToSeries(klines, s.klineInterval)
-	smaIndicator := techan.NewSimpleMovingAverage(techan.NewClosePriceIndicator(series), s.strategyLength)
-	stdevIndicator := techan.NewStandardDeviationIndicator(techan.NewClosePriceIndicator(series))
+func (s *PairArbitrageStrategyTradingService) calculateZScore(klines []domains.IKline) (big.Decimal, error) {
+	if s.hedgeRatioMethod == indicator.HEDGE_RATIO_PRICE_RATIO {
+		s.hedgeRatio = 1
+		series := s.TechanConvertorService.ConvertKlinesToSeries(klines[len(klines)-s.strategyLength-1:], s.klineInterval)
+		return s.calculateZScoreByIndicator(techan.NewClosePriceIndicator(series)), nil
+	}
+
+	hedgeRatioLength := s.hedgeRatioLength
+	if hedgeRatioLength < s.strategyLength+1 {
+		hedgeRatioLength = s.strategyLength + 1
+	}
+	prices1, prices2, err := s.splitPrices(klines[len(klines)-hedgeRatioLength:])
+	if err != nil {
+		return big.ZERO, err
+	}
+
+	hedgeRatio, err := s.HedgeRatioService.Calculate(s.hedgeRatioMethod, prices1, prices2)
+	if err != nil {
+		return big.ZERO, err
+	}
+	s.hedgeRatio = hedgeRatio.Beta
+
+	spreads := hedgeRatio.Spreads[len(hedgeRatio.Spreads)-s.strategyLength-1:]
+	return s.calculateZScoreByIndicator(techan.NewFixedIndicator(spreads...)), nil
+}
+
+func (s *PairArbitrageStrategyTradingService) splitPrices(klines []domains.IKline) ([]float64, []float64, error) {
+	prices1 := make([]float64, 0, len(klines))
+	prices2 := make([]float64, 0, len(klines))
+	for _, kline := range klines {
+		syntheticKline, ok := kline.(*domains.SyntheticKline)
+		if !ok {
+			return nil, nil, fmt.Errorf("unexpected kline type %T", kline)
+		}
+		prices1 = append(prices1, syntheticKline.Close1)
+		prices2 = append(prices2, syntheticKline.Close2)
+	}
+	return prices1, prices2, nil
+}
+
+// checkRegime estimates half-life of the spread and suspends the pair when rolling correlation drops below regimeMinCorrelation
+// or Engle-Granger test doesn't reject absence of cointegration at regimeSignificance level
+func (s *PairArbitrageStrategyTradingService) checkRegime(klines []domains.IKline) {
+	if s.regimeLength <= 0 || len(klines) < s.regimeLength {
+		return
+	}
+
+	prices1, prices2, err := s.splitPrices(klines[len(klines)-s.regimeLength:])
+	if err != nil {
+		zap.S().Errorf("Error on check regime %s-%s: %s", s.coin1.Symbol, s.coin2.Symbol, err.Error())
+		return
+	}
+
+	correlation, errCorrelation := s.CointegrationService.Correlation(prices1, prices2)
+	engleGranger, errEngleGranger := s.CointegrationService.EngleGranger(prices1, prices2)
+	if errCorrelation != nil || errEngleGranger != nil {
+		zap.S().Errorf("Error on check regime %s-%s: %v %v", s.coin1.Symbol, s.coin2.Symbol, errCorrelation, errEngleGranger)
+		return
+	}
+
+	if halfLife, err := s.CointegrationService.HalfLife(engleGranger.Residuals); err == nil {
+		s.halfLife = halfLife
+	}
+
+	suspended := correlation < s.regimeMinCorrelation ||
+		s.regimeSignificance > 0 && !engleGranger.IsCointegratedAt(s.regimeSignificance)
+	if suspended == s.suspended {
+		return
+	}
+	s.suspended = suspended
+
+	state := "resumed"
+	if suspended {
+		state = "suspended"
+	}
+	zap.S().Infof("Pair %s-%s %s: correlation=%.2f adf=%.2f halfLife=%.1f at %v", s.coin1.Symbol, s.coin2.Symbol, state,
+		correlation, engleGranger.AdfStatistic, s.halfLife, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+	telegramApi.SendTextToTelegramChat(fmt.Sprintf("Pair %s-%s %s: correlation %.2f, adf %.2f", s.coin1.Symbol, s.coin2.Symbol, state, correlation, engleGranger.AdfStatistic))
+}
+
+// calculateZScoreByIndicator uses the last strategyLength+1 values of the spread
+func (s *PairArbitrageStrategyTradingService) calculateZScoreByIndicator(spreadIndicator techan.Indicator) big.Decimal {
+	smaIndicator := techan.NewSimpleMovingAverage(spreadIndicator, s.strategyLength)
+	stdevIndicator := techan.NewStandardDeviationIndicator(spreadIndicator)
 
 	stdevValue := stdevIndicator.Calculate(s.strategyLength)
 	if stdevValue.EQ(big.ZERO) {
@@ -264,25 +404,25 @@ func (s *PairArbitrageStrategyTradingService) calculateZScore(klines []domains.I
 	smaValue := smaIndicator.Calculate(s.strategyLength)
 
 	//zsc = (src - sma(src, length)) / selectedStdev
-	zScore := (series.LastCandle().ClosePrice.Sub(smaValue)).Div(stdevValue)
+	zScore := (spreadIndicator.Calculate(s.strategyLength).Sub(smaValue)).Div(stdevValue)
 	return zScore
 }
 
 // CloseOpenedOrderByStopLossIfNeeded if one order closed by stopLoss then close other with current price
 func (s *PairArbitrageStrategyTradingService) CloseOpenedOrderByStopLossIfNeeded(zScore big.Decimal) {
-	openedOrder1, _ := s.TransactionRepo.FindOpenedTransactionByCoinAndTradingKey(s.tradingStrategy, s.coin1.Id, s.getTradingKey())
-	openedOrder2, _ := s.TransactionRepo.FindOpenedTransactionByCoinAndTradingKey(s.tradingStrategy, s.coin2.Id, s.getTradingKey())
+	openedOrder1, _ := s.SignalSink.FindOpenedTransaction(s.coin1, s.getTradingKey())
+	openedOrder2, _ := s.SignalSink.FindOpenedTransaction(s.coin2, s.getTradingKey())
 	var closedOrder1 *domains.Transaction
 	var closedOrder2 *domains.Transaction
 
 	if openedOrder1 != nil {
-		closedOrder1 = s.OrderManagerService.CloseOrderByFixedStopLossOrTakeProfit(s.coin1, openedOrder1, s.klineIntervalS)
+		closedOrder1 = s.SignalSink.CloseByFixedStopLossOrTakeProfit(s.coin1, openedOrder1, s.klineIntervalS)
 		if closedOrder1 != nil {
 			openedOrder1 = nil
 		}
 	}
 	if openedOrder2 != nil {
-		closedOrder2 = s.OrderManagerService.CloseOrderByFixedStopLossOrTakeProfit(s.coin2, openedOrder2, s.klineIntervalS)
+		closedOrder2 = s.SignalSink.CloseByFixedStopLossOrTakeProfit(s.coin2, openedOrder2, s.klineIntervalS)
 		if closedOrder2 != nil {
 			openedOrder2 = nil
 		}
@@ -290,13 +430,13 @@ func (s *PairArbitrageStrategyTradingService) CloseOpenedOrderByStopLossIfNeeded
 	if openedOrder1 == nil && openedOrder2 == nil {
 		zap.S().Infof("Orders closed in exchange %s-%s", s.coin1.Symbol, s.coin2.Symbol)
 
-		s.notifyInTelegram(closedOrder1, closedOrder2, "in exchange")
+		s.notifyInTelegram(closedOrder1, closedOrder2, constants.CLOSE_REASON_EXCHANGE)
 		return
 	}
 
 	//if one of order has been closed by exchange
 	if openedOrder1 == nil && openedOrder2 != nil || openedOrder2 == nil && openedOrder1 != nil {
-		s.closeOrders("by exchange")
+		s.closeOrders(constants.CLOSE_REASON_LEG_CLOSED)
 		return
 	}
 
@@ -309,6 +449,12 @@ func (s *PairArbitrageStrategyTradingService) CloseOpenedOrderByStopLossIfNeeded
 	profitInPercent2 := util.CalculateProfitInPercent(openedOrder2.Price, currentPrice2, openedOrder2.FuturesType)
 
 	sumProfit := profitInPercent1 + profitInPercent2
+	if s.hedgeRatioMethod != indicator.HEDGE_RATIO_PRICE_RATIO {
+		// legs have different cost, profit is related to the average cost of the leg as for equal legs
+		cost1 := openedOrder1.Price * openedOrder1.Amount
+		cost2 := openedOrder2.Price * openedOrder2.Amount
+		sumProfit = (profitInPercent1*cost1 + profitInPercent2*cost2) / ((cost1 + cost2) / 2)
+	}
 
 	zap.S().Debugf("%s-%s: profit=%.2f+%.2f=%.2f%%  openedOrder1.Price-[%.4f] openedOrder2.Price-[%.4f]   currentPrice1-[%.4f] currentPrice2-[%.4f]   zScore=%.2f",
 		s.coin1.Symbol, s.coin2.Symbol,
@@ -318,20 +464,48 @@ func (s *PairArbitrageStrategyTradingService) CloseOpenedOrderByStopLossIfNeeded
 		zScore.Float())
 
 	if sumProfit < s.maxOrderLoss {
-		s.closeOrders("by stopLoss")
+		s.closeOrders(constants.CLOSE_REASON_MAX_LOSS)
 		return
 	}
 	if sumProfit > s.closeOnProfit {
-		s.closeOrders("with profit")
+		s.closeOrders(constants.CLOSE_REASON_PROFIT)
 		return
 	}
 	if zScore.Abs().LT(big.NewDecimal(s.zScoreCloseToZero)) && sumProfit > s.zScoreMinProfit {
-		s.closeOrders("by zScore")
+		s.closeOrders(constants.CLOSE_REASON_Z_SCORE)
+		return
+	}
+	if s.zScoreStopLoss > 0 && zScore.Abs().GT(big.NewDecimal(s.zScoreStopLoss)) {
+		s.closeOrders(constants.CLOSE_REASON_Z_SCORE_STOP)
+		return
+	}
+	if s.isHoldingTimeExceeded(openedOrder1) {
+		s.closeOrders(constants.CLOSE_REASON_TIME_STOP)
 		return
 	}
 }
 
-func (s *PairArbitrageStrategyTradingService) notifyInTelegram(closedOrder1 *domains.Transaction, closedOrder2 *domains.Transaction, closeReason string) {
+// isHoldingTimeExceeded compares age of position with half-life of the spread known at opening,
+// current half-life is used when position was opened before restart
+func (s *PairArbitrageStrategyTradingService) isHoldingTimeExceeded(openedOrder *domains.Transaction) bool {
+	maxHoldingKlines := float64(s.maxHoldingKlines)
+
+	halfLife := s.entryHalfLife
+	if halfLife <= 0 {
+		halfLife = s.halfLife
+	}
+	if s.halfLifeMultiplier > 0 && halfLife > 0 && (maxHoldingKlines <= 0 || s.halfLifeMultiplier*halfLife < maxHoldingKlines) {
+		maxHoldingKlines = s.halfLifeMultiplier * halfLife
+	}
+	if maxHoldingKlines <= 0 {
+		return false
+	}
+
+	holdingKlines := s.Clock.NowTime().Sub(openedOrder.CreatedAt).Minutes() / float64(s.klineInterval)
+	return holdingKlines > maxHoldingKlines
+}
+
+func (s *PairArbitrageStrategyTradingService) notifyInTelegram(closedOrder1 *domains.Transaction, closedOrder2 *domains.Transaction, closeReason constants.CloseReason) {
 	profit := int64(0)
 	profitPercent := float64(0)
 	if closedOrder1 != nil {
@@ -343,21 +517,21 @@ func (s *PairArbitrageStrategyTradingService) notifyInTelegram(closedOrder1 *dom
 		profitPercent += closedOrder2.PercentProfit.Float64
 	}
 	zap.S().Infof("Close orders [%v-%v] with profit[%.2f] at %v", s.coin1.Symbol, s.coin2.Symbol, profitPercent, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
-	telegramApi.SendTextToTelegramChat(fmt.Sprintf("Closed %s %v - %v profit: %+d (%.4f%%)", closeReason, s.coin1.Symbol, s.coin2.Symbol, profit, profitPercent))
+	telegramApi.SendTextToTelegramChat(fmt.Sprintf("Closed by %s %v - %v profit: %+d (%.4f%%)", closeReason, s.coin1.Symbol, s.coin2.Symbol, profit, profitPercent))
 }
 
-func (s *PairArbitrageStrategyTradingService) closeOrders(closeReason string) (*domains.Transaction, *domains.Transaction) {
+func (s *PairArbitrageStrategyTradingService) closeOrders(closeReason constants.CloseReason) (*domains.Transaction, *domains.Transaction) {
 	zap.S().Infof("Close orders %s %s - %s ", closeReason, s.coin1.Symbol, s.coin2.Symbol)
-	openedOrder1, _ := s.TransactionRepo.FindOpenedTransactionByCoinAndTradingKey(s.tradingStrategy, s.coin1.Id, s.getTradingKey())
+	openedOrder1, _ := s.SignalSink.FindOpenedTransaction(s.coin1, s.getTradingKey())
 	var closedOrder1 *domains.Transaction
 	if openedOrder1 != nil {
-		closedOrder1 = s.OrderManagerService.CloseFuturesOrderWithCurrentPrice(s.coin1, openedOrder1)
+		closedOrder1 = s.SignalSink.Emit(signal.NewCloseSignal(s.coin1, openedOrder1, 0, closeReason))
 	}
 
-	openedOrder2, _ := s.TransactionRepo.FindOpenedTransactionByCoinAndTradingKey(s.tradingStrategy, s.coin2.Id, s.getTradingKey())
+	openedOrder2, _ := s.SignalSink.FindOpenedTransaction(s.coin2, s.getTradingKey())
 	var closedOrder2 *domains.Transaction
 	if openedOrder2 != nil {
-		closedOrder2 = s.OrderManagerService.CloseFuturesOrderWithCurrentPrice(s.coin2, openedOrder2)
+		closedOrder2 = s.SignalSink.Emit(signal.NewCloseSignal(s.coin2, openedOrder2, 0, closeReason))
 	}
 
 	s.notifyInTelegram(closedOrder1, closedOrder2, closeReason)
@@ -366,19 +540,21 @@ func (s *PairArbitrageStrategyTradingService) closeOrders(closeReason string) (*
 }
 
 func (s *PairArbitrageStrategyTradingService) hasOpenedOrders() bool {
-	openedOrder1, _ := s.TransactionRepo.FindOpenedTransactionByCoinAndTradingKey(s.tradingStrategy, s.coin1.Id, s.getTradingKey())
-	openedOrder2, _ := s.TransactionRepo.FindOpenedTransactionByCoinAndTradingKey(s.tradingStrategy, s.coin2.Id, s.getTradingKey())
+	openedOrder1, _ := s.SignalSink.FindOpenedTransaction(s.coin1, s.getTradingKey())
+	openedOrder2, _ := s.SignalSink.FindOpenedTransaction(s.coin2, s.getTradingKey())
 
 	return openedOrder1 != nil || openedOrder2 != nil
 }
 
 func (s *PairArbitrageStrategyTradingService) openOrder(coin *domains.Coin, futuresType futureType.FuturesType) {
 	stopLossPrice := s.calculateOrderStopLoss(coin, futuresType)
-	orderCost := s.calculateCostForOrder()
+	orderCost := s.calculateCostForOrder(coin)
 
 	zap.S().Debugf("Open order for %v with cost %v", coin.Symbol, orderCost)
 
-	s.OrderManagerService.OpenFuturesOrderWithCostAndFixedStopLossAndTakeProfit(coin, s.getTradingKey(), futuresType, orderCost, stopLossPrice, 0)
+	openSignal := signal.NewOpenSignal(coin, s.getTradingKey(), futuresType, orderCost, "")
+	openSignal.StopLossPrice = stopLossPrice
+	s.SignalSink.Emit(openSignal)
 }
 
 func (s *PairArbitrageStrategyTradingService) calculateOrderStopLoss(coin *domains.Coin, futuresType futureType.FuturesType) float64 {
@@ -390,13 +566,33 @@ func (s *PairArbitrageStrategyTradingService) calculateOrderStopLoss(coin *domai
 	return float64(0)
 }
 
-func (s *PairArbitrageStrategyTradingService) calculateCostForOrder() float64 {
+func (s *PairArbitrageStrategyTradingService) calculateCostForOrder(coin *domains.Coin) float64 {
 	sumOfProfitByCoin1, _ := s.TransactionRepo.CalculateSumOfProfitByCoinAndTradingKey(s.coin1.Id, s.tradingStrategy, s.getTradingKey())
 	sumOfProfitByCoin2, _ := s.TransactionRepo.CalculateSumOfProfitByCoinAndTradingKey(s.coin2.Id, s.tradingStrategy, s.getTradingKey())
+	capital := (int64(s.startCapitalInCents) + sumOfProfitByCoin1 + sumOfProfitByCoin2) * int64(s.leverage)
+	if allocatedCapital, ok := s.OrderManagerService.GetAllocatedCapital(); ok {
+		// both legs share the allocation, a bit is left for the price change between the legs
+		capital = util.GetCents(allocatedCapital*0.99) * int64(s.leverage)
+	}
+
+	if s.hedgeRatioMethod == indicator.HEDGE_RATIO_PRICE_RATIO {
+		return util.GetDollarsByCents(capital / 2)
+	}
 
-	return util.GetDollarsByCents(((int64(s.startCapitalInCents) + sumOfProfitByCoin1 + sumOfProfitByCoin2) / 2) * int64(s.leverage))
+	// amount2 = beta * amount1, so cost of legs are proportional to p1 and beta*p2
+	currentPrice1, err1 := s.ExchangeDataService.GetCurrentPriceForFutures(s.coin1, s.klineInterval)
+	currentPrice2, err2 := s.ExchangeDataService.GetCurrentPriceForFutures(s.coin2, s.klineInterval)
+	if err1 != nil || err2 != nil || currentPrice1+s.hedgeRatio*currentPrice2 <= 0 {
+		return util.GetDollarsByCents(capital / 2)
+	}
+
+	legWeight := currentPrice1 / (currentPrice1 + s.hedgeRatio*currentPrice2)
+	if coin.Id == s.coin2.Id {
+		legWeight = 1 - legWeight
+	}
+	return util.GetDollarsByCents(capital) * legWeight
 }
 
 func (s *PairArbitrageStrategyTradingService) getTradingKey() string {
-	return s.coin1.Symbol + "-" + s.coin2.Symbol
+	return s.tradingKey
 }
@@ -0,0 +1,214 @@
+package trading
+
+import (
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/constants/futureType"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/exchange"
+	"cryptoBot/pkg/service/indicator"
+	"cryptoBot/pkg/service/indicator/expression"
+	"cryptoBot/pkg/service/indicator/techanLib"
+	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/signal"
+	"cryptoBot/pkg/util"
+	"github.com/sdcoffey/techan"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// RuleBasedStrategyRules keeps entry and exit conditions of the strategy, nil condition is never satisfied
+type RuleBasedStrategyRules struct {
+	EntryLong  *expression.Expression
+	EntryShort *expression.Expression
+	ExitLong   *expression.Expression
+	ExitShort  *expression.Expression
+}
+
+func NewRuleBasedStrategyRules(entryLong string, entryShort string, exitLong string, exitShort string) (*RuleBasedStrategyRules, error) {
+	rules := &RuleBasedStrategyRules{}
+
+	for _, rule := range []struct {
+		source string
+		target **expression.Expression
+	}{
+		{entryLong, &rules.EntryLong},
+		{entryShort, &rules.EntryShort},
+		{exitLong, &rules.ExitLong},
+		{exitShort, &rules.ExitShort},
+	} {
+		if rule.source == "" {
+			continue
+		}
+		parsed, err := expression.Parse(rule.source)
+		if err != nil {
+			return nil, err
+		}
+		*rule.target = parsed
+	}
+
+	return rules, nil
+}
+
+func (r *RuleBasedStrategyRules) Lookback() int {
+	result := 0
+	for _, rule := range []*expression.Expression{r.EntryLong, r.EntryShort, r.ExitLong, r.ExitShort} {
+		if rule != nil && rule.Lookback() > result {
+			result = rule.Lookback()
+		}
+	}
+	return result
+}
+
+// NewRuleBasedStrategyConfigs reads strategy.ruleBased.strategies from config.yml
+func NewRuleBasedStrategyConfigs() []*StrategyConfig {
+	return loadStrategyConfigs(RULE_BASED_STRATEGY, "strategy.ruleBased.strategies")
+}
+
+// Strategy without hand-written logic, entries and exits are expressions from config.yml
+func NewRuleBasedStrategyTradingService(
+	transactionRepo repository.Transaction,
+	clock date.Clock,
+	exchangeDataService *exchange.DataService,
+	klinesFetcherService *exchange.KlinesFetcherService,
+	orderManagerService *orders.OrderManagerService,
+	signalSink signal.Sink,
+	techanConvertorService *techanLib.TechanConvertorService,
+	coin *domains.Coin,
+	rules *RuleBasedStrategyRules,
+	klineInterval int,
+) *RuleBasedStrategyTradingService {
+	return &RuleBasedStrategyTradingService{
+		TransactionRepo:        transactionRepo,
+		Clock:                  clock,
+		ExchangeDataService:    exchangeDataService,
+		KlinesFetcherService:   klinesFetcherService,
+		OrderManagerService:    orderManagerService,
+		SignalSink:             signalSink,
+		TechanConvertorService: techanConvertorService,
+		coin:                   coin,
+		rules:                  rules,
+		klineInterval:          klineInterval,
+		klineIntervalS:         strconv.Itoa(klineInterval),
+		historySize:            rules.Lookback()*3 + 2,
+		leverage:               1,
+		stopLossPercent:        0, //disabled
+		takeProfitPercent:      0, //disabled
+		costOfOrderInCents:     100 * 100,
+		tradingStrategy:        constants.RULE_BASED,
+	}
+}
+
+type RuleBasedStrategyTradingService struct {
+	TransactionRepo        repository.Transaction
+	Clock                  date.Clock
+	ExchangeDataService    *exchange.DataService
+	KlinesFetcherService   *exchange.KlinesFetcherService
+	OrderManagerService    *orders.OrderManagerService
+	SignalSink             signal.Sink
+	TechanConvertorService *techanLib.TechanConvertorService
+	/* Positions are opened in sessions outside of blackouts only, closing is always allowed */
+	SessionsService    *indicator.SessionsService
+	coin               *domains.Coin
+	rules              *RuleBasedStrategyRules
+	klineInterval      int
+	klineIntervalS     string
+	historySize        int
+	leverage           int
+	stopLossPercent    float64
+	takeProfitPercent  float64
+	costOfOrderInCents int
+	tradingStrategy    constants.TradingStrategy
+}
+
+func (s *RuleBasedStrategyTradingService) BotAction(coin *domains.Coin) {
+	return
+}
+
+func (s *RuleBasedStrategyTradingService) InitializeTrading(coin *domains.Coin) error {
+	return nil
+}
+
+func (s *RuleBasedStrategyTradingService) Initialize() error {
+	err := s.SignalSink.SetFuturesLeverage(s.coin, s.leverage)
+	if err != nil {
+		return err
+	}
+
+	s.KlinesFetcherService.FetchActualKlines(s.coin, s.klineInterval)
+
+	return nil
+}
+
+func (s *RuleBasedStrategyTradingService) BeforeExecute() {
+	return
+}
+
+func (s *RuleBasedStrategyTradingService) Execute() {
+	s.KlinesFetcherService.FetchActualKlines(s.coin, s.klineInterval)
+
+	openedOrder, _ := s.SignalSink.FindOpenedTransaction(s.coin, "")
+	if openedOrder != nil {
+		if closedOrder := s.SignalSink.CloseByFixedStopLossOrTakeProfit(s.coin, openedOrder, s.klineIntervalS); closedOrder != nil {
+			return
+		}
+	}
+
+	series := s.TechanConvertorService.BuildTimeSeriesByKlines(s.coin, s.klineIntervalS, int64(s.historySize))
+	if series == nil || series.LastIndex() < s.rules.Lookback() {
+		zap.S().Errorf("Not enough klines for %s at %s", s.coin.Symbol, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		return
+	}
+
+	if openedOrder != nil {
+		exitRule := s.rules.ExitLong
+		if openedOrder.FuturesType == futureType.SHORT {
+			exitRule = s.rules.ExitShort
+		}
+		if exitRule != nil && exitRule.Evaluate(series) {
+			zap.S().Infof("EXIT SIGNAL [%s] at %v", exitRule.String(), s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+			closeSignal := signal.NewCloseSignal(s.coin, openedOrder, 0, constants.CLOSE_REASON_SIGNAL)
+			closeSignal.Indicators = map[string]float64{"close": series.LastCandle().ClosePrice.Float()}
+			s.SignalSink.Emit(closeSignal)
+		}
+		return
+	}
+
+	if s.rules.EntryLong != nil && s.rules.EntryLong.Evaluate(series) {
+		zap.S().Infof("LONG SIGNAL [%s] at %v", s.rules.EntryLong.String(), s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		s.openOrder(futureType.LONG, s.rules.EntryLong, series)
+	} else if s.rules.EntryShort != nil && s.rules.EntryShort.Evaluate(series) {
+		zap.S().Infof("SHORT SIGNAL [%s] at %v", s.rules.EntryShort.String(), s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		s.openOrder(futureType.SHORT, s.rules.EntryShort, series)
+	}
+}
+
+func (s *RuleBasedStrategyTradingService) openOrder(futuresType futureType.FuturesType, rule *expression.Expression, series *techan.TimeSeries) {
+	if s.SessionsService != nil && !s.SessionsService.IsTradingAllowedNow() {
+		zap.S().Infof("Trading of %s isn't allowed by sessions at %v", s.coin.Symbol, s.Clock.NowTime().Format(constants.DATE_TIME_FORMAT))
+		return
+	}
+
+	currentPrice, err := s.ExchangeDataService.GetCurrentPrice(s.coin)
+	if err != nil {
+		zap.S().Errorf("Error during GetCurrentPrice at %v: %s", s.Clock.NowTime(), err.Error())
+		return
+	}
+
+	stopLoss := float64(0)
+	if s.stopLossPercent > 0 {
+		stopLoss = util.CalculatePriceForStopLoss(currentPrice, s.stopLossPercent, futuresType)
+	}
+	takeProfit := float64(0)
+	if s.takeProfitPercent > 0 {
+		takeProfit = util.CalculatePriceForTakeProfit(currentPrice, s.takeProfitPercent, futuresType)
+	}
+
+	openSignal := signal.NewOpenSignal(s.coin, "", futuresType, util.GetDollarsByCents(int64(s.costOfOrderInCents)), rule.String())
+	openSignal.Price = currentPrice
+	openSignal.StopLossPrice = stopLoss
+	openSignal.TakeProfitPrice = takeProfit
+	openSignal.Indicators = map[string]float64{"close": series.LastCandle().ClosePrice.Float()}
+	s.SignalSink.Emit(openSignal)
+}
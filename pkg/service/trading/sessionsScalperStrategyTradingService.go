@@ -10,6 +10,8 @@ import (
 	"cryptoBot/pkg/service/indicator"
 	"cryptoBot/pkg/service/indicator/techanLib"
 	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/signal"
+	"cryptoBot/pkg/service/state"
 	"cryptoBot/pkg/util"
 	"github.com/sdcoffey/techan"
 	"github.com/spf13/viper"
@@ -18,8 +20,6 @@ import (
 )
 
 // https://youtu.be/ZE0ACEx1U84
-var sessionsScalperStrategyTradingService *SessionsScalperStrategyTradingService
-
 func NewSessionsScalperStrategyTradingService(
 	transactionRepo repository.Transaction,
 	clock date.Clock,
@@ -27,6 +27,7 @@ func NewSessionsScalperStrategyTradingService(
 	klineRepo repository.Kline,
 	klinesFetcherService *exchange.KlinesFetcherService,
 	orderManagerService *orders.OrderManagerService,
+	signalSink signal.Sink,
 	techanConvertorService *techanLib.TechanConvertorService,
 	stochasticService *indicator.StochasticService,
 	smaTubeService *indicator.SmaTubeService,
@@ -34,16 +35,14 @@ func NewSessionsScalperStrategyTradingService(
 	sessionsService *indicator.SessionsService,
 	klineInterval int,
 ) *SessionsScalperStrategyTradingService {
-	if sessionsScalperStrategyTradingService != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	sessionsScalperStrategyTradingService = &SessionsScalperStrategyTradingService{
+	return &SessionsScalperStrategyTradingService{
 		KlineRepo:                 klineRepo,
 		TransactionRepo:           transactionRepo,
 		Clock:                     clock,
 		ExchangeDataService:       exchangeDataService,
 		KlinesFetcherService:      klinesFetcherService,
 		OrderManagerService:       orderManagerService,
+		SignalSink:                signalSink,
 		TechanConvertorService:    techanConvertorService,
 		StochasticService:         stochasticService,
 		SmaTubeService:            smaTubeService,
@@ -59,8 +58,8 @@ func NewSessionsScalperStrategyTradingService(
 		takeProfitRatio:           1.5,
 		costOfOrderInCents:        100 * 100,
 		tradingStrategy:           constants.SESSION_SCALPER,
+		leverage:                  viper.GetInt("strategy.sessionsScalper.futures.leverage"),
 	}
-	return sessionsScalperStrategyTradingService
 }
 
 type SessionsScalperStrategyTradingService struct {
@@ -70,6 +69,7 @@ type SessionsScalperStrategyTradingService struct {
 	ExchangeDataService       *exchange.DataService
 	KlinesFetcherService      *exchange.KlinesFetcherService
 	OrderManagerService       *orders.OrderManagerService
+	SignalSink                signal.Sink
 	TechanConvertorService    *techanLib.TechanConvertorService
 	StochasticService         *indicator.StochasticService
 	SmaTubeService            *indicator.SmaTubeService
@@ -85,10 +85,33 @@ type SessionsScalperStrategyTradingService struct {
 	takeProfitRatio           float64
 	costOfOrderInCents        int
 	tradingStrategy           constants.TradingStrategy
+	leverage                  int
+	coin                      *domains.Coin
+
+	/* Restores state after restart, nil in backtests */
+	StrategyStateService *state.StrategyStateService
+	stateKey             string
+	state                *state.StrategyState
+}
+
+func (s *SessionsScalperStrategyTradingService) Initialize() error {
+	if err := s.loadState(); err != nil {
+		return err
+	}
+	return s.InitializeTrading(s.coin)
+}
+
+func (s *SessionsScalperStrategyTradingService) BeforeExecute() {
+	return
+}
+
+func (s *SessionsScalperStrategyTradingService) Execute() {
+	s.BotAction(s.coin)
+	s.saveState()
 }
 
 func (s *SessionsScalperStrategyTradingService) InitializeTrading(coin *domains.Coin) error {
-	err := s.OrderManagerService.SetFuturesLeverage(coin, viper.GetInt("strategy.sessionsScalper.futures.leverage"))
+	err := s.SignalSink.SetFuturesLeverage(coin, s.leverage)
 	if err != nil {
 		return err
 	}
@@ -102,12 +125,12 @@ func (s *SessionsScalperStrategyTradingService) BotAction(coin *domains.Coin) {
 	s.KlinesFetcherService.FetchActualKlines(coin, s.klineInterval)
 
 	s.closeOrderIfNeeded(coin)
-	openedOrder, _ := s.TransactionRepo.FindOpenedTransaction(s.tradingStrategy)
+	openedOrder, _ := s.SignalSink.FindOpenedTransaction(coin, "")
 	if openedOrder != nil {
 		return
 	}
 
-	if !s.SessionsService.IsSuitableSessionNow() {
+	if !s.SessionsService.IsTradingAllowedNow() {
 		return
 	}
 
@@ -158,9 +181,9 @@ func (s *SessionsScalperStrategyTradingService) BotAction(coin *domains.Coin) {
 }
 
 func (s *SessionsScalperStrategyTradingService) closeOrderIfNeeded(coin *domains.Coin) {
-	openedOrder, _ := s.TransactionRepo.FindOpenedTransaction(s.tradingStrategy)
+	openedOrder, _ := s.SignalSink.FindOpenedTransaction(coin, "")
 	if openedOrder != nil {
-		s.OrderManagerService.CloseOrderByFixedStopLossOrTakeProfit(coin, openedOrder, strconv.Itoa(s.klineInterval))
+		s.SignalSink.CloseByFixedStopLossOrTakeProfit(coin, openedOrder, strconv.Itoa(s.klineInterval))
 	}
 }
 
@@ -169,5 +192,34 @@ func (s *SessionsScalperStrategyTradingService) openOrder(coin *domains.Coin, st
 	currentPrice, _ := s.ExchangeDataService.GetCurrentPrice(coin)
 	takeProfit := util.CalculateProfitByRation(currentPrice, stopLoss, stochasticFuturesTypeSignal, s.takeProfitRatio)
 
-	s.OrderManagerService.OpenFuturesOrderWithCostAndFixedStopLossAndTakeProfit(coin, "", stochasticFuturesTypeSignal, util.GetDollarsByCents(int64(s.costOfOrderInCents)), stopLoss, takeProfit)
+	openSignal := signal.NewOpenSignal(coin, "", stochasticFuturesTypeSignal, util.GetDollarsByCents(int64(s.costOfOrderInCents)), "")
+	openSignal.StopLossPrice = stopLoss
+	openSignal.TakeProfitPrice = takeProfit
+	s.SignalSink.Emit(openSignal)
+}
+
+// loadState restores statuses of the setup, so restart doesn't reset it
+func (s *SessionsScalperStrategyTradingService) loadState() error {
+	if s.StrategyStateService == nil {
+		return nil
+	}
+
+	loadedState, err := s.StrategyStateService.Load(s.stateKey)
+	if err != nil {
+		return err
+	}
+	s.state = loadedState
+	s.waitingCrossingFastSMA = s.state.GetBool("waitingCrossingFastSMA", s.waitingCrossingFastSMA)
+	return nil
+}
+
+func (s *SessionsScalperStrategyTradingService) saveState() {
+	if s.state == nil {
+		return
+	}
+
+	s.state.SetBool("waitingCrossingFastSMA", s.waitingCrossingFastSMA)
+	if err := s.StrategyStateService.Save(s.state); err != nil {
+		zap.S().Errorf("Error during saving state of %s: %s", s.stateKey, err.Error())
+	}
 }
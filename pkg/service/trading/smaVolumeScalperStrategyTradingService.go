@@ -10,6 +10,8 @@ import (
 	"cryptoBot/pkg/service/indicator"
 	"cryptoBot/pkg/service/indicator/techanLib"
 	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/signal"
+	"cryptoBot/pkg/service/state"
 	"cryptoBot/pkg/util"
 	"github.com/sdcoffey/techan"
 	"github.com/spf13/viper"
@@ -19,8 +21,6 @@ import (
 )
 
 // https://youtu.be/ZE0ACEx1U84
-var smaVolumeScalperStrategyTradingService *SmaVolumeScalperStrategyTradingService
-
 func NewSmaVolumeScalperStrategyTradingService(
 	transactionRepo repository.Transaction,
 	clock date.Clock,
@@ -28,6 +28,7 @@ func NewSmaVolumeScalperStrategyTradingService(
 	klineRepo repository.Kline,
 	klinesFetcherService *exchange.KlinesFetcherService,
 	orderManagerService *orders.OrderManagerService,
+	signalSink signal.Sink,
 	techanConvertorService *techanLib.TechanConvertorService,
 	stochasticService *indicator.StochasticService,
 	smaTubeService *indicator.SmaTubeService,
@@ -35,16 +36,14 @@ func NewSmaVolumeScalperStrategyTradingService(
 	relativeVolumeIndicatorService *indicator.RelativeVolumeIndicatorService,
 	klineInterval int,
 ) *SmaVolumeScalperStrategyTradingService {
-	if smaVolumeScalperStrategyTradingService != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	smaVolumeScalperStrategyTradingService = &SmaVolumeScalperStrategyTradingService{
+	return &SmaVolumeScalperStrategyTradingService{
 		KlineRepo:                      klineRepo,
 		TransactionRepo:                transactionRepo,
 		Clock:                          clock,
 		ExchangeDataService:            exchangeDataService,
 		KlinesFetcherService:           klinesFetcherService,
 		OrderManagerService:            orderManagerService,
+		SignalSink:                     signalSink,
 		TechanConvertorService:         techanConvertorService,
 		StochasticService:              stochasticService,
 		SmaTubeService:                 smaTubeService,
@@ -60,6 +59,7 @@ func NewSmaVolumeScalperStrategyTradingService(
 		takeProfitRatio:                0.35,
 		costOfOrderInCents:             100 * 100,
 		tradingStrategy:                constants.SMA_VOLUME_SCALPER,
+		leverage:                       viper.GetInt("strategy.smaVolumeScalper.futures.leverage"),
 		sma21Length:                    21,
 		sma50Length:                    50,
 		sma100Length:                   100,
@@ -70,7 +70,6 @@ func NewSmaVolumeScalperStrategyTradingService(
 		BULL_TREND_STATUS: false,
 		BEAR_TREND_STATUS: false,
 	}
-	return smaVolumeScalperStrategyTradingService
 }
 
 type SmaVolumeScalperStrategyTradingService struct {
@@ -80,6 +79,7 @@ type SmaVolumeScalperStrategyTradingService struct {
 	ExchangeDataService            *exchange.DataService
 	KlinesFetcherService           *exchange.KlinesFetcherService
 	OrderManagerService            *orders.OrderManagerService
+	SignalSink                     signal.Sink
 	TechanConvertorService         *techanLib.TechanConvertorService
 	StochasticService              *indicator.StochasticService
 	SmaTubeService                 *indicator.SmaTubeService
@@ -99,15 +99,38 @@ type SmaVolumeScalperStrategyTradingService struct {
 	takeProfitRatio                float64
 	costOfOrderInCents             int
 	tradingStrategy                constants.TradingStrategy
+	leverage                       int
+	coin                           *domains.Coin
 
 	BULL_STATUS       bool
 	BEAR_STATUS       bool
 	BULL_TREND_STATUS bool
 	BEAR_TREND_STATUS bool
+
+	/* Restores state after restart, nil in backtests */
+	StrategyStateService *state.StrategyStateService
+	stateKey             string
+	state                *state.StrategyState
+}
+
+func (s *SmaVolumeScalperStrategyTradingService) Initialize() error {
+	if err := s.loadState(); err != nil {
+		return err
+	}
+	return s.InitializeTrading(s.coin)
+}
+
+func (s *SmaVolumeScalperStrategyTradingService) BeforeExecute() {
+	return
+}
+
+func (s *SmaVolumeScalperStrategyTradingService) Execute() {
+	s.BotAction(s.coin)
+	s.saveState()
 }
 
 func (s *SmaVolumeScalperStrategyTradingService) InitializeTrading(coin *domains.Coin) error {
-	err := s.OrderManagerService.SetFuturesLeverage(coin, viper.GetInt("strategy.smaVolumeScalper.futures.leverage"))
+	err := s.SignalSink.SetFuturesLeverage(coin, s.leverage)
 	if err != nil {
 		return err
 	}
@@ -121,7 +144,7 @@ func (s *SmaVolumeScalperStrategyTradingService) BotAction(coin *domains.Coin) {
 	s.KlinesFetcherService.FetchActualKlines(coin, s.klineInterval)
 
 	s.closeOrderIfNeeded(coin)
-	openedOrder, _ := s.TransactionRepo.FindOpenedTransaction(s.tradingStrategy)
+	openedOrder, _ := s.SignalSink.FindOpenedTransaction(coin, "")
 	//if openedOrder != nil {
 	//	return
 	//}
@@ -131,7 +154,7 @@ func (s *SmaVolumeScalperStrategyTradingService) BotAction(coin *domains.Coin) {
 		currentProfit, _ := s.OrderManagerService.CalculateCurrentProfitInPercentWithoutLeverage(coin, openedOrder)
 		minProfitInPercent := 0.46
 		if currentProfit > minProfitInPercent {
-			s.OrderManagerService.CloseFuturesOrderWithCurrentPrice(coin, openedOrder)
+			s.SignalSink.Emit(signal.NewCloseSignal(coin, openedOrder, 0, constants.CLOSE_REASON_SIGNAL))
 		} else {
 			return
 		}
@@ -240,14 +263,14 @@ func (s *SmaVolumeScalperStrategyTradingService) BotAction(coin *domains.Coin) {
 }
 
 func (s *SmaVolumeScalperStrategyTradingService) closeOrderIfNeeded(coin *domains.Coin) {
-	openedOrder, _ := s.TransactionRepo.FindOpenedTransaction(s.tradingStrategy)
+	openedOrder, _ := s.SignalSink.FindOpenedTransaction(coin, "")
 	if openedOrder != nil {
-		s.OrderManagerService.CloseOrderByFixedStopLossOrTakeProfit(coin, openedOrder, strconv.Itoa(s.klineInterval))
+		s.SignalSink.CloseByFixedStopLossOrTakeProfit(coin, openedOrder, strconv.Itoa(s.klineInterval))
 	}
 
-	openedOrder, _ = s.TransactionRepo.FindOpenedTransaction(s.tradingStrategy)
+	openedOrder, _ = s.SignalSink.FindOpenedTransaction(coin, "")
 	if openedOrder != nil && s.OrderManagerService.ShouldCloseByTrailingTakeProfitWithoutLeverage(coin, openedOrder) {
-		s.OrderManagerService.CloseFuturesOrderWithCurrentPrice(coin, openedOrder)
+		s.SignalSink.Emit(signal.NewCloseSignal(coin, openedOrder, 0, constants.CLOSE_REASON_SIGNAL))
 	}
 }
 
@@ -265,27 +288,51 @@ func (s *SmaVolumeScalperStrategyTradingService) openOrder(coin *domains.Coin, f
 		return
 	}
 
-	isNextOrderFake := s.isNextOrderFake(coin)
 	costOrOrder := s.calculateCurrentWalletValue(coin)
 
-	s.OrderManagerService.OpenFuturesOrderWithCostAndFixedStopLossAndTakeProfitAndFake(coin, "", futuresTypeSignal, costOrOrder, stopLoss, takeProfit, isNextOrderFake)
+	openSignal := signal.NewOpenSignal(coin, "", futuresTypeSignal, costOrOrder, "")
+	openSignal.StopLossPrice = stopLoss
+	openSignal.TakeProfitPrice = takeProfit
+	// equity curve filter of the order manager decides whether the executed order is fake
+	s.SignalSink.Emit(openSignal)
 }
 
-func (s *SmaVolumeScalperStrategyTradingService) isNextOrderFake(coin *domains.Coin) bool {
-	transaction, err := s.TransactionRepo.FindLastByCoinId(coin.Id, s.tradingStrategy)
-	if transaction == nil || err != nil {
-		return false
-	}
+func (s *SmaVolumeScalperStrategyTradingService) calculateCurrentWalletValue(coin *domains.Coin) float64 {
+	sumOfProfitByCoin, _ := s.TransactionRepo.CalculateSumOfProfitByCoin(coin.Id, s.tradingStrategy)
 
-	if transaction.Profit.Valid {
-		return transaction.Profit.Int64 < 0
+	return util.GetDollarsByCents((int64(s.costOfOrderInCents) + sumOfProfitByCoin) * viper.GetInt64("strategy.smaVolumeScalper.futures.leverage"))
+}
+
+// loadState restores statuses of the setup, so restart doesn't reset it
+func (s *SmaVolumeScalperStrategyTradingService) loadState() error {
+	if s.StrategyStateService == nil {
+		return nil
 	}
 
-	return false
+	loadedState, err := s.StrategyStateService.Load(s.stateKey)
+	if err != nil {
+		return err
+	}
+	s.state = loadedState
+	s.waitingCrossingFastSMA = s.state.GetBool("waitingCrossingFastSMA", s.waitingCrossingFastSMA)
+	s.BULL_STATUS = s.state.GetBool("bullStatus", s.BULL_STATUS)
+	s.BEAR_STATUS = s.state.GetBool("bearStatus", s.BEAR_STATUS)
+	s.BULL_TREND_STATUS = s.state.GetBool("bullTrendStatus", s.BULL_TREND_STATUS)
+	s.BEAR_TREND_STATUS = s.state.GetBool("bearTrendStatus", s.BEAR_TREND_STATUS)
+	return nil
 }
 
-func (s *SmaVolumeScalperStrategyTradingService) calculateCurrentWalletValue(coin *domains.Coin) float64 {
-	sumOfProfitByCoin, _ := s.TransactionRepo.CalculateSumOfProfitByCoin(coin.Id, s.tradingStrategy)
+func (s *SmaVolumeScalperStrategyTradingService) saveState() {
+	if s.state == nil {
+		return
+	}
 
-	return util.GetDollarsByCents((int64(s.costOfOrderInCents) + sumOfProfitByCoin) * viper.GetInt64("strategy.smaVolumeScalper.futures.leverage"))
+	s.state.SetBool("waitingCrossingFastSMA", s.waitingCrossingFastSMA)
+	s.state.SetBool("bullStatus", s.BULL_STATUS)
+	s.state.SetBool("bearStatus", s.BEAR_STATUS)
+	s.state.SetBool("bullTrendStatus", s.BULL_TREND_STATUS)
+	s.state.SetBool("bearTrendStatus", s.BEAR_TREND_STATUS)
+	if err := s.StrategyStateService.Save(s.state); err != nil {
+		zap.S().Errorf("Error during saving state of %s: %s", s.stateKey, err.Error())
+	}
 }
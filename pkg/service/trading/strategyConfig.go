@@ -0,0 +1,197 @@
+package trading
+
+import (
+	"cryptoBot/pkg/constants"
+	"fmt"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StrategyAccount keeps names of env variables with exchange credentials of the strategy instance
+type StrategyAccount struct {
+	ApiKeyEnv    string
+	ApiSecretEnv string
+}
+
+// Name returns account of the env variable, e.g. PairTrading1 of BYBIT_PairTrading1_API_KEY, empty without credentials
+func (a StrategyAccount) Name() string {
+	return strings.TrimSuffix(strings.TrimPrefix(a.ApiKeyEnv, "BYBIT_"), "_API_KEY")
+}
+
+// StrategyConfig describes one strategy instance hosted by StrategyRegistry
+type StrategyConfig struct {
+	/* Name of the registered strategy factory, e.g. PAIR_ARBITRAGE_STRATEGY */
+	Strategy string
+
+	/* Optional name distinguishing instances of the same strategy on the same coins */
+	Name string
+
+	/* Value saved to transaction_table.trading_strategy, 0 - default value of the strategy */
+	TradingStrategy constants.TradingStrategy
+
+	Coins   []string
+	Account StrategyAccount
+
+	/* Kline interval in minutes, 0 - default value of the strategy */
+	Interval int
+
+	/* Strategy specific parameters overriding defaults, e.g. strategyLength for pair arbitrage */
+	Params map[string]interface{}
+
+	/* Params read by the factory, set only on the copy tracked by StrategyRegistry.Build */
+	usedParams map[string]bool
+}
+
+// loaderParams are fields of config.yml items kept in StrategyConfig fields by loadStrategyConfigs, factories don't read them
+var loaderParams = map[string]bool{"name": true, "coin": true, "interval": true, "tradingstrategy": true, "apikeyenv": true, "apisecretenv": true}
+
+// Key identifies the instance in the registry, capital allocator and state store. Without name the key includes
+// the account and the trading strategy id when they are set, so the same coins can be traded by several accounts
+func (c *StrategyConfig) Key() string {
+	if c.Name != "" {
+		return c.Strategy + ":" + c.Name
+	}
+	key := c.Strategy + ":" + strings.Join(c.Coins, "-")
+	if account := c.Account.Name(); account != "" {
+		key += "@" + account
+	}
+	if c.TradingStrategy != 0 {
+		key += fmt.Sprintf("/%d", c.TradingStrategy)
+	}
+	return key
+}
+
+// loadStrategyConfigs reads instances of the single coin strategy from the list of config.yml, e.g. strategy.grid.strategies,
+// all fields of an item are params of the instance
+func loadStrategyConfigs(strategy string, viperKey string) []*StrategyConfig {
+	items := cast.ToSlice(viper.Get(viperKey))
+	configs := make([]*StrategyConfig, 0, len(items))
+
+	for _, item := range items {
+		params := lowerKeys(cast.ToStringMap(item))
+		configs = append(configs, &StrategyConfig{
+			Strategy:        strategy,
+			Name:            cast.ToString(params["name"]),
+			TradingStrategy: constants.TradingStrategy(cast.ToInt8(params["tradingstrategy"])),
+			Coins:           []string{cast.ToString(params["coin"])},
+			Interval:        cast.ToInt(params["interval"]),
+			Account: StrategyAccount{
+				ApiKeyEnv:    cast.ToString(params["apikeyenv"]),
+				ApiSecretEnv: cast.ToString(params["apisecretenv"]),
+			},
+			Params: params,
+		})
+	}
+
+	return configs
+}
+
+// lowerKeys lowercases keys of a list item of config.yml, viper lowercases keys of maps, but not of maps inside lists
+func lowerKeys(values map[string]interface{}) map[string]interface{} {
+	lowered := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		lowered[strings.ToLower(key)] = value
+	}
+	return lowered
+}
+
+// trackParams returns copy of the config recording params read from it
+func (c *StrategyConfig) trackParams() *StrategyConfig {
+	tracked := *c
+	tracked.usedParams = make(map[string]bool, len(c.Params))
+	return &tracked
+}
+
+// unknownParams returns sorted params nobody read from the tracked config, e.g. misspelled in config.yml
+func (c *StrategyConfig) unknownParams() []string {
+	unknown := make([]string, 0)
+	for key := range c.Params {
+		if !c.usedParams[key] && !loaderParams[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func (c *StrategyConfig) GetTradingStrategy(defaultValue constants.TradingStrategy) constants.TradingStrategy {
+	if c.TradingStrategy == 0 {
+		return defaultValue
+	}
+	return c.TradingStrategy
+}
+
+func (c *StrategyConfig) GetInterval(defaultValue int) int {
+	if c.Interval == 0 {
+		return defaultValue
+	}
+	return c.Interval
+}
+
+// IsIntervalStart checks a kline of the interval starts at the moment, intervals are counted from midnight,
+// instance without interval starts at every moment
+func (c *StrategyConfig) IsIntervalStart(moment time.Time) bool {
+	if c.Interval <= 0 {
+		return true
+	}
+	return (moment.Hour()*60+moment.Minute())%c.Interval == 0
+}
+
+func (c *StrategyConfig) GetInt(key string, defaultValue int) int {
+	if value, ok := c.param(key); ok {
+		return cast.ToInt(value)
+	}
+	return defaultValue
+}
+
+func (c *StrategyConfig) GetFloat64(key string, defaultValue float64) float64 {
+	if value, ok := c.param(key); ok {
+		return cast.ToFloat64(value)
+	}
+	return defaultValue
+}
+
+func (c *StrategyConfig) GetString(key string, defaultValue string) string {
+	if value, ok := c.param(key); ok {
+		return cast.ToString(value)
+	}
+	return defaultValue
+}
+
+func (c *StrategyConfig) GetBool(key string, defaultValue bool) bool {
+	if value, ok := c.param(key); ok {
+		return cast.ToBool(value)
+	}
+	return defaultValue
+}
+
+func (c *StrategyConfig) GetStringSlice(key string) []string {
+	if value, ok := c.param(key); ok {
+		return cast.ToStringSlice(value)
+	}
+	return nil
+}
+
+// param looks the key up ignoring case, viper lowercases keys of maps read from config.yml
+func (c *StrategyConfig) param(key string) (interface{}, bool) {
+	if value, ok := c.Params[key]; ok {
+		c.markUsed(key)
+		return value, true
+	}
+	for paramKey, value := range c.Params {
+		if strings.EqualFold(paramKey, key) {
+			c.markUsed(paramKey)
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func (c *StrategyConfig) markUsed(key string) {
+	if c.usedParams != nil {
+		c.usedParams[key] = true
+	}
+}
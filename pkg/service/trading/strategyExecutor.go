@@ -0,0 +1,177 @@
+package trading
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ExecutionResult describes one run of one strategy instance
+type ExecutionResult struct {
+	Key      string
+	Duration time.Duration
+	Skipped  bool
+	Err      error
+}
+
+func NewStrategyExecutor(instances func() []*StrategyInstance, workers int, unitTimeout time.Duration) *StrategyExecutor {
+	if workers < 1 {
+		workers = 1
+	}
+	return &StrategyExecutor{
+		instances:   instances,
+		workers:     workers,
+		unitTimeout: unitTimeout,
+		running:     make(map[string]bool),
+	}
+}
+
+// StrategyExecutor runs strategy instances (coins or pairs) in parallel with bounded worker pool.
+// Every instance has its own services, so one failing or slow instance doesn't affect others:
+// panic is recovered and reported as error, instance exceeded unitTimeout is reported and skipped
+// on next runs until its previous run is finished.
+type StrategyExecutor struct {
+	instances   func() []*StrategyInstance
+	workers     int
+	unitTimeout time.Duration
+
+	mutex   sync.Mutex
+	running map[string]bool
+}
+
+func (e *StrategyExecutor) BeforeExecute() []ExecutionResult {
+	return e.Run("BeforeExecute", func(service TradingService) {
+		service.BeforeExecute()
+	})
+}
+
+func (e *StrategyExecutor) Execute() []ExecutionResult {
+	return e.Run("Execute", func(service TradingService) {
+		service.Execute()
+	})
+}
+
+// BeforeExecuteAt runs only instances whose kline interval starts at the moment
+func (e *StrategyExecutor) BeforeExecuteAt(moment time.Time) []ExecutionResult {
+	return e.run(e.dueInstances(moment), "BeforeExecute", func(service TradingService) {
+		service.BeforeExecute()
+	})
+}
+
+// ExecuteAt runs only instances whose kline interval starts at the moment
+func (e *StrategyExecutor) ExecuteAt(moment time.Time) []ExecutionResult {
+	return e.run(e.dueInstances(moment), "Execute", func(service TradingService) {
+		service.Execute()
+	})
+}
+
+// Run calls action for every instance and waits until all instances are finished or timed out
+func (e *StrategyExecutor) Run(actionName string, action func(service TradingService)) []ExecutionResult {
+	return e.run(e.instances(), actionName, action)
+}
+
+// dueInstances returns instances with interval starting at the moment, instances without interval are always due
+func (e *StrategyExecutor) dueInstances(moment time.Time) []*StrategyInstance {
+	instances := make([]*StrategyInstance, 0)
+	for _, instance := range e.instances() {
+		if instance.Config.IsIntervalStart(moment) {
+			instances = append(instances, instance)
+		}
+	}
+	return instances
+}
+
+func (e *StrategyExecutor) run(instances []*StrategyInstance, actionName string, action func(service TradingService)) []ExecutionResult {
+	results := make([]ExecutionResult, len(instances))
+
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < e.workers && i < len(instances); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results[index] = e.runUnit(instances[index], actionName, action)
+			}
+		}()
+	}
+
+	for i := range instances {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, result := range results {
+		if result.Err != nil {
+			zap.S().Errorf("%s %s failed in %s: %s", actionName, result.Key, result.Duration, result.Err.Error())
+		} else if result.Skipped {
+			zap.S().Warnf("%s %s skipped, previous run is not finished", actionName, result.Key)
+		}
+	}
+
+	return results
+}
+
+func (e *StrategyExecutor) runUnit(instance *StrategyInstance, actionName string, action func(service TradingService)) ExecutionResult {
+	key := instance.Config.Key()
+	result := ExecutionResult{Key: key}
+
+	if !e.acquire(key) {
+		result.Skipped = true
+		return result
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer e.release(key)
+		defer func() {
+			if r := recover(); r != nil {
+				zap.S().Errorf("Panic during %s %s: %v\n%s", actionName, key, r, debug.Stack())
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+
+		action(instance.Service)
+		done <- nil
+	}()
+
+	if e.unitTimeout <= 0 {
+		result.Err = <-done
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	timer := time.NewTimer(e.unitTimeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		result.Err = err
+	case <-timer.C:
+		result.Err = fmt.Errorf("timeout %s exceeded", e.unitTimeout)
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+func (e *StrategyExecutor) acquire(key string) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.running[key] {
+		return false
+	}
+	e.running[key] = true
+	return true
+}
+
+func (e *StrategyExecutor) release(key string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	delete(e.running, key)
+}
@@ -0,0 +1,657 @@
+package trading
+
+import (
+	"cryptoBot/pkg/api"
+	"cryptoBot/pkg/constants"
+	"cryptoBot/pkg/constants/futureType"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/service/exchange"
+	"cryptoBot/pkg/service/indicator"
+	"cryptoBot/pkg/service/indicator/techanLib"
+	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/portfolio"
+	"cryptoBot/pkg/service/signal"
+	"fmt"
+	"github.com/spf13/viper"
+	"strconv"
+)
+
+const (
+	PAIR_ARBITRAGE_STRATEGY     = "pairArbitrage"
+	SMA_VOLUME_SCALPER_STRATEGY = "smaVolumeScalper"
+	SESSIONS_SCALPER_STRATEGY   = "sessionsScalper"
+	TREND_METER_STRATEGY        = "trendMeter"
+	RULE_BASED_STRATEGY         = "ruleBased"
+	GRID_STRATEGY               = "grid"
+	FUNDING_BASIS_STRATEGY      = "fundingBasis"
+	MEAN_REVERSION_STRATEGY     = "meanReversion"
+	BREAKOUT_STRATEGY           = "breakout"
+)
+
+func registerDefaultStrategies(registry *StrategyRegistry) {
+	registry.Register(PAIR_ARBITRAGE_STRATEGY, newPairArbitrageStrategy)
+	registry.Register(SMA_VOLUME_SCALPER_STRATEGY, newSmaVolumeScalperStrategy)
+	registry.Register(SESSIONS_SCALPER_STRATEGY, newSessionsScalperStrategy)
+	registry.Register(TREND_METER_STRATEGY, newTrendMeterStrategy)
+	registry.Register(RULE_BASED_STRATEGY, newRuleBasedStrategy)
+	registry.Register(GRID_STRATEGY, newGridStrategy)
+	registry.Register(FUNDING_BASIS_STRATEGY, newFundingBasisStrategy)
+	registry.Register(MEAN_REVERSION_STRATEGY, newMeanReversionStrategy)
+	registry.Register(BREAKOUT_STRATEGY, newBreakoutStrategy)
+}
+
+// defaultTradingStrategies are saved to transactions of the registered strategies when config doesn't set TradingStrategy
+var defaultTradingStrategies = map[string]constants.TradingStrategy{
+	PAIR_ARBITRAGE_STRATEGY:     constants.PAIR_ARBITRAGE,
+	SMA_VOLUME_SCALPER_STRATEGY: constants.SMA_VOLUME_SCALPER,
+	SESSIONS_SCALPER_STRATEGY:   constants.SESSION_SCALPER,
+	TREND_METER_STRATEGY:        constants.TREND_METER,
+	RULE_BASED_STRATEGY:         constants.RULE_BASED,
+	GRID_STRATEGY:               constants.GRID,
+	FUNDING_BASIS_STRATEGY:      constants.FUNDING_BASIS,
+	MEAN_REVERSION_STRATEGY:     constants.MEAN_REVERSION,
+	BREAKOUT_STRATEGY:           constants.BREAKOUT,
+}
+
+// ResolveTradingStrategy returns value saved to transaction_table.trading_strategy by the instance built from the config
+func ResolveTradingStrategy(config *StrategyConfig) constants.TradingStrategy {
+	return config.GetTradingStrategy(defaultTradingStrategies[config.Strategy])
+}
+
+// strategyServices are created for every strategy instance, so instances never share exchange api or trading strategy id
+type strategyServices struct {
+	ExchangeApi                api.ExchangeApi
+	TechanConvertorService     *techanLib.TechanConvertorService
+	ExchangeDataService        *exchange.DataService
+	KlinesFetcherService       *exchange.KlinesFetcherService
+	PriceChangeTrackingService *orders.PriceChangeTrackingService
+	ProfitLossFinderService    *orders.ProfitLossFinderService
+}
+
+func (d *StrategyDependencies) newStrategyServices(config *StrategyConfig) *strategyServices {
+	exchangeApi := d.ExchangeApiProvider(config.Account)
+
+	return &strategyServices{
+		ExchangeApi:                exchangeApi,
+		TechanConvertorService:     techanLib.NewTechanConvertorService(d.Clock, d.Repos.Kline),
+		ExchangeDataService:        exchange.NewExchangeDataService(d.Repos.Transaction, d.Repos.Coin, exchangeApi, d.Clock, d.Repos.Kline),
+		KlinesFetcherService:       exchange.NewKlinesFetcherService(exchangeApi, d.Repos.Kline, d.Clock),
+		PriceChangeTrackingService: orders.NewPriceChangeTrackingService(d.Repos.PriceChange),
+		ProfitLossFinderService:    orders.NewProfitLossFinderService(d.Clock, d.Repos.Kline),
+	}
+}
+
+// newOrderManagerService registers the instance in the capital allocator by weight param, so its positions are limited by its share
+// of equity of its account. Transactions of the scope are the instance's own ones, they are reserved and scored by the allocator
+func (d *StrategyDependencies) newOrderManagerService(config *StrategyConfig, services *strategyServices, scope portfolio.TransactionScope, leverage int64) *orders.OrderManagerService {
+	orderManagerService := orders.NewOrderManagerService(d.Repos.Transaction, services.ExchangeApi, d.Clock, services.ExchangeDataService, d.Repos.Kline,
+		scope.TradingStrategy, services.PriceChangeTrackingService, services.ProfitLossFinderService, leverage, 0, 0, 0, 0)
+
+	weight := config.GetFloat64("weight", 1)
+	if d.CapitalAllocator != nil {
+		d.CapitalAllocator.Register(config.Key(), config.Account.Name(), services.ExchangeApi, scope, weight, config.GetInt("leverage", int(leverage)))
+		orderManagerService.SetCapitalAllocator(d.CapitalAllocator, config.Key())
+	}
+	orderManagerService.SetEquityCurveFilter(d.newEquityCurveFilter(config, scope, 0))
+	return orderManagerService
+}
+
+// newEquityCurveFilter reads equityFilterMaLength and equityFilterMaxDrawdownPercent, nil is returned when both are disabled
+func (d *StrategyDependencies) newEquityCurveFilter(config *StrategyConfig, scope portfolio.TransactionScope, defaultMaLength int) *orders.EquityCurveFilterService {
+	maLength := config.GetInt("equityFilterMaLength", defaultMaLength)
+	maxDrawdownPercent := config.GetFloat64("equityFilterMaxDrawdownPercent", 0)
+	if maLength <= 0 && maxDrawdownPercent <= 0 {
+		return nil
+	}
+	equityCurveFilter := orders.NewEquityCurveFilterService(d.Repos.Transaction, d.Clock, maLength, maxDrawdownPercent)
+	equityCurveFilter.SetScope(scope)
+	return equityCurveFilter
+}
+
+// newSignalSink routes signals by signalMode of the strategy or by signals.mode, orders are placed by default
+func (d *StrategyDependencies) newSignalSink(config *StrategyConfig, orderManagerService *orders.OrderManagerService, tradingStrategy constants.TradingStrategy) (signal.Sink, error) {
+	mode := constants.SignalMode(config.GetString("signalMode", viper.GetString("signals.mode")))
+	sink, err := signal.NewSink(mode, d.Repos.Transaction, d.Repos.Signal, d.Clock, orderManagerService, tradingStrategy, config.Key())
+	if err != nil {
+		return nil, fmt.Errorf("Strategy [%s]: %s", config.Key(), err.Error())
+	}
+	return sink, nil
+}
+
+// newSessionsService builds trading calendar of the strategy, sessions param restricts it to the named sessions of sessions.calendar.
+// Without the param the strategy trades in defaultSessions or at any time when they're empty, blackouts are always applied
+func (d *StrategyDependencies) newSessionsService(config *StrategyConfig, defaultSessions []string) (*indicator.SessionsService, error) {
+	sessionsService, err := indicator.NewSessionsServiceFromConfig(d.Clock, config.GetStringSlice("sessions"))
+	if err == nil && len(config.GetStringSlice("sessions")) == 0 {
+		sessionsService, err = sessionsService.WithSessions(defaultSessions)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Strategy [%s]: %s", config.Key(), err.Error())
+	}
+	return sessionsService, nil
+}
+
+func (d *StrategyDependencies) findCoins(config *StrategyConfig, expectedSize int) ([]*domains.Coin, error) {
+	if len(config.Coins) != expectedSize {
+		return nil, fmt.Errorf("Strategy [%s] expects %d coins, got %v", config.Strategy, expectedSize, config.Coins)
+	}
+
+	coins := make([]*domains.Coin, 0, len(config.Coins))
+	for _, symbol := range config.Coins {
+		coin, err := d.Repos.Coin.FindBySymbol(symbol)
+		if err != nil {
+			return nil, err
+		}
+		if coin == nil {
+			return nil, fmt.Errorf("Coin [%s] not found", symbol)
+		}
+		coins = append(coins, coin)
+	}
+	return coins, nil
+}
+
+func newPairArbitrageStrategy(deps *StrategyDependencies, config *StrategyConfig) (TradingService, error) {
+	coins, err := deps.findCoins(config, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	hedgeRatioMethod, err := indicator.ParseHedgeRatioMethod(config.GetString("hedgeRatio", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	services := deps.newStrategyServices(config)
+	tradingStrategy := config.GetTradingStrategy(constants.PAIR_ARBITRAGE)
+	tradingKey := pairTradingKey(config, coins)
+	orderManagerService := deps.newOrderManagerService(config, services, portfolio.NewTransactionScope(tradingStrategy, coins, tradingKey), 0)
+	signalSink, err := deps.newSignalSink(config, orderManagerService, tradingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	service := NewPairArbitrageStrategyTradingService(
+		deps.Repos.Coin,
+		deps.Repos.Transaction,
+		deps.Clock,
+		services.ExchangeDataService,
+		deps.Repos.SyntheticKline,
+		services.KlinesFetcherService,
+		orderManagerService,
+		signalSink,
+		services.TechanConvertorService,
+		indicator.NewHedgeRatioService(config.GetFloat64("kalmanDelta", 0.0001), config.GetFloat64("kalmanObservationVariance", 0.001)),
+		indicator.NewCointegrationService(config.GetInt("adfLags", 1)),
+		coins[0],
+		coins[1],
+	)
+	service.tradingStrategy = tradingStrategy
+	service.tradingKey = tradingKey
+	service.klineInterval = config.GetInterval(service.klineInterval)
+	service.klineIntervalS = strconv.Itoa(service.klineInterval)
+	service.startCapitalInCents = config.GetInt("startCapitalInCents", service.startCapitalInCents)
+	service.strategyLength = config.GetInt("strategyLength", service.strategyLength)
+	service.leverage = config.GetInt("leverage", service.leverage)
+	service.stopLossPercent = config.GetFloat64("stopLossPercent", service.stopLossPercent)
+	service.closeOnProfit = config.GetFloat64("closeOnProfit", service.closeOnProfit)
+	service.maxOrderLoss = config.GetFloat64("maxOrderLoss", service.maxOrderLoss)
+	service.entryZScore = config.GetFloat64("entryZScore", service.entryZScore)
+	service.zScoreCloseToZero = config.GetFloat64("zScoreCloseToZero", service.zScoreCloseToZero)
+	service.zScoreMinProfit = config.GetFloat64("zScoreMinProfit", service.zScoreMinProfit)
+	service.hedgeRatioMethod = hedgeRatioMethod
+	service.hedgeRatioLength = config.GetInt("hedgeRatioLength", service.hedgeRatioLength)
+	service.zScoreStopLoss = config.GetFloat64("zScoreStopLoss", service.zScoreStopLoss)
+	service.halfLifeMultiplier = config.GetFloat64("halfLifeMultiplier", service.halfLifeMultiplier)
+	service.maxHoldingKlines = config.GetInt("maxHoldingKlines", service.maxHoldingKlines)
+	service.regimeLength = config.GetInt("regimeLength", service.regimeLength)
+	service.regimeMinCorrelation = config.GetFloat64("regimeMinCorrelation", service.regimeMinCorrelation)
+	service.regimeSignificance = config.GetFloat64("regimeSignificance", service.regimeSignificance)
+	service.enabled = config.GetBool("enabled", service.enabled)
+
+	return service, nil
+}
+
+// pairTradingKey keeps transactions of the same pair traded by several accounts or named instances apart,
+// e.g. PairTrading1:BTCUSDT-ETHUSDT, the account goes first so keys of accounts aren't prefixes of each other
+func pairTradingKey(config *StrategyConfig, coins []*domains.Coin) string {
+	tradingKey := coins[0].Symbol + "-" + coins[1].Symbol
+	if config.Name != "" {
+		tradingKey = config.Name + ":" + tradingKey
+	}
+	if account := config.Account.Name(); account != "" {
+		tradingKey = account + ":" + tradingKey
+	}
+	return tradingKey
+}
+
+func newSmaVolumeScalperStrategy(deps *StrategyDependencies, config *StrategyConfig) (TradingService, error) {
+	coins, err := deps.findCoins(config, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	services := deps.newStrategyServices(config)
+	tradingStrategy := config.GetTradingStrategy(constants.SMA_VOLUME_SCALPER)
+	leverage := config.GetInt("leverage", viper.GetInt("strategy.smaVolumeScalper.futures.leverage"))
+	scope := portfolio.NewTransactionScope(tradingStrategy, coins, "")
+	orderManagerService := deps.newOrderManagerService(config, services, scope, int64(leverage))
+	// the order after a losing trade is fake, the losing trade puts the shadow equity below its SMA(2)
+	orderManagerService.SetEquityCurveFilter(deps.newEquityCurveFilter(config, scope, 2))
+	signalSink, err := deps.newSignalSink(config, orderManagerService, tradingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	service := NewSmaVolumeScalperStrategyTradingService(
+		deps.Repos.Transaction,
+		deps.Clock,
+		services.ExchangeDataService,
+		deps.Repos.Kline,
+		services.KlinesFetcherService,
+		orderManagerService,
+		signalSink,
+		services.TechanConvertorService,
+		indicator.NewStochasticService(deps.Clock, deps.Repos.Kline, services.TechanConvertorService),
+		indicator.NewSmaTubeService(deps.Clock, deps.Repos.Kline),
+		indicator.NewLocalExtremumTrendService(deps.Clock, deps.Repos.Kline),
+		indicator.NewRelativeVolumeIndicatorService(),
+		config.GetInterval(60),
+	)
+	service.coin = coins[0]
+	service.tradingStrategy = tradingStrategy
+	service.leverage = leverage
+	service.takeProfitRatio = config.GetFloat64("takeProfitRatio", service.takeProfitRatio)
+	service.costOfOrderInCents = config.GetInt("costOfOrderInCents", service.costOfOrderInCents)
+	service.sma21Length = config.GetInt("sma21Length", service.sma21Length)
+	service.sma50Length = config.GetInt("sma50Length", service.sma50Length)
+	service.sma100Length = config.GetInt("sma100Length", service.sma100Length)
+	service.sma200Length = config.GetInt("sma200Length", service.sma200Length)
+	service.StrategyStateService = deps.StrategyStateService
+	service.stateKey = config.Key()
+
+	return service, nil
+}
+
+func newSessionsScalperStrategy(deps *StrategyDependencies, config *StrategyConfig) (TradingService, error) {
+	coins, err := deps.findCoins(config, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	services := deps.newStrategyServices(config)
+	tradingStrategy := config.GetTradingStrategy(constants.SESSION_SCALPER)
+	leverage := config.GetInt("leverage", viper.GetInt("strategy.sessionsScalper.futures.leverage"))
+	sessionsService, err := deps.newSessionsService(config, []string{"london", "newYork"})
+	if err != nil {
+		return nil, err
+	}
+	orderManagerService := deps.newOrderManagerService(config, services, portfolio.NewTransactionScope(tradingStrategy, coins, ""), int64(leverage))
+	signalSink, err := deps.newSignalSink(config, orderManagerService, tradingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	service := NewSessionsScalperStrategyTradingService(
+		deps.Repos.Transaction,
+		deps.Clock,
+		services.ExchangeDataService,
+		deps.Repos.Kline,
+		services.KlinesFetcherService,
+		orderManagerService,
+		signalSink,
+		services.TechanConvertorService,
+		indicator.NewStochasticService(deps.Clock, deps.Repos.Kline, services.TechanConvertorService),
+		indicator.NewSmaTubeService(deps.Clock, deps.Repos.Kline),
+		indicator.NewLocalExtremumTrendService(deps.Clock, deps.Repos.Kline),
+		sessionsService,
+		config.GetInterval(5),
+	)
+	service.coin = coins[0]
+	service.tradingStrategy = tradingStrategy
+	service.leverage = leverage
+	service.fastSmaLength = config.GetInt("fastSmaLength", service.fastSmaLength)
+	service.slowSmaLength = config.GetInt("slowSmaLength", service.slowSmaLength)
+	service.takeProfitRatio = config.GetFloat64("takeProfitRatio", service.takeProfitRatio)
+	service.costOfOrderInCents = config.GetInt("costOfOrderInCents", service.costOfOrderInCents)
+	service.StrategyStateService = deps.StrategyStateService
+	service.stateKey = config.Key()
+
+	return service, nil
+}
+
+func newTrendMeterStrategy(deps *StrategyDependencies, config *StrategyConfig) (TradingService, error) {
+	coins, err := deps.findCoins(config, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	services := deps.newStrategyServices(config)
+	tradingStrategy := config.GetTradingStrategy(constants.TREND_METER)
+	tradingType := constants.SPOT
+	if config.GetBool("futures", false) {
+		tradingType = constants.FUTURES
+	}
+	orderManagerService := deps.newOrderManagerService(config, services, portfolio.NewTransactionScope(tradingStrategy, coins, ""), viper.GetInt64("strategy.trendMeter.futures.leverage"))
+	signalSink, err := deps.newSignalSink(config, orderManagerService, tradingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	service := NewTrendMeterStrategyTradingService(
+		deps.Repos.Transaction,
+		deps.Clock,
+		services.ExchangeDataService,
+		deps.Repos.Kline,
+		indicator.NewStandardDeviationService(deps.Clock, deps.Repos.Kline, services.TechanConvertorService),
+		services.KlinesFetcherService,
+		indicator.NewMACDService(services.TechanConvertorService),
+		indicator.NewRelativeStrengthIndexService(services.TechanConvertorService),
+		indicator.NewExponentialMovingAverageService(services.TechanConvertorService),
+		orderManagerService,
+		signalSink,
+		services.PriceChangeTrackingService,
+		tradingType,
+	)
+	service.coin = coins[0]
+	service.tradingStrategy = tradingStrategy
+
+	return service, nil
+}
+
+func newRuleBasedStrategy(deps *StrategyDependencies, config *StrategyConfig) (TradingService, error) {
+	coins, err := deps.findCoins(config, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := NewRuleBasedStrategyRules(
+		config.GetString("entryLong", ""),
+		config.GetString("entryShort", ""),
+		config.GetString("exitLong", ""),
+		config.GetString("exitShort", ""),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Strategy [%s]: %s", config.Key(), err.Error())
+	}
+	if rules.EntryLong == nil && rules.EntryShort == nil {
+		return nil, fmt.Errorf("Strategy [%s] has neither entryLong nor entryShort rule", config.Key())
+	}
+
+	services := deps.newStrategyServices(config)
+	tradingStrategy := config.GetTradingStrategy(constants.RULE_BASED)
+	leverage := config.GetInt("leverage", 1)
+	orderManagerService := deps.newOrderManagerService(config, services, portfolio.NewTransactionScope(tradingStrategy, coins, ""), int64(leverage))
+	signalSink, err := deps.newSignalSink(config, orderManagerService, tradingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	service := NewRuleBasedStrategyTradingService(
+		deps.Repos.Transaction,
+		deps.Clock,
+		services.ExchangeDataService,
+		services.KlinesFetcherService,
+		orderManagerService,
+		signalSink,
+		services.TechanConvertorService,
+		coins[0],
+		rules,
+		config.GetInterval(60),
+	)
+	service.tradingStrategy = tradingStrategy
+	service.leverage = leverage
+	service.historySize = config.GetInt("historySize", service.historySize)
+	service.stopLossPercent = config.GetFloat64("stopLossPercent", service.stopLossPercent)
+	service.takeProfitPercent = config.GetFloat64("takeProfitPercent", service.takeProfitPercent)
+	service.costOfOrderInCents = config.GetInt("costOfOrderInCents", service.costOfOrderInCents)
+	service.SessionsService, err = deps.newSessionsService(config, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func newGridStrategy(deps *StrategyDependencies, config *StrategyConfig) (TradingService, error) {
+	coins, err := deps.findCoins(config, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := NewGridPrices(
+		config.GetFloat64("lowerPrice", 0),
+		config.GetFloat64("upperPrice", 0),
+		config.GetInt("levels", 0),
+		GridSpacing(config.GetString("spacing", string(GRID_SPACING_ARITHMETIC))),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Strategy [%s]: %s", config.Key(), err.Error())
+	}
+
+	quantity := config.GetFloat64("quantity", 0)
+	if quantity <= 0 {
+		return nil, fmt.Errorf("Strategy [%s] expects positive quantity per level", config.Key())
+	}
+
+	tradingType := constants.FUTURES
+	switch config.GetString("tradingType", "futures") {
+	case "futures":
+	case "spot":
+		tradingType = constants.SPOT
+	default:
+		return nil, fmt.Errorf("Strategy [%s] expects tradingType futures or spot", config.Key())
+	}
+
+	futuresType := futureType.LONG
+	switch config.GetString("direction", "long") {
+	case "long":
+	case "short":
+		futuresType = futureType.SHORT
+	default:
+		return nil, fmt.Errorf("Strategy [%s] expects direction long or short", config.Key())
+	}
+	if tradingType == constants.SPOT && futuresType == futureType.SHORT {
+		return nil, fmt.Errorf("Strategy [%s]: short grid is supported only for futures", config.Key())
+	}
+
+	services := deps.newStrategyServices(config)
+	tradingStrategy := config.GetTradingStrategy(constants.GRID)
+	leverage := config.GetInt("leverage", 1)
+	orderManagerService := deps.newOrderManagerService(config, services, portfolio.NewTransactionScope(tradingStrategy, coins, config.Key()+"#"), int64(leverage))
+	signalSink, err := deps.newSignalSink(config, orderManagerService, tradingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	service := NewGridStrategyTradingService(
+		deps.Repos.Transaction,
+		deps.Repos.GridLevel,
+		deps.Clock,
+		services.ExchangeDataService,
+		services.KlinesFetcherService,
+		orderManagerService,
+		signalSink,
+		coins[0],
+		config.Key(),
+		prices,
+		quantity,
+		config.GetInterval(60),
+	)
+	service.tradingStrategy = tradingStrategy
+	service.tradingType = tradingType
+	service.futuresType = futuresType
+	service.leverage = leverage
+	service.stopOutPercent = config.GetFloat64("stopOutPercent", service.stopOutPercent)
+
+	return service, nil
+}
+
+func newFundingBasisStrategy(deps *StrategyDependencies, config *StrategyConfig) (TradingService, error) {
+	coins, err := deps.findCoins(config, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	services := deps.newStrategyServices(config)
+	tradingStrategy := config.GetTradingStrategy(constants.FUNDING_BASIS)
+	leverage := config.GetInt("leverage", 1)
+	orderManagerService := deps.newOrderManagerService(config, services, portfolio.NewTransactionScope(tradingStrategy, coins, config.Key()+":"), int64(leverage))
+	signalSink, err := deps.newSignalSink(config, orderManagerService, tradingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	service := NewFundingBasisStrategyTradingService(
+		deps.Repos.Transaction,
+		deps.Repos.FundingRate,
+		deps.Clock,
+		services.ExchangeDataService,
+		services.KlinesFetcherService,
+		exchange.NewFundingRateFetcherService(services.ExchangeApi, deps.Repos.FundingRate, deps.Clock),
+		orderManagerService,
+		signalSink,
+		coins[0],
+		config.Key(),
+		config.GetInterval(60),
+	)
+	service.tradingStrategy = tradingStrategy
+	service.leverage = leverage
+	service.entryFundingRate = config.GetFloat64("entryFundingRate", service.entryFundingRate)
+	service.exitFundingRate = config.GetFloat64("exitFundingRate", service.exitFundingRate)
+	service.entryBasisPercent = config.GetFloat64("entryBasisPercent", service.entryBasisPercent)
+	service.costOfOrderInCents = config.GetInt("costOfOrderInCents", service.costOfOrderInCents)
+	if service.entryFundingRate <= service.exitFundingRate {
+		return nil, fmt.Errorf("Strategy [%s] expects entryFundingRate > exitFundingRate", config.Key())
+	}
+
+	return service, nil
+}
+
+func newMeanReversionStrategy(deps *StrategyDependencies, config *StrategyConfig) (TradingService, error) {
+	coins, err := deps.findCoins(config, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	bands := MeanReversionBands(config.GetString("bands", string(MEAN_REVERSION_BANDS_BOLLINGER)))
+	if bands != MEAN_REVERSION_BANDS_BOLLINGER && bands != MEAN_REVERSION_BANDS_KELTNER {
+		return nil, fmt.Errorf("Strategy [%s] expects bands bollinger or keltner", config.Key())
+	}
+	allowLong, allowShort, err := parseDirection(config)
+	if err != nil {
+		return nil, err
+	}
+
+	services := deps.newStrategyServices(config)
+	tradingStrategy := config.GetTradingStrategy(constants.MEAN_REVERSION)
+	leverage := config.GetInt("leverage", 1)
+	orderManagerService := deps.newOrderManagerService(config, services, portfolio.NewTransactionScope(tradingStrategy, coins, ""), int64(leverage))
+	signalSink, err := deps.newSignalSink(config, orderManagerService, tradingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	service := NewMeanReversionStrategyTradingService(
+		deps.Repos.Transaction,
+		deps.Clock,
+		services.ExchangeDataService,
+		services.KlinesFetcherService,
+		orderManagerService,
+		signalSink,
+		services.TechanConvertorService,
+		coins[0],
+		bands,
+		config.GetInterval(60),
+	)
+	service.tradingStrategy = tradingStrategy
+	service.leverage = leverage
+	service.allowLong = allowLong
+	service.allowShort = allowShort
+	service.length = config.GetInt("length", service.length)
+	service.deviation = config.GetFloat64("deviation", service.deviation)
+	service.rsiLength = config.GetInt("rsiLength", service.rsiLength)
+	service.rsiOversold = config.GetFloat64("rsiOversold", service.rsiOversold)
+	service.rsiOverbought = config.GetFloat64("rsiOverbought", service.rsiOverbought)
+	service.stopLossPercent = config.GetFloat64("stopLossPercent", service.stopLossPercent)
+	service.costOfOrderInCents = config.GetInt("costOfOrderInCents", service.costOfOrderInCents)
+	service.SessionsService, err = deps.newSessionsService(config, nil)
+	if err != nil {
+		return nil, err
+	}
+	if service.length < 2 || service.rsiLength < 2 || service.deviation <= 0 {
+		return nil, fmt.Errorf("Strategy [%s] expects length and rsiLength >= 2 and positive deviation", config.Key())
+	}
+
+	return service, nil
+}
+
+func newBreakoutStrategy(deps *StrategyDependencies, config *StrategyConfig) (TradingService, error) {
+	coins, err := deps.findCoins(config, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	allowLong, allowShort, err := parseDirection(config)
+	if err != nil {
+		return nil, err
+	}
+
+	services := deps.newStrategyServices(config)
+	tradingStrategy := config.GetTradingStrategy(constants.BREAKOUT)
+	leverage := config.GetInt("leverage", 1)
+	orderManagerService := deps.newOrderManagerService(config, services, portfolio.NewTransactionScope(tradingStrategy, coins, config.Key()+"#"), int64(leverage))
+	signalSink, err := deps.newSignalSink(config, orderManagerService, tradingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	service := NewBreakoutStrategyTradingService(
+		deps.Repos.Transaction,
+		deps.Clock,
+		services.ExchangeDataService,
+		services.KlinesFetcherService,
+		orderManagerService,
+		signalSink,
+		services.TechanConvertorService,
+		coins[0],
+		config.Key(),
+		config.GetInterval(60),
+	)
+	service.tradingStrategy = tradingStrategy
+	service.leverage = leverage
+	service.allowLong = allowLong
+	service.allowShort = allowShort
+	service.entryLength = config.GetInt("entryLength", service.entryLength)
+	service.exitLength = config.GetInt("exitLength", service.exitLength)
+	service.atrLength = config.GetInt("atrLength", service.atrLength)
+	service.stopAtrMultiplier = config.GetFloat64("stopAtrMultiplier", service.stopAtrMultiplier)
+	service.pyramidAtrStep = config.GetFloat64("pyramidAtrStep", service.pyramidAtrStep)
+	service.maxUnits = config.GetInt("maxUnits", service.maxUnits)
+	service.costOfOrderInCents = config.GetInt("costOfOrderInCents", service.costOfOrderInCents)
+	service.riskPerUnitInCents = config.GetInt("riskPerUnitInCents", service.riskPerUnitInCents)
+	service.SessionsService, err = deps.newSessionsService(config, nil)
+	if err != nil {
+		return nil, err
+	}
+	if service.entryLength < 2 || service.exitLength < 2 || service.atrLength < 2 {
+		return nil, fmt.Errorf("Strategy [%s] expects entryLength, exitLength and atrLength >= 2", config.Key())
+	}
+	if service.stopAtrMultiplier <= 0 || service.pyramidAtrStep <= 0 || service.maxUnits < 1 {
+		return nil, fmt.Errorf("Strategy [%s] expects positive stopAtrMultiplier, pyramidAtrStep and maxUnits", config.Key())
+	}
+
+	return service, nil
+}
+
+// parseDirection reads direction long, short or both
+func parseDirection(config *StrategyConfig) (allowLong bool, allowShort bool, err error) {
+	switch config.GetString("direction", "both") {
+	case "both":
+		return true, true, nil
+	case "long":
+		return true, false, nil
+	case "short":
+		return false, true, nil
+	}
+	return false, false, fmt.Errorf("Strategy [%s] expects direction long, short or both", config.Key())
+}
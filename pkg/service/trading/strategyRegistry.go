@@ -0,0 +1,220 @@
+package trading
+
+import (
+	"cryptoBot/pkg/api"
+	"cryptoBot/pkg/data/domains"
+	"cryptoBot/pkg/repository"
+	"cryptoBot/pkg/service/date"
+	"cryptoBot/pkg/service/portfolio"
+	"cryptoBot/pkg/service/state"
+	"fmt"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+)
+
+// StrategyFactory builds new independent instance of a strategy by its config
+type StrategyFactory func(deps *StrategyDependencies, config *StrategyConfig) (TradingService, error)
+
+// StrategyDependencies are shared between all strategy instances of the process
+type StrategyDependencies struct {
+	Repos *repository.Repository
+	Clock date.Clock
+
+	/* Returns exchange api for the account of strategy instance, backtests return the same mock for all accounts */
+	ExchangeApiProvider func(account StrategyAccount) api.ExchangeApi
+
+	/* Strategies restore in-memory state by it, backtests leave it nil to start from the empty state */
+	StrategyStateService *state.StrategyStateService
+
+	/* Splits equity across instances when portfolio.enabled, nil lets every instance decide its own capital */
+	CapitalAllocator *portfolio.CapitalAllocatorService
+}
+
+type StrategyInstance struct {
+	Config  *StrategyConfig
+	Service TradingService
+}
+
+func NewStrategyRegistry(deps *StrategyDependencies) *StrategyRegistry {
+	registry := &StrategyRegistry{
+		deps:      deps,
+		factories: make(map[string]StrategyFactory),
+	}
+	registerDefaultStrategies(registry)
+	return registry
+}
+
+// StrategyRegistry hosts several strategies and several instances of the same strategy in one process.
+// Every instance has its own coins, account, trading strategy id and parameters.
+// Instances can be created and removed while the executor runs them.
+type StrategyRegistry struct {
+	deps      *StrategyDependencies
+	factories map[string]StrategyFactory
+
+	mutex     sync.RWMutex
+	instances []*StrategyInstance
+}
+
+func (r *StrategyRegistry) Register(strategy string, factory StrategyFactory) {
+	r.factories[strategy] = factory
+}
+
+func (r *StrategyRegistry) IsRegistered(strategy string) bool {
+	_, ok := r.factories[strategy]
+	return ok
+}
+
+// Build creates new strategy instance without hosting it in the registry, params the factory doesn't read are rejected
+func (r *StrategyRegistry) Build(config *StrategyConfig) (TradingService, error) {
+	factory, ok := r.factories[config.Strategy]
+	if !ok {
+		return nil, fmt.Errorf("Strategy [%s] is not registered", config.Strategy)
+	}
+
+	tracked := config.trackParams()
+	service, err := factory(r.deps, tracked)
+	if err != nil {
+		return nil, err
+	}
+	if unknown := tracked.unknownParams(); len(unknown) > 0 {
+		return nil, fmt.Errorf("Strategy [%s] has unknown params %s", config.Key(), strings.Join(unknown, ", "))
+	}
+	return service, nil
+}
+
+// Create builds new strategy instance and hosts it in the registry
+func (r *StrategyRegistry) Create(config *StrategyConfig) (TradingService, error) {
+	if r.Find(config.Key()) != nil {
+		return nil, fmt.Errorf("Strategy instance [%s] already exists", config.Key())
+	}
+
+	service, err := r.Build(config)
+	if err != nil {
+		// factory could register the instance before the failure
+		if r.deps.CapitalAllocator != nil {
+			r.deps.CapitalAllocator.Unregister(config.Key())
+		}
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.instances = append(r.instances, &StrategyInstance{Config: config, Service: service})
+	return service, nil
+}
+
+// Replace builds and initializes instances of the configs first, then they take the place of hosted instances with the same keys
+// or are added. Nothing is changed when any of them fails, so hosted instances keep trading with their previous configs
+func (r *StrategyRegistry) Replace(configs []*StrategyConfig) error {
+	registrations := make([]*portfolio.Registration, 0, len(configs))
+	if r.deps.CapitalAllocator != nil {
+		for _, config := range configs {
+			registrations = append(registrations, r.deps.CapitalAllocator.FindRegistration(config.Key()))
+		}
+	}
+
+	built := make([]*StrategyInstance, 0, len(configs))
+	for _, config := range configs {
+		service, err := r.Build(config)
+		if err == nil {
+			err = service.Initialize()
+		}
+		if err != nil {
+			// factories register new instances in the capital allocator before the failure
+			for _, registration := range registrations {
+				r.deps.CapitalAllocator.Restore(registration)
+			}
+			return fmt.Errorf("Strategy instance [%s] isn't created: %s", config.Key(), err.Error())
+		}
+		built = append(built, &StrategyInstance{Config: config, Service: service})
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, instance := range built {
+		replaced := false
+		for i := range r.instances {
+			if r.instances[i].Config.Key() == instance.Config.Key() {
+				r.instances[i] = instance
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			r.instances = append(r.instances, instance)
+		}
+	}
+	return nil
+}
+
+func (r *StrategyRegistry) Remove(config *StrategyConfig) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, instance := range r.instances {
+		if instance.Config.Key() == config.Key() {
+			if r.deps.CapitalAllocator != nil {
+				r.deps.CapitalAllocator.Unregister(config.Key())
+			}
+			instances := make([]*StrategyInstance, 0, len(r.instances)-1)
+			instances = append(instances, r.instances[:i]...)
+			r.instances = append(instances, r.instances[i+1:]...)
+			return
+		}
+	}
+}
+
+func (r *StrategyRegistry) Find(key string) *StrategyInstance {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, instance := range r.instances {
+		if instance.Config.Key() == key {
+			return instance
+		}
+	}
+	return nil
+}
+
+// Instances returns snapshot of hosted instances, it isn't changed by following Create and Remove
+func (r *StrategyRegistry) Instances() []*StrategyInstance {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	instances := make([]*StrategyInstance, len(r.instances))
+	copy(instances, r.instances)
+	return instances
+}
+
+func (r *StrategyRegistry) Initialize() error {
+	for _, instance := range r.Instances() {
+		if err := instance.Service.Initialize(); err != nil {
+			zap.S().Errorf("Error during Initialize %s: %s", instance.Config.Key(), err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *StrategyRegistry) BeforeExecute() {
+	for _, instance := range r.Instances() {
+		instance.Service.BeforeExecute()
+	}
+}
+
+func (r *StrategyRegistry) Execute() {
+	for _, instance := range r.Instances() {
+		instance.Service.Execute()
+	}
+}
+
+func (r *StrategyRegistry) BotAction(coin *domains.Coin) {
+	return
+}
+
+func (r *StrategyRegistry) InitializeTrading(coin *domains.Coin) error {
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"cryptoBot/pkg/service/exchange"
 	"cryptoBot/pkg/service/indicator"
 	"cryptoBot/pkg/service/orders"
+	"cryptoBot/pkg/service/signal"
 	"cryptoBot/pkg/util"
 	"fmt"
 	"github.com/sdcoffey/big"
@@ -18,8 +19,6 @@ import (
 	"go.uber.org/zap"
 )
 
-var trendMeterStrategyTradingServiceImpl *TrendMeterStrategyTradingService
-
 func NewTrendMeterStrategyTradingService(
 	transactionRepo repository.Transaction,
 	clock date.Clock,
@@ -31,13 +30,11 @@ func NewTrendMeterStrategyTradingService(
 	relativeStrengthIndexService *indicator.RelativeStrengthIndexService,
 	exponentialMovingAverageService *indicator.ExponentialMovingAverageService,
 	orderManagerService *orders.OrderManagerService,
+	signalSink signal.Sink,
 	priceChangeTrackingService *orders.PriceChangeTrackingService,
 	tradingType constants.TradingType,
 ) *TrendMeterStrategyTradingService {
-	if trendMeterStrategyTradingServiceImpl != nil {
-		panic("Unexpected try to create second service instance")
-	}
-	trendMeterStrategyTradingServiceImpl = &TrendMeterStrategyTradingService{
+	return &TrendMeterStrategyTradingService{
 		KlineRepo:                       klineRepo,
 		TransactionRepo:                 transactionRepo,
 		Clock:                           clock,
@@ -48,10 +45,11 @@ func NewTrendMeterStrategyTradingService(
 		RelativeStrengthIndexService:    relativeStrengthIndexService,
 		ExponentialMovingAverageService: exponentialMovingAverageService,
 		OrderManagerService:             orderManagerService,
+		SignalSink:                      signalSink,
 		PriceChangeTrackingService:      priceChangeTrackingService,
 		tradingType:                     tradingType,
+		tradingStrategy:                 constants.TREND_METER,
 	}
-	return trendMeterStrategyTradingServiceImpl
 }
 
 type TrendMeterStrategyTradingService struct {
@@ -65,13 +63,28 @@ type TrendMeterStrategyTradingService struct {
 	RelativeStrengthIndexService    *indicator.RelativeStrengthIndexService
 	ExponentialMovingAverageService *indicator.ExponentialMovingAverageService
 	OrderManagerService             *orders.OrderManagerService
+	SignalSink                      signal.Sink
 	PriceChangeTrackingService      *orders.PriceChangeTrackingService
 	tradingType                     constants.TradingType
+	tradingStrategy                 constants.TradingStrategy
+	coin                            *domains.Coin
+}
+
+func (s *TrendMeterStrategyTradingService) Initialize() error {
+	return s.InitializeTrading(s.coin)
+}
+
+func (s *TrendMeterStrategyTradingService) BeforeExecute() {
+	return
+}
+
+func (s *TrendMeterStrategyTradingService) Execute() {
+	s.BotAction(s.coin)
 }
 
 func (s *TrendMeterStrategyTradingService) InitializeTrading(coin *domains.Coin) error {
 	if s.tradingType == constants.FUTURES {
-		err := s.OrderManagerService.SetFuturesLeverage(coin, viper.GetInt("strategy.trendMeter.futures.leverage"))
+		err := s.SignalSink.SetFuturesLeverage(coin, viper.GetInt("strategy.trendMeter.futures.leverage"))
 		if err != nil {
 			return err
 		}
@@ -100,8 +113,9 @@ func (s *TrendMeterStrategyTradingService) BotAction(coin *domains.Coin) {
 }
 
 func (s *TrendMeterStrategyTradingService) BotActionCheckIfOrderClosedByExchange(coin *domains.Coin) {
-	openedOrder, _ := s.TransactionRepo.FindOpenedTransaction(constants.TREND_METER)
-	if openedOrder == nil {
+	openedOrder, _ := s.SignalSink.FindOpenedTransaction(coin, "")
+	// recorded and fake positions aren't opened in the exchange
+	if openedOrder == nil || openedOrder.IsFake {
 		return
 	}
 
@@ -112,7 +126,7 @@ func (s *TrendMeterStrategyTradingService) BotActionCheckIfOrderClosedByExchange
 }
 
 func (s *TrendMeterStrategyTradingService) BotActionBuyMoreIfNeeded(coin *domains.Coin) {
-	openedOrders, _ := s.TransactionRepo.FindAllOpenedTransactions(constants.TREND_METER)
+	openedOrders, _ := s.SignalSink.FindAllOpenedTransactions(coin, "")
 	openedTransactionsCount := len(openedOrders)
 	if openedTransactionsCount == 0 {
 		return
@@ -155,27 +169,29 @@ func (s *TrendMeterStrategyTradingService) BotActionBuyMoreIfNeeded(coin *domain
 		return
 	}
 
-	s.OrderManagerService.OpenOrderWithCost(coin, "", futureType.LONG, float64(costInUSDT), s.tradingType)
+	s.openOrder(coin, float64(costInUSDT))
 }
 
 func (s *TrendMeterStrategyTradingService) BotActionCloseOrderIfNeeded(coin *domains.Coin) {
-	openedOrders, _ := s.TransactionRepo.FindAllOpenedTransactions(constants.TREND_METER)
+	openedOrders, _ := s.SignalSink.FindAllOpenedTransactions(coin, "")
 	if len(openedOrders) == 1 {
 		openedOrder := openedOrders[0]
 		if s.isTakeProfitSignal(coin, openedOrder) {
 			currentPrice, _ := s.ExchangeDataService.GetCurrentPrice(coin)
-			s.OrderManagerService.CloseOrder(openedOrder, coin, currentPrice, s.tradingType)
+			s.closeOrder(coin, openedOrder, currentPrice)
 		}
 	} else if len(openedOrders) > 1 {
 		if s.isTakeProfitSignalForCombinedOrder(coin, openedOrders) {
 			currentPrice, _ := s.ExchangeDataService.GetCurrentPrice(coin)
-			s.OrderManagerService.CloseCombinedOrder(openedOrders, coin, currentPrice, s.tradingType)
+			for _, openedOrder := range openedOrders {
+				s.closeOrder(coin, openedOrder, currentPrice)
+			}
 		}
 	}
 }
 
 func (s *TrendMeterStrategyTradingService) BotActionOpenOrderIfNeeded(coin *domains.Coin) {
-	openedOrder, _ := s.TransactionRepo.FindOpenedTransaction(constants.TREND_METER)
+	openedOrder, _ := s.SignalSink.FindOpenedTransaction(coin, "")
 
 	if openedOrder != nil {
 		return
@@ -195,7 +211,7 @@ func (s *TrendMeterStrategyTradingService) isTakeProfitSignalForCombinedOrder(co
 
 	profitInPercent := util.CalculateProfitInPercent(avgPrice, currentPrice, futureType.LONG)
 
-	openedOrders, _ := s.TransactionRepo.FindAllOpenedTransactions(constants.TREND_METER)
+	openedOrders, _ := s.SignalSink.FindAllOpenedTransactions(coin, "")
 
 	isProfitSignal := profitInPercent > float64(len(openedOrders)-1)
 	if isProfitSignal {
@@ -294,10 +310,22 @@ func (s *TrendMeterStrategyTradingService) calculateIndicators(coin *domains.Coi
 	////if > 10% end
 
 	if trendMeterSignalLong && trendBar1 && trendBar2 && emaFastAbove && volatilityOscillatorSignal && volatilityFuturesType == futureType.LONG {
-		s.OrderManagerService.OpenOrderWithCost(coin, "", futureType.LONG, util.GetDollarsByCents(viper.GetInt64("strategy.trendMeter.initialCostInCents")), s.tradingType)
+		s.openOrder(coin, util.GetDollarsByCents(viper.GetInt64("strategy.trendMeter.initialCostInCents")))
 	}
 }
 
+func (s *TrendMeterStrategyTradingService) openOrder(coin *domains.Coin, cost float64) {
+	openSignal := signal.NewOpenSignal(coin, "", futureType.LONG, cost, "")
+	openSignal.TradingType = s.tradingType
+	s.SignalSink.Emit(openSignal)
+}
+
+func (s *TrendMeterStrategyTradingService) closeOrder(coin *domains.Coin, openedOrder *domains.Transaction, price float64) {
+	closeSignal := signal.NewCloseSignal(coin, openedOrder, price, constants.CLOSE_REASON_SIGNAL)
+	closeSignal.TradingType = s.tradingType
+	s.SignalSink.Emit(closeSignal)
+}
+
 // CalculateMacdSignal signal is true when MACD cross the ZERO value (was < 0, now > 0 and the opposite)
 func (s *TrendMeterStrategyTradingService) CalculateMacdSignal(coin *domains.Coin) (bool, futureType.FuturesType) {
 	macdList := s.MACDService.CalculateMACDForAll(coin,